@@ -1,27 +1,90 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/alert"
 	"github.com/iguidao/redis-manager/src/middleware/casbin"
 	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/metrics"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
 	"github.com/iguidao/redis-manager/src/middleware/rcron"
+	"github.com/iguidao/redis-manager/src/middleware/secret"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
+	"github.com/iguidao/redis-manager/src/middleware/util"
 	"github.com/iguidao/redis-manager/src/rhttp"
 	"github.com/robfig/cron"
 )
 
 func init() {
+	// 生成配置文件时还没有配置文件
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		return
+	}
 	if err := cfg.Init(""); err != nil {
 		panic(err)
 	}
+	// token 只从环境变量读取，不写在配置文件里
+	if vaultaddr := cfg.Get_Info_String("vaultaddr"); vaultaddr != "" {
+		secret.SetResolver(secret.NewVaultResolver(vaultaddr, os.Getenv("VAULT_TOKEN"), time.Duration(cfg.Get_Info_Int("vaultcachesec"))*time.Second))
+	}
+	// 配置里的密钥读取不到时直接退出，不带着空的密钥启动
+	if err := cfg.CheckSecrets(); err != nil {
+		panic(err)
+	}
 	logger.SetupLogger()
+	logger.SetStreamResolver(opredis.FleetClient)
+	secret.SetErrorHandler(func(ref string, err error) {
+		logger.Error("resolve secret ", ref, " error: ", err)
+	})
 	mysql.Connect(cfg.Get_Info_String("MYSQL"))
 	mysql.Migrate()
+	if err := mysql.DB.CheckCfgSecrets(); err != nil {
+		logger.Error("check cfg secrets error: ", err)
+		exit(1)
+	}
 	casbin.Connect()
+	alert.Register(alert.LogAlerter{})
+	if webhook := mysql.DB.GetOneCfgValue(model.ALERTWEBHOOK); webhook != "" {
+		alert.Register(alert.NewWebhookAlerter(webhook))
+	}
+	logger.SetInstanceCounter(func() int {
+		return len(opredis.FleetTargets())
+	})
 }
 
 func main() {
+	defer logger.Close()
+	// redis-manager init [path] 生成示例配置文件
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		writeSampleConfig()
+		return
+	}
+	// redis-manager preflight 只做连通性检查
+	if len(os.Args) > 1 && os.Args[1] == "preflight" {
+		preflight()
+		return
+	}
+	// redis-manager config dump 输出实际生效的配置，密钥已隐藏
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "dump" {
+		if err := util.DumpEffectiveConfig(os.Stdout); err != nil {
+			logger.Error("dump config error: ", err)
+			exit(1)
+		}
+		return
+	}
+	// redis-manager metrics [path] 检查一次实例健康后输出指标，path 为空时输出到标准输出
+	if len(os.Args) > 1 && os.Args[1] == "metrics" {
+		dumpMetrics()
+		return
+	}
 	c := cron.New()
 	var calendarcrontime string
 	calendarcrontime = mysql.DB.GetOneCfgValue(model.CLOUDREFRESH)
@@ -29,8 +92,40 @@ func main() {
 		calendarcrontime = "@every 10m"
 	}
 	c.AddFunc(calendarcrontime, func() {
-		rcron.CloudRefresh()
+		tools.SafeRun("cloudrefresh", rcron.CloudRefresh)
+	})
+	evictioncrontime := mysql.DB.GetOneCfgValue(model.EVICTIONSAMPLE)
+	if evictioncrontime == "" {
+		evictioncrontime = "@every 1m"
+	}
+	c.AddFunc(evictioncrontime, func() {
+		tools.SafeRun("evictionsample", rcron.EvictionSample)
+	})
+	snapshotcrontime := mysql.DB.GetOneCfgValue(model.INFOSNAPSHOT)
+	if snapshotcrontime == "" {
+		snapshotcrontime = "@every 5m"
+	}
+	c.AddFunc(snapshotcrontime, func() {
+		tools.SafeRun("infosnapshot", rcron.InfoSnapshot)
 	})
+	healthcrontime := mysql.DB.GetOneCfgValue(model.HEALTHCHECK)
+	if healthcrontime == "" {
+		healthcrontime = "@every 30s"
+	}
+	c.AddFunc(healthcrontime, func() {
+		tools.SafeRun("instancehealth", rcron.InstanceHealth)
+	})
+	// 备份和TTL检查没有默认时间，配置了才执行
+	if backupcrontime := mysql.DB.GetOneCfgValue(model.BACKUPSCHEDULE); backupcrontime != "" {
+		c.AddFunc(backupcrontime, func() {
+			tools.SafeRun("backup", rcron.Backup)
+		})
+	}
+	if ttlcrontime := mysql.DB.GetOneCfgValue(model.TTLPOLICYSCHEDULE); ttlcrontime != "" {
+		c.AddFunc(ttlcrontime, func() {
+			tools.SafeRun("ttlpolicy", rcron.TTLPolicy)
+		})
+	}
 	c.Start()
 	listen := cfg.Get_Info_String("addr")
 	if listen == "" {
@@ -38,3 +133,81 @@ func main() {
 	}
 	rhttp.NewServer().Run(listen)
 }
+
+func preflight() {
+	report := util.Preflight(context.Background())
+	result, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(result))
+	passed := 0.0
+	if report.Passed {
+		passed = 1
+	}
+	metrics.Set("redis_manager_preflight_passed", passed, nil)
+	pushMetrics("preflight")
+	if !report.Passed {
+		exit(1)
+	}
+}
+
+// 命令行任务没法被抓取，配置了 pushgateway 时结束前推送指标
+func pushMetrics(job string) {
+	gateway := cfg.Get_Info_String("pushgateway")
+	if gateway == "" {
+		return
+	}
+	if err := metrics.PushMetrics(gateway, job); err != nil {
+		logger.Error("push metrics error: ", err)
+	}
+}
+
+// 给没有持续抓取的环境用，由 cron 定时执行后收集文件
+func dumpMetrics() {
+	rcron.InstanceHealth()
+	text, err := metrics.MetricsText(context.Background())
+	if err != nil {
+		logger.Error("metrics text error: ", err)
+		exit(1)
+	}
+	if len(os.Args) < 3 {
+		fmt.Print(text)
+		return
+	}
+	// 先写临时文件再改名，收集的时候不会读到一半的内容
+	path := os.Args[2]
+	if err := os.WriteFile(path+".tmp", []byte(text), 0644); err != nil {
+		logger.Error("write metrics error: ", err)
+		exit(1)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		logger.Error("write metrics error: ", err)
+		exit(1)
+	}
+}
+
+func writeSampleConfig() {
+	path := "yaml/config.yaml"
+	if len(os.Args) > 2 {
+		path = os.Args[2]
+	}
+	if _, err := os.Stat(path); err == nil {
+		fmt.Println(path, "already exists")
+		exit(1)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Println("create", path, "error:", err)
+		exit(1)
+	}
+	defer f.Close()
+	if err := cfg.WriteSampleConfig(f, "yaml"); err != nil {
+		fmt.Println("write", path, "error:", err)
+		exit(1)
+	}
+	fmt.Println("sample config written to", path)
+}
+
+// os.Exit 不会执行 defer，退出前先关闭日志，把缓冲的日志写入文件
+func exit(code int) {
+	logger.Close()
+	os.Exit(code)
+}
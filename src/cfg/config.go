@@ -2,6 +2,10 @@ package cfg
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/secret"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
@@ -9,6 +13,7 @@ import (
 
 type Config struct {
 	Name string
+	Type string // 配置文件格式，为空时使用yaml
 }
 
 func Get_Info_Int(get_type string) int {
@@ -25,12 +30,166 @@ func Get_Info_Int(get_type string) int {
 	case "checksize":
 		rediscfg_checksize := viper.GetInt("rediscfg.checksize")
 		return rediscfg_checksize
+	case "optimeout":
+		rediscfg_optimeout := viper.GetInt("rediscfg.optimeout")
+		return rediscfg_optimeout
+	case "breakerfailures":
+		rediscfg_breakerfailures := viper.GetInt("rediscfg.breakerfailures")
+		return rediscfg_breakerfailures
+	case "breakercooldown":
+		rediscfg_breakercooldown := viper.GetInt("rediscfg.breakercooldown")
+		return rediscfg_breakercooldown
+	case "scanrate":
+		rediscfg_scanrate := viper.GetInt("rediscfg.scanrate")
+		return rediscfg_scanrate
+	case "alertmemorypercent":
+		rediscfg_alertmemorypercent := viper.GetInt("rediscfg.alertmemorypercent")
+		return rediscfg_alertmemorypercent
+	case "alertlagsec":
+		rediscfg_alertlagsec := viper.GetInt("rediscfg.alertlagsec")
+		return rediscfg_alertlagsec
+	case "alertevictedpersec":
+		rediscfg_alertevictedpersec := viper.GetInt("rediscfg.alertevictedpersec")
+		return rediscfg_alertevictedpersec
+	case "maxconcurrentops":
+		rediscfg_maxconcurrentops := viper.GetInt("rediscfg.maxconcurrentops")
+		return rediscfg_maxconcurrentops
+	case "evictionwindow":
+		rediscfg_evictionwindow := viper.GetInt("rediscfg.evictionwindow")
+		return rediscfg_evictionwindow
+	case "bgsaveinterval":
+		rediscfg_bgsaveinterval := viper.GetInt("rediscfg.bgsaveinterval")
+		return rediscfg_bgsaveinterval
+	case "journalmaxmb":
+		local_journalmaxmb := viper.GetInt("local.journalmaxmb")
+		return local_journalmaxmb
+	case "journalbackups":
+		local_journalbackups := viper.GetInt("local.journalbackups")
+		return local_journalbackups
+	case "snapshotmaxmb":
+		local_snapshotmaxmb := viper.GetInt("local.snapshotmaxmb")
+		return local_snapshotmaxmb
+	case "snapshotbackups":
+		local_snapshotbackups := viper.GetInt("local.snapshotbackups")
+		return local_snapshotbackups
+	case "snapshotretentiondays":
+		local_snapshotretentiondays := viper.GetInt("local.snapshotretentiondays")
+		return local_snapshotretentiondays
+	case "logdedupwindowms":
+		local_logdedupwindowms := viper.GetInt("local.logdedupwindowms")
+		return local_logdedupwindowms
+	case "logstreammaxlen":
+		local_logstreammaxlen := viper.GetInt("local.logstreammaxlen")
+		return local_logstreammaxlen
+	case "logmaxtotalmb":
+		local_logmaxtotalmb := viper.GetInt("local.logmaxtotalmb")
+		return local_logmaxtotalmb
+	case "logmaxsizemb":
+		local_logmaxsizemb := viper.GetInt("local.logmaxsizemb")
+		return local_logmaxsizemb
+	case "logmaxbackups":
+		local_logmaxbackups := viper.GetInt("local.logmaxbackups")
+		return local_logmaxbackups
+	case "logretentiondays":
+		local_logretentiondays := viper.GetInt("local.logretentiondays")
+		return local_logretentiondays
+	case "logheartbeatsec":
+		local_logheartbeatsec := viper.GetInt("local.logheartbeatsec")
+		return local_logheartbeatsec
+	case "logsilencealarmsec":
+		local_logsilencealarmsec := viper.GetInt("local.logsilencealarmsec")
+		return local_logsilencealarmsec
+	case "logsamplefirst":
+		local_logsamplefirst := viper.GetInt("local.logsamplefirst")
+		return local_logsamplefirst
+	case "logsamplethereafter":
+		local_logsamplethereafter := viper.GetInt("local.logsamplethereafter")
+		return local_logsamplethereafter
+	case "logbufferkb":
+		local_logbufferkb := viper.GetInt("local.logbufferkb")
+		return local_logbufferkb
+	case "txtimeout":
+		txcloud_txtimeout := viper.GetInt("txcloud.txtimeout")
+		return txcloud_txtimeout
+	case "txreqtimeout":
+		txcloud_txreqtimeout := viper.GetInt("txcloud.txreqtimeout")
+		return txcloud_txreqtimeout
+	case "txretry":
+		txcloud_txretry := viper.GetInt("txcloud.txretry")
+		return txcloud_txretry
+	case "txbackoffms":
+		txcloud_txbackoffms := viper.GetInt("txcloud.txbackoffms")
+		return txcloud_txbackoffms
+	case "vaultcachesec":
+		local_vaultcachesec := viper.GetInt("local.vaultcachesec")
+		return local_vaultcachesec
+	case "safegomaxbackoff":
+		local_safegomaxbackoff := viper.GetInt("local.safegomaxbackoff")
+		return local_safegomaxbackoff
 	default:
 		return 0
 	}
 }
 
+func Get_Info_Bool(get_type string) bool {
+	switch get_type {
+	case "logfilelock":
+		local_logfilelock := viper.GetBool("local.logfilelock")
+		return local_logfilelock
+	case "logfilefallback":
+		local_logfilefallback := viper.GetBool("local.logfilefallback")
+		return local_logfilefallback
+	case "opsfailfast":
+		rediscfg_opsfailfast := viper.GetBool("rediscfg.opsfailfast")
+		return rediscfg_opsfailfast
+	default:
+		return false
+	}
+}
+
+func Get_Info_Map(get_type string) map[string]string {
+	switch get_type {
+	case "logfields":
+		local_logfields := viper.GetStringMapString("local.logfields")
+		return local_logfields
+	case "logkeys":
+		local_logkeys := viper.GetStringMapString("local.logkeys")
+		return local_logkeys
+	default:
+		return nil
+	}
+}
+
+// 值为 vault:path#field 时从 Vault 读取，读取失败返回空，错误由 secret 限频上报
 func Get_Info_String(get_type string) string {
+	value, err := secret.Resolve(infoString(get_type))
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// 启动时检查配置文件里所有 vault: 引用都能读取到，有读取失败的返回错误，不带着空的密钥启动
+func CheckSecrets() error {
+	keylist := viper.AllKeys()
+	sort.Strings(keylist)
+	var failed []string
+	for _, key := range keylist {
+		value := viper.GetString(key)
+		if !secret.IsRef(value) {
+			continue
+		}
+		if _, err := secret.Resolve(value); err != nil {
+			failed = append(failed, key+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("resolve config secrets failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func infoString(get_type string) string {
 	switch get_type {
 	case "MYSQL":
 		mysql_name := viper.GetString("mysql.name")
@@ -56,6 +215,48 @@ func Get_Info_String(get_type string) string {
 	case "logapppath":
 		local_logapppath := viper.GetString("local.logapppath")
 		return local_logapppath
+	case "logformat":
+		local_logformat := viper.GetString("local.logformat")
+		return local_logformat
+	case "loglevel":
+		local_loglevel := viper.GetString("local.loglevel")
+		return local_loglevel
+	case "loglevelmode":
+		local_loglevelmode := viper.GetString("local.loglevelmode")
+		return local_loglevelmode
+	case "pushgateway":
+		local_pushgateway := viper.GetString("local.pushgateway")
+		return local_pushgateway
+	case "journalpath":
+		local_journalpath := viper.GetString("local.journalpath")
+		return local_journalpath
+	case "snapshotpath":
+		local_snapshotpath := viper.GetString("local.snapshotpath")
+		return local_snapshotpath
+	case "vaultaddr":
+		local_vaultaddr := viper.GetString("local.vaultaddr")
+		return local_vaultaddr
+	case "logconfigmap":
+		local_logconfigmap := viper.GetString("local.logconfigmap")
+		return local_logconfigmap
+	case "logconfigmapkey":
+		local_logconfigmapkey := viper.GetString("local.logconfigmapkey")
+		return local_logconfigmapkey
+	case "loggelfaddr":
+		local_loggelfaddr := viper.GetString("local.loggelfaddr")
+		return local_loggelfaddr
+	case "logstreaminstance":
+		local_logstreaminstance := viper.GetString("local.logstreaminstance")
+		return local_logstreaminstance
+	case "logstreamkey":
+		local_logstreamkey := viper.GetString("local.logstreamkey")
+		return local_logstreamkey
+	case "logsamplelevels":
+		local_logsamplelevels := viper.GetString("local.logsamplelevels")
+		return local_logsamplelevels
+	case "logflushlevel":
+		local_logflushlevel := viper.GetString("local.logflushlevel")
+		return local_logflushlevel
 	case "secretkey":
 		rediscfg_secretkey := viper.GetString("local.secretkey")
 		return rediscfg_secretkey
@@ -73,9 +274,31 @@ func Get_Info_String(get_type string) string {
 	}
 }
 
+// 配置文件的所有配置，包括没有 Get_Info 的key
+func AllSettings() map[string]interface{} {
+	return viper.AllSettings()
+}
+
+// 当前使用的配置文件路径
+func ConfigFile() string {
+	return viper.ConfigFileUsed()
+}
+
 func Init(cfg string) error {
+	return InitWithType(cfg, "")
+}
+
+// 指定配置文件格式，用于没有扩展名的配置文件，例如k8s挂载的 config
+func InitWithType(cfg, cfgtype string) error {
 	c := Config{
 		Name: cfg,
+		Type: strings.ToLower(cfgtype),
+	}
+	if c.Type == "" {
+		c.Type = "yaml"
+	}
+	if !supportedType(c.Type) {
+		return fmt.Errorf("unsupported config type %q, supported: %s", cfgtype, strings.Join(viper.SupportedExts, ","))
 	}
 	// 初始化配置文件
 	if err := c.initConfig(); err != nil {
@@ -86,6 +309,15 @@ func Init(cfg string) error {
 	return nil
 }
 
+func supportedType(cfgtype string) bool {
+	for _, v := range viper.SupportedExts {
+		if v == cfgtype {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Config) initConfig() error {
 	if c.Name != "" {
 		// 如果指定了配置文件，则解析指定的配置文件
@@ -95,8 +327,8 @@ func (c *Config) initConfig() error {
 		viper.AddConfigPath("yaml")
 		viper.SetConfigName("config")
 	}
-	// 设置配置文件格式为YAML
-	viper.SetConfigType("yaml")
+	// 设置配置文件格式，默认为YAML
+	viper.SetConfigType(c.Type)
 	// viper解析配置文件
 	if err := viper.ReadInConfig(); err != nil {
 		return err
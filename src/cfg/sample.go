@@ -0,0 +1,116 @@
+package cfg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 带注释的示例配置，和 yaml/dev.yaml 的key保持一致
+const sampleYaml = `# redis-manager 示例配置
+# 腾讯云、阿里云的密钥以及定时任务时间等运行时配置保存在mysql里，在页面的配置管理中修改
+
+local:
+    addr: 0.0.0.0:8000              # http 监听地址
+    runmode: debug                  # gin 运行模式 debug/release
+    pagesize: 10                    # 分页大小
+    secretkey: ""                   # jwt 签名密钥
+    safegomaxbackoff: 60            # 后台goroutine panic 后重启的最大间隔，秒
+    pushgateway: ""                 # 命令行任务结束时推送指标的 pushgateway 地址，为空不推送
+
+    # 日志
+    logapipath: "./logs/api.log"    # 接口访问日志
+    logapppath: "./logs/app.log"    # 应用日志
+    loglevel: "debug"               # debug/info/warn/error，无法识别时使用 info
+    loglevelmode: "threshold"       # threshold 输出不低于 loglevel 的日志，exact 只输出等于 loglevel 的日志
+    logformat: "console"            # console/json/ndjson/gelf
+    logfilelock: false              # 多进程写同一个日志文件时加文件锁，轮转也在锁内按实际大小进行
+    logfilefallback: true           # 日志文件连续写入失败时改写 stderr，每30秒重试一次文件
+    logdedupwindowms: 0             # 相同日志的去重窗口，毫秒，0 不去重
+    logsamplelevels: ""             # 需要采样的级别，逗号分隔，例如 "error"，为空不采样
+    logsamplefirst: 10              # 采样时每秒相同日志先输出的条数
+    logsamplethereafter: 100        # 之后每多少条输出一条
+    logbufferkb: 0                  # 日志文件写入缓冲，KB，0 不缓冲
+    logflushlevel: "warn"           # 开启缓冲时，不低于该级别的日志立即刷盘
+    logsilencealarmsec: 0           # 超过该秒数没有日志时输出 heartbeat 或触发回调，0 不检查
+    logheartbeatsec: 0              # 每隔该秒数输出一条带运行时间和实例个数的 heartbeat 日志，0 不输出
+    logmaxtotalmb: 0                # app 和 api 日志包括轮转文件的总大小，MB，超过时删除最旧的轮转文件，0 不限制
+    logmaxsizemb: 0                 # 日志文件按大小轮转，MB，0 时 app 日志按 1024MB 轮转，api 日志不轮转、追加写入
+    logmaxbackups: 0                # 保留的轮转文件个数，0 不限制
+    logretentiondays: 0             # 轮转日志文件保留的天数，启动时和每天删除更旧的文件，0 不删除
+    logstreaminstance: ""           # 同时写入redis stream 的实例ID，从管理的实例里查找，为空不写
+    logstreamkey: "redis-manager:logs"
+    logstreammaxlen: 100000         # stream 的最大长度，近似裁剪
+    loggelfaddr: ""                 # 同时通过udp发送 GELF 格式日志的 graylog 地址 host:port，为空不发送
+    logconfigmap: ""                # 在k8s里运行时监听的 ConfigMap，namespace/name，修改 level 后实时生效，为空不监听
+    logconfigmapkey: "zap.json"     # ConfigMap 里保存日志配置的key，json格式，例如 {"level":"warn"}
+    vaultaddr: ""                   # Vault 地址，配置值写成 vault:secret/data/redis#secretkey 时从 Vault 读取，token 使用 VAULT_TOKEN 环境变量
+    vaultcachesec: 300              # 从 Vault 读取的值缓存的秒数
+    logfields:                      # 每条日志都带上的字段，可以覆盖 host、version
+        service: "redis-manager"
+    logkeys: {}                     # 修改输出的字段名，可选 time/level/message/caller/name/stacktrace，例如 time: "@timestamp"
+
+    # 操作流水，记录所有修改类操作，和日志分开保存
+    journalpath: "./logs/journal.log"
+    journalmaxmb: 100               # 单个文件的最大大小，MB，超过后轮转
+    journalbackups: 3               # 保留的历史文件个数
+
+    # 实例指标快照，用于查看内存、连接数、命中率的趋势
+    snapshotpath: "./logs/snapshot.log"
+    snapshotmaxmb: 50               # 单个文件的最大大小，MB，超过后轮转
+    snapshotbackups: 10             # 最多保留的历史文件个数
+    snapshotretentiondays: 7        # 历史文件超过该天数后删除
+
+rediscfg:
+    allkeyfornum: 10                # 扫描key时最多 scan 的轮数
+    locktime: 60                    # 普通操作的锁时间，秒
+    biglocktime: 600                # 大key分析的锁时间，秒
+    checksize: 4000                 # 大key的判断阈值，字节
+    bgsaveinterval: 600             # 同一个实例两次 bgsave 的最小间隔，秒
+    optimeout: 3000                 # redis 操作超时，毫秒
+    evictionwindow: 300             # 驱逐速率的统计窗口，秒
+    breakerfailures: 5              # 熔断器连续失败次数
+    breakercooldown: 30             # 熔断器打开后的冷却时间，秒
+    scanrate: 1000                  # 每个实例每秒最多扫描、删除的key数量，0 不限速
+    maxconcurrentops: 2             # 每个实例同时执行的管理操作个数，0 不限制
+    opsfailfast: false              # 超过 maxconcurrentops 时直接失败，false 时等待
+    alertmemorypercent: 90          # 内存使用率超过该百分比时告警，0 不告警
+    alertlagsec: 10                 # 主从延迟超过该秒数时告警，0 不告警
+    alertevictedpersec: 0           # 每秒驱逐的key个数超过该值时告警，0 不告警
+
+# 腾讯云接口调用，5xx和限频时按 Retry-After 或指数退避重试
+txcloud:
+    txtimeout: 60                   # 包括重试在内的总超时，秒
+    txreqtimeout: 10                # 单次请求的超时，秒
+    txretry: 3                      # 最多重试次数，不配置或 0 时使用默认的 3 次
+    txbackoffms: 500                # 第一次重试前的等待时间，毫秒，之后翻倍
+
+mysql:
+    name: redis_manager
+    addr: 127.0.0.1:3306
+    username: root
+    password: ""
+
+# 管理用的redis，保存锁和分析结果
+redis:
+    addr: 127.0.0.1
+    port: 6379
+    password: ""
+    db: 0
+
+# 腾讯云COS，下载备份文件时使用
+cos:
+    cosaccesskey: ""
+    cosaccesskeyid: ""
+    cosendpointpub: ""
+`
+
+// 输出示例配置，目前只支持yaml，其他格式没法带注释
+func WriteSampleConfig(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case "", "yaml", "yml":
+		_, err := io.WriteString(w, sampleYaml)
+		return err
+	}
+	return fmt.Errorf("unsupported sample config format %q, only yaml is supported", format)
+}
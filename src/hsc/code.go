@@ -36,4 +36,7 @@ const (
 	WARN_CODIS_GROUP_MIN_NUMBER    = 60014
 	WARN_CODIS_GROUP_MIN_CAPACITY  = 60015
 	WARN_CHECK_IPPORT_FAIL         = 60016
+	WARN_CLUSTER_ONLY_DB0          = 60017
+	WARN_NEED_CONFIRM              = 60018
+	WARN_NOT_IN_WINDOW             = 60019
 )
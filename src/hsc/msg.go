@@ -33,6 +33,9 @@ var MsgFlags = map[int]string{
 	WARN_CODIS_GROUP_MIN_NUMBER:   "codis的group最小是1个，不能再少了",
 	WARN_CODIS_GROUP_MIN_CAPACITY: "剩余codis的group容量不足80%了",
 	WARN_CHECK_IPPORT_FAIL:        "IP和端口健康检查失败",
+	WARN_CLUSTER_ONLY_DB0:         "cluster只支持db0",
+	WARN_NEED_CONFIRM:             "危险操作，需要填写目标ID确认",
+	WARN_NOT_IN_WINDOW:            "不在维护窗口内，请在维护窗口内执行",
 }
 
 func GetMsg(code int) string {
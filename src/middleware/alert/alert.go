@@ -0,0 +1,148 @@
+package alert
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
+)
+
+const (
+	STATUSFIRING   = "firing"
+	STATUSRESOLVED = "resolved"
+)
+
+// 一次告警或恢复
+type Alert struct {
+	Name      string    `json:"name"` // 告警名，例如 instance_down、memory_percent
+	Instance  string    `json:"instance"`
+	Status    string    `json:"status"` // firing、resolved
+	Message   string    `json:"message"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Time      time.Time `json:"time"`
+}
+
+// 告警的发送方式，Send 在单独的goroutine里执行
+type Alerter interface {
+	Send(alert Alert) error
+}
+
+// 不发送，没有注册任何 Alerter 时和它一样不发送
+type NopAlerter struct{}
+
+func (NopAlerter) Send(alert Alert) error {
+	return nil
+}
+
+// 每个发送方式排队的告警个数，满了之后丢弃
+const sinkBuffer = 256
+
+// 每个发送方式一个goroutine按顺序发送，同一个告警的 firing 不会在 resolved 之后到达
+type sink struct {
+	alerter Alerter
+	ch      chan Alert
+}
+
+func newSink(a Alerter) *sink {
+	s := &sink{alerter: a, ch: make(chan Alert, sinkBuffer)}
+	tools.SafeGoRestart("alert", s.loop)
+	return s
+}
+
+func (s *sink) loop() {
+	for alert := range s.ch {
+		if err := s.alerter.Send(alert); err != nil {
+			logger.Error("send alert ", alert.Name, " ", alert.Instance, " error: ", err)
+		}
+	}
+}
+
+var (
+	lock   sync.Mutex
+	sinks  []*sink
+	firing = make(map[string]Alert)
+)
+
+// 注册告警的发送方式，可以注册多个
+func Register(a Alerter) {
+	s := newSink(a)
+	lock.Lock()
+	sinks = append(sinks, s)
+	lock.Unlock()
+}
+
+// 各个检查项每次检查后调用，breached 为是否超过阈值
+// 同一个实例的同一个告警只在开始超过时发送一次，恢复时发送 resolved
+func Check(name, instance string, breached bool, value, threshold float64, message string) {
+	key := name + "/" + instance
+	lock.Lock()
+	last, active := firing[key]
+	if breached == active {
+		lock.Unlock()
+		return
+	}
+	alert := Alert{
+		Name:      name,
+		Instance:  instance,
+		Status:    STATUSFIRING,
+		Message:   message,
+		Value:     value,
+		Threshold: threshold,
+		Time:      time.Now(),
+	}
+	if breached {
+		firing[key] = alert
+	} else {
+		delete(firing, key)
+		alert.Status = STATUSRESOLVED
+		if alert.Message == "" {
+			alert.Message = last.Message
+		}
+	}
+	dispatch(alert)
+	lock.Unlock()
+}
+
+// 持有 lock 时调用，按 Check 的顺序放入每个发送方式的队列，没有注册时不发送
+func dispatch(alert Alert) {
+	for _, s := range sinks {
+		select {
+		case s.ch <- alert:
+		default:
+			logger.Error("alert queue full, drop ", alert.Status, " ", alert.Name, " ", alert.Instance)
+		}
+	}
+}
+
+// 清理已经不在 instances 里的实例的告警状态，并发送 resolved，实例下线后不会一直显示在告警列表里
+func Prune(instances []string) {
+	exists := make(map[string]bool, len(instances))
+	for _, v := range instances {
+		exists[v] = true
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	for key, v := range firing {
+		if exists[v.Instance] {
+			continue
+		}
+		delete(firing, key)
+		v.Status = STATUSRESOLVED
+		v.Time = time.Now()
+		v.Message = v.Instance + " 已经下线"
+		dispatch(v)
+	}
+}
+
+// 当前正在告警的列表
+func Firing() []Alert {
+	lock.Lock()
+	defer lock.Unlock()
+	result := make([]Alert, 0, len(firing))
+	for _, v := range firing {
+		result = append(result, v)
+	}
+	return result
+}
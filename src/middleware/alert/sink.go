@@ -0,0 +1,49 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 写到错误日志，日志采集那边可以按 alert 关键字告警
+type LogAlerter struct{}
+
+func (LogAlerter) Send(alert Alert) error {
+	if alert.Status == STATUSFIRING {
+		logger.Error("alert firing: ", alert.Name, " instance: ", alert.Instance, " value: ", alert.Value, " threshold: ", alert.Threshold, " ", alert.Message)
+		return nil
+	}
+	logger.Info("alert resolved: ", alert.Name, " instance: ", alert.Instance, " value: ", alert.Value, " ", alert.Message)
+	return nil
+}
+
+// POST json 格式的告警到 URL
+type WebhookAlerter struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookAlerter(url string) *WebhookAlerter {
+	return &WebhookAlerter{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *WebhookAlerter) Send(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", w.URL, resp.Status)
+	}
+	return nil
+}
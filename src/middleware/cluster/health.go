@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+const ClusterSlots = 16384
+
+// 根据 cluster nodes 和 cluster info 的结果检查集群健康状态
+func CheckHealth(nodeinfo []string, clusterinfo string) model.ClusterHealth {
+	var result model.ClusterHealth
+	var covered [ClusterSlots]bool
+	result.MasterReplicas = make(map[string]int)
+	masteraddr := make(map[string]string)
+	var slaves []string
+	for _, line := range strings.Split(clusterinfo, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "cluster_state:") {
+			result.ClusterState = strings.TrimPrefix(line, "cluster_state:")
+		}
+	}
+	for _, v := range nodeinfo {
+		nodelist := strings.Fields(v)
+		if len(nodelist) < 8 {
+			continue
+		}
+		address := strings.Split(nodelist[1], "@")[0]
+		flags := strings.Split(nodelist[2], ",")
+		for _, flag := range flags {
+			switch flag {
+			case "fail":
+				result.FailNodes = append(result.FailNodes, address)
+			case "fail?":
+				result.PfailNodes = append(result.PfailNodes, address)
+			}
+		}
+		if checkFlag(flags, "master") {
+			result.Masters++
+			masteraddr[nodelist[0]] = address
+			result.MasterReplicas[address] = 0
+			for _, slotrange := range nodelist[8:] {
+				start, end, ok := parseSlotRange(slotrange)
+				if !ok {
+					continue
+				}
+				for i := start; i <= end; i++ {
+					covered[i] = true
+				}
+			}
+		} else if checkFlag(flags, "slave") {
+			result.Replicas++
+			slaves = append(slaves, nodelist[3])
+		}
+	}
+	for _, masterid := range slaves {
+		if address, ok := masteraddr[masterid]; ok {
+			result.MasterReplicas[address]++
+		}
+	}
+	gapstart := -1
+	for i := 0; i <= ClusterSlots; i++ {
+		if i < ClusterSlots && covered[i] {
+			result.SlotsCovered++
+		}
+		if i < ClusterSlots && !covered[i] {
+			if gapstart == -1 {
+				gapstart = i
+			}
+			continue
+		}
+		if gapstart != -1 {
+			result.SlotGaps = append(result.SlotGaps, strconv.Itoa(gapstart)+"-"+strconv.Itoa(i-1))
+			gapstart = -1
+		}
+	}
+
+	if result.ClusterState == "fail" {
+		result.Warnings = append(result.Warnings, "cluster_state:fail")
+	}
+	if result.SlotsCovered != ClusterSlots {
+		result.Warnings = append(result.Warnings, "slot没有全部分配: "+strconv.Itoa(result.SlotsCovered)+"/"+strconv.Itoa(ClusterSlots))
+	}
+	if len(result.FailNodes) != 0 {
+		result.Warnings = append(result.Warnings, "存在fail节点: "+strings.Join(result.FailNodes, ","))
+	}
+	if len(result.PfailNodes) != 0 {
+		result.Warnings = append(result.Warnings, "存在pfail节点: "+strings.Join(result.PfailNodes, ","))
+	}
+	var masterlist []string
+	for address := range result.MasterReplicas {
+		masterlist = append(masterlist, address)
+	}
+	sort.Strings(masterlist)
+	for _, address := range masterlist {
+		if result.MasterReplicas[address] == 0 {
+			result.Warnings = append(result.Warnings, "master没有slave: "+address)
+		}
+	}
+	result.Healthy = len(result.Warnings) == 0
+	return result
+}
+
+func checkFlag(flags []string, flag string) bool {
+	for _, v := range flags {
+		if v == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// 解析slot区间，迁移中的 [slot->-nodeid] 不计算
+func parseSlotRange(slotrange string) (int, int, bool) {
+	if strings.HasPrefix(slotrange, "[") {
+		return 0, 0, false
+	}
+	num := strings.Split(slotrange, "-")
+	start, err := strconv.Atoi(num[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	end := start
+	if len(num) == 2 {
+		end, err = strconv.Atoi(num[1])
+		if err != nil {
+			return 0, 0, false
+		}
+	}
+	if start < 0 || end >= ClusterSlots || start > end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
@@ -0,0 +1,130 @@
+package cluster
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+type rebalanceMaster struct {
+	address string
+	slots   []int
+	target  int
+	keys    int64
+}
+
+// 根据 cluster nodes 生成让每个master的slot个数平均的迁移计划，只生成计划不执行
+// 多出来的slot从slot多的master尾部取，分给slot少的master，fail的master不参与
+func PlanRebalance(nodeinfo []string, keycount map[string]int64) model.RebalancePlan {
+	var result model.RebalancePlan
+	var masters []*rebalanceMaster
+	for _, v := range nodeinfo {
+		nodelist := strings.Fields(v)
+		if len(nodelist) < 8 {
+			continue
+		}
+		flags := strings.Split(nodelist[2], ",")
+		if !checkFlag(flags, "master") || checkFlag(flags, "fail") {
+			continue
+		}
+		master := &rebalanceMaster{address: strings.Split(nodelist[1], "@")[0]}
+		master.keys = keycount[master.address]
+		for _, slotrange := range nodelist[8:] {
+			start, end, ok := parseSlotRange(slotrange)
+			if !ok {
+				continue
+			}
+			for i := start; i <= end; i++ {
+				master.slots = append(master.slots, i)
+			}
+		}
+		masters = append(masters, master)
+	}
+	if len(masters) == 0 {
+		result.Balanced = true
+		return result
+	}
+	// slot多的排前面，多出来的余数分给前面的master，减少迁移
+	sort.Slice(masters, func(i, j int) bool {
+		if len(masters[i].slots) != len(masters[j].slots) {
+			return len(masters[i].slots) > len(masters[j].slots)
+		}
+		return masters[i].address < masters[j].address
+	})
+	total := 0
+	for _, v := range masters {
+		total += len(v.slots)
+	}
+	for i, v := range masters {
+		v.target = total / len(masters)
+		if i < total%len(masters) {
+			v.target++
+		}
+	}
+	var receivers []*rebalanceMaster
+	for _, v := range masters {
+		if len(v.slots) < v.target {
+			receivers = append(receivers, v)
+		}
+	}
+	received := make(map[string]int)
+	for _, from := range masters {
+		surplus := len(from.slots) - from.target
+		if surplus <= 0 {
+			continue
+		}
+		var keysperslot int64
+		if len(from.slots) > 0 {
+			keysperslot = from.keys / int64(len(from.slots))
+		}
+		moving := from.slots[len(from.slots)-surplus:]
+		for _, to := range receivers {
+			need := to.target - len(to.slots) - received[to.address]
+			if need <= 0 || len(moving) == 0 {
+				continue
+			}
+			if need > len(moving) {
+				need = len(moving)
+			}
+			result.Moves = append(result.Moves, model.SlotMove{
+				From:          from.address,
+				To:            to.address,
+				Slots:         formatSlots(moving[:need]),
+				SlotCount:     need,
+				EstimatedKeys: keysperslot * int64(need),
+			})
+			received[to.address] += need
+			moving = moving[need:]
+		}
+	}
+	for _, v := range masters {
+		result.Masters = append(result.Masters, model.RebalanceMaster{
+			Address:     v.address,
+			Slots:       len(v.slots),
+			TargetSlots: v.target,
+			Keys:        v.keys,
+		})
+	}
+	result.Balanced = len(result.Moves) == 0
+	return result
+}
+
+// 把连续的slot合并成区间
+func formatSlots(slots []int) string {
+	var ranges []string
+	for i := 0; i < len(slots); {
+		j := i
+		for j+1 < len(slots) && slots[j+1] == slots[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(slots[i]))
+		} else {
+			ranges = append(ranges, strconv.Itoa(slots[i])+"-"+strconv.Itoa(slots[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/metrics"
+)
+
+// 事件类型
+const (
+	INSTANCEREGISTERED = "instance_registered"
+	STATECHANGE        = "state_change"
+	BACKUPSTARTED      = "backup_started"
+	BACKUPFINISHED     = "backup_finished"
+	CONFIGAPPLIED      = "config_applied"
+)
+
+// 订阅的默认缓冲个数
+const DefaultBuffer = 100
+
+// 管理端的生命周期事件，嵌入的程序订阅后自己处理，不用解析日志
+type Event struct {
+	Type     string            `json:"type"`
+	Instance string            `json:"instance"`
+	Time     time.Time         `json:"time"`
+	Data     map[string]string `json:"data"`
+}
+
+var (
+	lock        sync.Mutex
+	subscribers []chan Event
+)
+
+// 订阅所有事件，不再使用时调用 Unsubscribe
+func Subscribe() <-chan Event {
+	return SubscribeBuffer(DefaultBuffer)
+}
+
+// 缓冲满了以后丢弃最旧的事件，丢弃的个数记在 redis_manager_events_dropped_total
+func SubscribeBuffer(buffer int) <-chan Event {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	ch := make(chan Event, buffer)
+	lock.Lock()
+	subscribers = append(subscribers, ch)
+	lock.Unlock()
+	return ch
+}
+
+// 取消订阅并关闭channel
+func Unsubscribe(sub <-chan Event) {
+	lock.Lock()
+	defer lock.Unlock()
+	for i, ch := range subscribers {
+		if ch == sub {
+			subscribers = append(subscribers[:i], subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// 发布事件，不会阻塞调用方
+func Publish(eventtype, instance string, data map[string]string) {
+	e := Event{Type: eventtype, Instance: instance, Time: time.Now(), Data: data}
+	lock.Lock()
+	defer lock.Unlock()
+	for _, ch := range subscribers {
+		select {
+		case ch <- e:
+			continue
+		default:
+		}
+		// 缓冲满了，丢掉最旧的一个再放
+		select {
+		case <-ch:
+		default:
+		}
+		metrics.Add("redis_manager_events_dropped_total", 1, nil)
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package goroutine
+
+import (
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/stderr"
+)
+
+const (
+	MinBackoff = time.Second
+	MaxBackoff = time.Minute
+)
+
+// panic 的处理函数，默认输出到 stderr
+// 这个包不依赖 logger，logger 自己的 goroutine 也可以使用，tools 初始化时会换成写日志和计数
+type PanicHandler func(name string, err interface{}, stack []byte)
+
+var (
+	handlerLock sync.RWMutex
+	handler     PanicHandler = stderrHandler
+)
+
+func stderrHandler(name string, err interface{}, stack []byte) {
+	stderr.Println("goroutine", name, "panic:", err)
+	os.Stderr.Write(stack)
+}
+
+// 设置 panic 的处理函数，为 nil 时恢复默认
+func SetPanicHandler(fn PanicHandler) {
+	if fn == nil {
+		fn = stderrHandler
+	}
+	handlerLock.Lock()
+	handler = fn
+	handlerLock.Unlock()
+}
+
+func currentHandler() PanicHandler {
+	handlerLock.RLock()
+	defer handlerLock.RUnlock()
+	return handler
+}
+
+// 启动一个带panic恢复的goroutine
+func Go(name string, fn func()) {
+	go func() {
+		Run(name, fn)
+	}()
+}
+
+// 启动一个带panic恢复的goroutine，panic后按退避时间重启，正常退出则不再重启
+// maxbackoff 小于等于0时使用 MaxBackoff
+func GoRestart(name string, fn func(), maxbackoff time.Duration, restart func(time.Duration)) {
+	if maxbackoff <= 0 {
+		maxbackoff = MaxBackoff
+	}
+	go func() {
+		backoff := MinBackoff
+		for {
+			if Run(name, fn) {
+				return
+			}
+			if restart != nil {
+				restart(backoff)
+			}
+			time.Sleep(backoff)
+			backoff = backoff * 2
+			if backoff > maxbackoff {
+				backoff = maxbackoff
+			}
+		}
+	}()
+}
+
+// 执行fn并恢复panic，正常结束返回true
+func Run(name string, fn func()) (ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			ok = false
+			currentHandler()(name, err, debug.Stack())
+		}
+	}()
+	fn()
+	return true
+}
@@ -0,0 +1,139 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 操作流水，和日志分开保存，用于事后复盘
+type JournalEntry struct {
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor"`
+	Instance   string    `json:"instance"`
+	Op         string    `json:"op"`
+	Args       string    `json:"args"`
+	Result     string    `json:"result"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+var lock sync.Mutex
+
+// 流水文件路径，默认 ./logs/journal.log
+func journalPath() string {
+	if path := cfg.Get_Info_String("journalpath"); path != "" {
+		return path
+	}
+	return "./logs/journal.log"
+}
+
+// 单个文件的最大大小，默认100MB
+func maxSize() int64 {
+	if size := cfg.Get_Info_Int("journalmaxmb"); size > 0 {
+		return int64(size) * 1024 * 1024
+	}
+	return 100 * 1024 * 1024
+}
+
+// 保留的历史文件个数，默认3个
+func backups() int {
+	if n := cfg.Get_Info_Int("journalbackups"); n > 0 {
+		return n
+	}
+	return 3
+}
+
+// 追加一条流水，文件超过大小后轮转为 journal.log.1、journal.log.2 ...
+func Record(entry JournalEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logger.Error("Journal marshal error: ", err)
+		return
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	path := journalPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logger.Error("Journal mkdir error: ", err)
+		return
+	}
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line))+1 > maxSize() {
+		rotate(path)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.Error("Journal open error: ", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		logger.Error("Journal write error: ", err)
+	}
+}
+
+func rotate(path string) {
+	n := backups()
+	os.Remove(path + "." + strconv.Itoa(n))
+	for i := n - 1; i >= 1; i-- {
+		os.Rename(path+"."+strconv.Itoa(i), path+"."+strconv.Itoa(i+1))
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		logger.Error("Journal rotate error: ", err)
+	}
+}
+
+// 查询 since 之后的流水，包含已经轮转的文件，按时间从旧到新返回
+func Journal(since time.Time) ([]JournalEntry, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	path := journalPath()
+	var files []string
+	for i := backups(); i >= 1; i-- {
+		files = append(files, path+"."+strconv.Itoa(i))
+	}
+	files = append(files, path)
+	var result []JournalEntry
+	for _, file := range files {
+		entries, err := readJournal(file, since)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entries...)
+	}
+	return result, nil
+}
+
+func readJournal(file string, since time.Time) ([]JournalEntry, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var result []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		// 进程退出时可能写了半行，跳过
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if !entry.Time.Before(since) {
+			result = append(result, entry)
+		}
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// 命令参数最多保留的个数
+const argsMaxCount = 32
+
+// 命令参数字段，单个参数超过 maxlen 字节、参数超过32个时截断，并标出原来的长度
+func Args(key string, args []string, maxlen int) zap.Field {
+	return zap.Strings(key, TruncateArgs(args, maxlen))
+}
+
+// 截断命令参数，maxlen 小于等于0时不截断单个参数
+func TruncateArgs(args []string, maxlen int) []string {
+	count := len(args)
+	if count > argsMaxCount {
+		count = argsMaxCount
+	}
+	result := make([]string, 0, count+1)
+	for _, arg := range args[:count] {
+		result = append(result, truncateArg(arg, maxlen))
+	}
+	if len(args) > count {
+		result = append(result, "…(truncated, "+strconv.Itoa(len(args))+" args)")
+	}
+	return result
+}
+
+// 按字节截断，不截断半个utf8字符
+func truncateArg(arg string, maxlen int) string {
+	if maxlen <= 0 || len(arg) <= maxlen {
+		return arg
+	}
+	cut := maxlen
+	for cut > 0 && !utf8.RuneStart(arg[cut]) {
+		cut--
+	}
+	return arg[:cut] + "…(truncated, " + strconv.Itoa(len(arg)) + " bytes)"
+}
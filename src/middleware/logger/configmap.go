@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount/"
+
+var ErrInvalidConfigMap = errors.New("logconfigmap should be namespace/name")
+
+// ConfigMap 里保存的日志配置，json格式，目前只有 level 可以实时生效
+type liveConfig struct {
+	Level string `json:"level"`
+}
+
+type configMap struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data"`
+}
+
+type configMapEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// watch 出错时 object 是 Status，410 表示 resourceVersion 已经过期
+type kubeStatus struct {
+	Code int `json:"code"`
+}
+
+var errResourceGone = errors.New("kubernetes resource version gone")
+
+// 监听 namespace/name 的 ConfigMap，key 里的日志配置修改后实时生效，key 为空时使用 zap.json
+// 不在 k8s 集群里运行时什么都不做
+func WatchConfigMap(configmap, key string) error {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		Info("not running in kubernetes, skip watching log configmap ", configmap)
+		return nil
+	}
+	parts := strings.SplitN(configmap, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ErrInvalidConfigMap
+	}
+	if key == "" {
+		key = "zap.json"
+	}
+	client, err := inClusterClient()
+	if err != nil {
+		return err
+	}
+	goroutine.Go("log configmap watch", func() { watchConfigMapLoop(client, parts[0], parts[1], key) })
+	return nil
+}
+
+func inClusterClient() (*http.Client, error) {
+	ca, err := ioutil.ReadFile(serviceAccountDir + "ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid kubernetes ca.crt")
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}, nil
+}
+
+// 先读取一次当前的配置，然后从这个版本开始 watch
+// watch 正常断开时从最后一个版本继续，返回 410 Gone 时重新读取再 watch
+func watchConfigMapLoop(client *http.Client, namespace, name, key string) {
+	base := "https://" + net.JoinHostPort(os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")) +
+		"/api/v1/namespaces/" + url.PathEscape(namespace) + "/configmaps"
+	for {
+		var cm configMap
+		if err := kubeGet(client, base+"/"+url.PathEscape(name), func(resp *http.Response) error {
+			return json.NewDecoder(resp.Body).Decode(&cm)
+		}); err != nil {
+			Warn("get log configmap ", namespace, "/", name, " error: ", err)
+			time.Sleep(30 * time.Second)
+			continue
+		}
+		applyLiveConfig(cm, key)
+		version := cm.Metadata.ResourceVersion
+		for {
+			watch := base + "?watch=1&allowWatchBookmarks=true&fieldSelector=" + url.QueryEscape("metadata.name="+name) + "&resourceVersion=" + url.QueryEscape(version)
+			err := kubeGet(client, watch, func(resp *http.Response) error {
+				return readConfigMapEvents(resp, key, &version)
+			})
+			if err == errResourceGone {
+				Info("log configmap ", namespace, "/", name, " resource version expired, relist")
+				break
+			}
+			if err != nil {
+				Warn("watch log configmap ", namespace, "/", name, " error: ", err)
+			}
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// 读取 watch 返回的事件，记录最后的 resourceVersion，收到 410 的 ERROR 事件时返回 errResourceGone
+func readConfigMapEvents(resp *http.Response, key string, version *string) error {
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event configMapEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if event.Type == "ERROR" {
+			var status kubeStatus
+			if err := json.Unmarshal(event.Object, &status); err == nil && status.Code == http.StatusGone {
+				return errResourceGone
+			}
+			return fmt.Errorf("kubernetes watch error: %s", event.Object)
+		}
+		var cm configMap
+		if err := json.Unmarshal(event.Object, &cm); err != nil {
+			continue
+		}
+		if cm.Metadata.ResourceVersion != "" {
+			*version = cm.Metadata.ResourceVersion
+		}
+		if event.Type == "ADDED" || event.Type == "MODIFIED" {
+			applyLiveConfig(cm, key)
+		}
+	}
+	return scanner.Err()
+}
+
+// token 会定期轮换，每次请求重新读取
+func kubeGet(client *http.Client, u string, handle func(*http.Response) error) error {
+	token, err := ioutil.ReadFile(serviceAccountDir + "token")
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusGone {
+		return errResourceGone
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kubernetes api status %d", resp.StatusCode)
+	}
+	return handle(resp)
+}
+
+func applyLiveConfig(cm configMap, key string) {
+	data, ok := cm.Data[key]
+	if !ok {
+		return
+	}
+	var config liveConfig
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		Warn("log configmap key ", key, " is not valid json: ", err)
+		return
+	}
+	if config.Level == "" {
+		return
+	}
+	if err := SetLevel(config.Level); err != nil {
+		Warn("log configmap level ", config.Level, " error: ", err)
+	}
+}
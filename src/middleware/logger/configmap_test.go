@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func watchResponse(lines ...string) *http.Response {
+	return &http.Response{Body: ioutil.NopCloser(strings.NewReader(strings.Join(lines, "\n")))}
+}
+
+// watch 正常结束时记录最后的 resourceVersion，下次从这里继续
+func TestReadConfigMapEventsTracksVersion(t *testing.T) {
+	version := "1"
+	resp := watchResponse(
+		`{"type":"BOOKMARK","object":{"metadata":{"resourceVersion":"5"}}}`,
+		`{"type":"BOOKMARK","object":{"metadata":{"resourceVersion":"7"}}}`,
+	)
+	if err := readConfigMapEvents(resp, "zap.json", &version); err != nil {
+		t.Fatalf("readConfigMapEvents error: %v", err)
+	}
+	if version != "7" {
+		t.Errorf("version = %q, want 7", version)
+	}
+}
+
+// 410 的 ERROR 事件需要重新读取
+func TestReadConfigMapEventsGone(t *testing.T) {
+	version := "1"
+	resp := watchResponse(
+		`{"type":"BOOKMARK","object":{"metadata":{"resourceVersion":"5"}}}`,
+		`{"type":"ERROR","object":{"kind":"Status","code":410,"reason":"Expired"}}`,
+		`{"type":"BOOKMARK","object":{"metadata":{"resourceVersion":"9"}}}`,
+	)
+	if err := readConfigMapEvents(resp, "zap.json", &version); err != errResourceGone {
+		t.Fatalf("err = %v, want errResourceGone", err)
+	}
+	if version != "5" {
+		t.Errorf("version = %q, want 5", version)
+	}
+}
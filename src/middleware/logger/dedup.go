@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+	"github.com/iguidao/redis-manager/src/middleware/stderr"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 在时间窗口内相同级别、相同内容、相同字段的日志只输出一次
+// 窗口结束时如果有被合并的日志，输出一条 "repeated N times" 的汇总
+type dedupCore struct {
+	zapcore.Core
+	prefix string // With 带上的字段，参与去重的key计算
+	state  *dedupState
+}
+
+type dedupEntry struct {
+	core  zapcore.Core
+	entry zapcore.Entry
+	first time.Time
+	count int
+}
+
+type dedupState struct {
+	sync.Mutex
+	window  time.Duration
+	entries map[uint64]*dedupEntry
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newDedupCore(core zapcore.Core, window time.Duration) zapcore.Core {
+	state := &dedupState{
+		window:  window,
+		entries: make(map[uint64]*dedupEntry),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	goroutine.Go("log dedup flush", state.flushLoop)
+	onClose(state.close)
+	return &dedupCore{Core: core, state: state}
+}
+
+// 停止定时汇总，还没输出的汇总立即输出
+func (s *dedupState) close() {
+	close(s.stop)
+	<-s.done
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{
+		Core:   c.Core.With(fields),
+		prefix: c.prefix + fieldsKey(fields),
+		state:  c.state,
+	}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	h := fnv.New64a()
+	h.Write([]byte(ent.Level.String() + "\x00" + c.prefix + "\x00" + ent.Message + "\x00" + fieldsKey(fields)))
+	key := h.Sum64()
+	now := time.Now()
+
+	c.state.Lock()
+	last, ok := c.state.entries[key]
+	if ok && now.Sub(last.first) < c.state.window {
+		last.count++
+		c.state.Unlock()
+		return nil
+	}
+	c.state.entries[key] = &dedupEntry{core: c.Core, entry: ent, first: now}
+	c.state.Unlock()
+	if ok && last.count > 0 {
+		writeRepeated(last)
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// 定时输出过期窗口的汇总，并清理过期的记录
+func (s *dedupState) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			s.flush(time.Now(), true)
+			return
+		case now := <-ticker.C:
+			s.flush(now, false)
+		}
+	}
+}
+
+// all 为 true 时不管窗口是否结束都输出
+func (s *dedupState) flush(now time.Time, all bool) {
+	var expired []*dedupEntry
+	s.Lock()
+	for key, v := range s.entries {
+		if all || now.Sub(v.first) >= s.window {
+			delete(s.entries, key)
+			if v.count > 0 {
+				expired = append(expired, v)
+			}
+		}
+	}
+	s.Unlock()
+	for _, v := range expired {
+		writeRepeated(v)
+	}
+}
+
+func writeRepeated(v *dedupEntry) {
+	ent := v.entry
+	ent.Time = time.Now()
+	ent.Message = "repeated " + strconv.Itoa(v.count) + " times: " + ent.Message
+	if err := v.core.Write(ent, nil); err != nil {
+		stderr.Println("log dedup write err, ", err.Error())
+	}
+}
+
+func fieldsKey(fields []zapcore.Field) string {
+	var key string
+	for _, f := range fields {
+		key += fmt.Sprintf("%s=%d/%d/%s/%v;", f.Key, f.Type, f.Integer, f.String, f.Interface)
+	}
+	return key
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/stderr"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	fallbackFailures = 3                // 连续失败多少次后切换到 stderr
+	fallbackRetry    = 30 * time.Second // 切换后每隔多久重试一次文件
+)
+
+// 磁盘满了或者目录变成只读时文件写入会一直失败，连续失败后改写 stderr，
+// 只提示一次，之后定期重试文件，写入成功后恢复
+type fallbackWriteSyncer struct {
+	sync.Mutex
+	ws        zapcore.WriteSyncer
+	failures  int
+	degraded  bool
+	lastRetry time.Time
+}
+
+func newFallbackWriteSyncer(ws zapcore.WriteSyncer) zapcore.WriteSyncer {
+	return &fallbackWriteSyncer{ws: ws}
+}
+
+func (f *fallbackWriteSyncer) Write(p []byte) (int, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.degraded && time.Since(f.lastRetry) < fallbackRetry {
+		return os.Stderr.Write(p)
+	}
+	n, err := f.ws.Write(p)
+	if err == nil {
+		if f.degraded {
+			stderr.Println("log file writable again, leave degraded mode")
+		}
+		f.failures = 0
+		f.degraded = false
+		return n, nil
+	}
+	f.failures++
+	if f.degraded {
+		f.lastRetry = time.Now()
+	} else if f.failures >= fallbackFailures {
+		f.degraded = true
+		f.lastRetry = time.Now()
+		stderr.Printf("log file write failed %d times, fallback to stderr: %s\n", f.failures, err.Error())
+	}
+	// 不能用 logger 输出，当前这条写到 stderr，避免丢失
+	return os.Stderr.Write(p)
+}
+
+func (f *fallbackWriteSyncer) Sync() error {
+	f.Lock()
+	defer f.Unlock()
+	if f.degraded {
+		return nil
+	}
+	return f.ws.Sync()
+}
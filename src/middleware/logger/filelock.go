@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"os"
+	"sync"
+
+	"github.com/iguidao/redis-manager/src/middleware/stderr"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 多个进程写同一个日志文件时，每次写入前加文件锁，避免日志行交错
+// 轮转也在锁内完成：按磁盘上的实际大小判断是否轮转，发现文件已被其他进程轮转时重新打开
+type lockedWriteSyncer struct {
+	sync.Mutex
+	lockfile *os.File
+	rotate   *lumberjack.Logger
+	opened   os.FileInfo
+}
+
+func newLockedWriteSyncer(rotate *lumberjack.Logger) (*lockedWriteSyncer, error) {
+	lockfile, err := os.OpenFile(rotate.Filename+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &lockedWriteSyncer{lockfile: lockfile, rotate: rotate}, nil
+}
+
+func (l *lockedWriteSyncer) Write(p []byte) (int, error) {
+	l.Lock()
+	defer l.Unlock()
+	if err := lockFile(l.lockfile); err == nil {
+		defer unlockFile(l.lockfile)
+	}
+	l.reopenIfRotated(int64(len(p)))
+	n, err := l.rotate.Write(p)
+	if l.opened == nil {
+		l.opened, _ = os.Stat(l.rotate.Filename)
+	}
+	return n, err
+}
+
+// 持有文件锁时调用，其他进程轮转过文件则关闭旧文件，下次写入时 lumberjack 会以追加方式重新打开
+// lumberjack 自己新建的文件不是 O_APPEND 打开的，会覆盖其他进程的写入，所以轮转和新建后都关闭一次
+func (l *lockedWriteSyncer) reopenIfRotated(size int64) {
+	info, err := os.Stat(l.rotate.Filename)
+	if os.IsNotExist(err) {
+		l.rotate.Close()
+		l.opened = nil
+		if f, err := os.OpenFile(l.rotate.Filename, os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+			f.Close()
+		}
+		return
+	}
+	if err != nil {
+		return
+	}
+	if l.opened != nil && !os.SameFile(l.opened, info) {
+		l.rotate.Close()
+		l.opened = nil
+		return
+	}
+	// 各进程的 lumberjack 只知道自己写入的大小，这里按磁盘上的大小轮转
+	if l.rotate.MaxSize > 0 && info.Size()+size >= int64(l.rotate.MaxSize)*1024*1024 {
+		if err := l.rotate.Rotate(); err != nil {
+			stderr.Println("rotate log file err, ", err.Error())
+		}
+		l.rotate.Close()
+		l.opened = nil
+	}
+}
+
+func (l *lockedWriteSyncer) Sync() error {
+	return nil
+}
+
+func (l *lockedWriteSyncer) Close() error {
+	l.Lock()
+	defer l.Unlock()
+	l.lockfile.Close()
+	return l.rotate.Close()
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+// +build !windows
+
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 每个 writer 有自己的 lumberjack 和锁文件句柄，和多个进程写同一个文件的情况一致
+func TestLockedWriteSyncerConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	const writers, lines = 4, 6000
+	line := strings.Repeat("x", 80)
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		locked, err := newLockedWriteSyncer(&lumberjack.Logger{Filename: filename, MaxSize: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(w int, locked *lockedWriteSyncer) {
+			defer wg.Done()
+			defer locked.Close()
+			for i := 0; i < lines; i++ {
+				if _, err := locked.Write([]byte(fmt.Sprintf("%d %05d %s\n", w, i, line))); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w, locked)
+	}
+	wg.Wait()
+
+	files, err := filepath.Glob(filepath.Join(dir, "app*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("expected rotated files, got %v", files)
+	}
+	seen := make(map[string]bool)
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 1024*1024 {
+			t.Errorf("%s is %d bytes, over max size", name, info.Size())
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			text := scanner.Text()
+			var w, i int
+			var rest string
+			if n, _ := fmt.Sscanf(text, "%d %d %s", &w, &i, &rest); n != 3 || rest != line {
+				t.Fatalf("interleaved line in %s: %q", name, text)
+			}
+			seen[text[:8]] = true
+		}
+		f.Close()
+	}
+	if len(seen) != writers*lines {
+		t.Errorf("got %d lines, want %d", len(seen), writers*lines)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// 阻塞等锁时会被 go runtime 的信号打断，返回 EINTR 时重试
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
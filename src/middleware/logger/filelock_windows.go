@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+package logger
+
+import "os"
+
+// windows 下不加锁，依赖 O_APPEND 写入
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 文件写入开启缓冲后，级别不低于 level 的日志写完立即 Sync，
+// 避免进程崩溃前的错误日志还留在缓冲区里
+type flushCore struct {
+	zapcore.Core
+	ws    zapcore.WriteSyncer
+	level zapcore.Level
+}
+
+func newFlushCore(core zapcore.Core, ws zapcore.WriteSyncer, level zapcore.Level) zapcore.Core {
+	return &flushCore{Core: core, ws: ws, level: level}
+}
+
+func (c *flushCore) With(fields []zapcore.Field) zapcore.Core {
+	return &flushCore{Core: c.Core.With(fields), ws: c.ws, level: c.level}
+}
+
+func (c *flushCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *flushCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	if ent.Level >= c.level {
+		if serr := c.ws.Sync(); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// 缓冲写入，size 单位是KB，缓冲区满或者每隔 interval 刷一次盘
+func newBufferedWriter(ws zapcore.WriteSyncer, size int) *zapcore.BufferedWriteSyncer {
+	return &zapcore.BufferedWriteSyncer{
+		WS:            ws,
+		Size:          size * 1024,
+		FlushInterval: 30 * time.Second,
+	}
+}
+
+// 解析立即刷盘的级别，默认 warn
+func flushLevel(name string) zapcore.Level {
+	var level zapcore.Level
+	if name == "" || level.UnmarshalText([]byte(name)) != nil {
+		return zapcore.WarnLevel
+	}
+	return level
+}
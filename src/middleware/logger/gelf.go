@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+	"github.com/iguidao/redis-manager/src/middleware/stderr"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	gelfChunkSize = 8192 // 单个udp包的最大长度，包含12字节的分片头
+	gelfMaxChunks = 128  // GELF 规定一条消息最多128个分片
+)
+
+var gelfPool = buffer.NewPool()
+
+// GELF 1.1 格式，host、short_message、level 等放在顶层，其他字段加 _ 前缀
+type gelfEncoder struct {
+	zapcore.Encoder
+	host string
+}
+
+func newGelfEncoder(encoder zapcore.EncoderConfig) zapcore.Encoder {
+	host, _ := os.Hostname()
+	// time、level、msg、stacktrace 由 gelfEncoder 自己输出
+	encoder.TimeKey = ""
+	encoder.LevelKey = ""
+	encoder.MessageKey = ""
+	encoder.StacktraceKey = ""
+	encoder.CallerKey = "caller"
+	encoder.NameKey = "logger"
+	encoder.LineEnding = "\n"
+	return &gelfEncoder{Encoder: zapcore.NewJSONEncoder(encoder), host: host}
+}
+
+func (e *gelfEncoder) Clone() zapcore.Encoder {
+	return &gelfEncoder{Encoder: e.Encoder.Clone(), host: e.host}
+}
+
+func (e *gelfEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	rest, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer rest.Free()
+	var extra map[string]json.RawMessage
+	if err := json.Unmarshal(rest.Bytes(), &extra); err != nil {
+		return nil, err
+	}
+	msg := map[string]interface{}{
+		"version":       "1.1",
+		"host":          e.host,
+		"short_message": ent.Message,
+		"timestamp":     float64(ent.Time.UnixNano()/int64(time.Millisecond)) / 1000,
+		"level":         gelfLevel(ent.Level),
+	}
+	if ent.Stack != "" {
+		msg["full_message"] = ent.Message + "\n" + ent.Stack
+	}
+	for k, v := range extra {
+		// 全局字段里的 host 覆盖机器名
+		if k == "host" {
+			var host string
+			if json.Unmarshal(v, &host) == nil && host != "" {
+				msg["host"] = host
+				continue
+			}
+		}
+		msg[gelfField(k)] = v
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	line := gelfPool.Get()
+	line.Write(data)
+	line.AppendByte('\n')
+	return line, nil
+}
+
+// zap 级别对应 syslog 的级别
+func gelfLevel(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// 自定义字段需要 _ 前缀，_id 是保留字段，字段名只能包含字母数字和 _ . -
+func gelfField(key string) string {
+	key = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, key)
+	if key == "id" {
+		key = "id_"
+	}
+	return "_" + key
+}
+
+// 通过udp发送到 graylog，超过一个包大小的消息按 GELF 的格式分片，超过128片的丢弃
+type gelfWriter struct {
+	conn net.Conn
+	ch   chan []byte
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newGelfWriter(addr string) (*gelfWriter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	w := &gelfWriter{
+		conn: conn,
+		ch:   make(chan []byte, streamBuffer),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	goroutine.GoRestart("log gelf", w.loop, 0, nil)
+	onClose(w.close)
+	return w, nil
+}
+
+// 发送完缓冲里的日志后关闭连接，之后写入的日志直接丢弃
+func (w *gelfWriter) close() {
+	close(w.stop)
+	<-w.done
+	w.conn.Close()
+}
+
+func (w *gelfWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.stop:
+		return len(p), nil
+	default:
+	}
+	entry := make([]byte, len(p))
+	copy(entry, p)
+	select {
+	case w.ch <- entry:
+	default:
+	}
+	return len(p), nil
+}
+
+func (w *gelfWriter) Sync() error {
+	return nil
+}
+
+func (w *gelfWriter) loop() {
+	var lasterr time.Time
+	for {
+		var entry []byte
+		select {
+		case entry = <-w.ch:
+		case <-w.stop:
+			// 发送完缓冲里剩下的日志再退出
+			select {
+			case entry = <-w.ch:
+			default:
+				close(w.done)
+				return
+			}
+		}
+		err := w.send(strings.TrimRight(string(entry), "\r\n"))
+		// 不能用 logger 输出，避免循环写入，错误每分钟最多打印一次
+		if err != nil && time.Since(lasterr) > time.Minute {
+			lasterr = time.Now()
+			stderr.Println("log gelf send err, ", err.Error())
+		}
+	}
+}
+
+func (w *gelfWriter) send(msg string) error {
+	if len(msg) <= gelfChunkSize {
+		_, err := w.conn.Write([]byte(msg))
+		return err
+	}
+	size := gelfChunkSize - 12
+	count := (len(msg) + size - 1) / size
+	if count > gelfMaxChunks {
+		return fmt.Errorf("gelf message too large: %d bytes", len(msg))
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk := make([]byte, 0, 12+end-i*size)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*size:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 发送到 graylog 的 core，固定使用 GELF 格式
+func newGelfCore(encoder zapcore.EncoderConfig, level zapcore.LevelEnabler, w *gelfWriter) zapcore.Core {
+	return zapcore.NewCore(newGelfEncoder(encoder), zapcore.AddSync(w), level)
+}
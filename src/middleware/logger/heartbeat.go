@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+)
+
+var (
+	startTime = time.Now()
+
+	heartbeatLock   sync.Mutex
+	heartbeatStop   chan struct{}
+	heartbeatDone   chan struct{}
+	instanceCounter func() int
+)
+
+// 注册 heartbeat 日志里实例个数的来源，logger 不能依赖数据库
+func SetInstanceCounter(fn func() int) {
+	heartbeatLock.Lock()
+	instanceCounter = fn
+	heartbeatLock.Unlock()
+}
+
+// 每隔 interval 输出一条 heartbeat 日志，给基于日志的告警判断进程存活
+// 重复调用时先停掉之前的
+func startHeartbeat(interval time.Duration) {
+	stopHeartbeat()
+	heartbeatLock.Lock()
+	heartbeatStop = make(chan struct{})
+	heartbeatDone = make(chan struct{})
+	stop, done := heartbeatStop, heartbeatDone
+	goroutine.Go("log heartbeat", func() { heartbeatLoop(interval, stop, done) })
+	heartbeatLock.Unlock()
+}
+
+func stopHeartbeat() {
+	heartbeatLock.Lock()
+	stop, done := heartbeatStop, heartbeatDone
+	heartbeatStop, heartbeatDone = nil, nil
+	heartbeatLock.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func heartbeatLoop(interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			With("uptime", time.Since(startTime).Truncate(time.Second).String(), "instances", countInstances()).Info("heartbeat")
+		}
+	}
+}
+
+// 没有注册或者统计失败时返回 -1
+func countInstances() (count int) {
+	heartbeatLock.Lock()
+	fn := instanceCounter
+	heartbeatLock.Unlock()
+	if fn == nil {
+		return -1
+	}
+	if !goroutine.Run("log heartbeat instance counter", func() { count = fn() }) {
+		return -1
+	}
+	return count
+}
+
+var (
+	closeLock sync.Mutex
+	closers   []func()
+)
+
+// 注册 Close 时需要停止的后台 goroutine 或者关闭的文件，按注册的逆序执行
+// 先注册文件，后注册写入文件的 core，关闭时先停止 core 再关闭文件
+func onClose(fn func()) {
+	closeLock.Lock()
+	closers = append(closers, fn)
+	closeLock.Unlock()
+}
+
+// 进程退出前调用，停止 heartbeat、日志清理、去重、静默检测和 stream、gelf 的发送，
+// 把缓冲的日志写入文件后关闭文件
+func Close() error {
+	stopHeartbeat()
+	stopRetentionSweep()
+	var err error
+	if s, ok := current().(interface{ Sync() error }); ok {
+		err = s.Sync()
+	}
+	closeLock.Lock()
+	fns := closers
+	closers = nil
+	closeLock.Unlock()
+	for i := len(fns) - 1; i >= 0; i-- {
+		fns[i]()
+	}
+	return err
+}
@@ -1,101 +1,422 @@
 package logger
 
 import (
-	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/stderr"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// error logger
+// SetupLogger 创建的 logger，只在 SetupLogger 里写入，包内通过 current() 读取
 var ErrorLogger *zap.SugaredLogger
 
+// 编译时通过 -ldflags "-X github.com/iguidao/redis-manager/src/middleware/logger.Version=xxx" 设置
+var Version = "dev"
+
+// 日志后端，包里的函数都通过它输出，默认是zap
+type Logger interface {
+	Debug(args ...interface{})
+	Debugf(template string, args ...interface{})
+	Info(args ...interface{})
+	Infof(template string, args ...interface{})
+	Warn(args ...interface{})
+	Warnf(template string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(template string, args ...interface{})
+	DPanic(args ...interface{})
+	DPanicf(template string, args ...interface{})
+	Panic(args ...interface{})
+	Panicf(template string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalf(template string, args ...interface{})
+	With(args ...interface{}) Logger
+}
+
+// 当前的日志后端，SetLogger 和打日志可能并发，通过 atomic.Value 读写
+var (
+	std     atomic.Value
+	stdOnce sync.Once
+)
+
+// atomic.Value 要求每次存的类型一致
+type loggerHolder struct {
+	Logger
+}
+
+type zapLogger struct {
+	*zap.SugaredLogger
+}
+
+func (l zapLogger) With(args ...interface{}) Logger {
+	return zapLogger{l.SugaredLogger.With(args...)}
+}
+
+// 替换日志后端
+func SetLogger(l Logger) {
+	std.Store(loggerHolder{l})
+}
+
+// SetupLogger 之前打日志时使用默认的 logger：console 格式、info 级别、输出到 stderr
+func current() Logger {
+	if h, ok := std.Load().(loggerHolder); ok {
+		return h.Logger
+	}
+	stdOnce.Do(func() {
+		std.CompareAndSwap(nil, loggerHolder{defaultLogger()})
+	})
+	return std.Load().(loggerHolder).Logger
+}
+
+func defaultLogger() Logger {
+	encoder := zap.NewDevelopmentEncoderConfig()
+	encoder.EncodeTime = zapcore.ISO8601TimeEncoder
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(encoder), zapcore.Lock(os.Stderr), zapcore.InfoLevel)
+	return zapLogger{zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1)).Sugar()}
+}
+
+// 带上固定字段的logger
+func With(args ...interface{}) Logger {
+	return current().With(args...)
+}
+
 func SetupLogger() *zap.SugaredLogger {
 	err := os.Mkdir("./logs/", os.ModePerm)
 	if err != nil && !strings.Contains(err.Error(), "exists") {
-		fmt.Println("create logs dir err, ", err.Error())
+		stderr.Println("create logs dir err, ", err.Error())
 	}
 	fileName := "./logs/" + cfg.Get_Info_String("logapppath")
-	syncWriter := zapcore.AddSync(&lumberjack.Logger{
-		Filename:  fileName,
-		MaxSize:   1024,
-		LocalTime: true,
-		Compress:  true,
-	})
+	// 没有配置 logmaxsizemb 时 app 日志按 defaultMaxSizeMB 轮转
+	rotation := currentRotateConfig()
+	if rotation.MaxSizeMB <= 0 {
+		rotation.MaxSizeMB = defaultMaxSizeMB
+	}
+	rotate := newRotateLogger(fileName, rotation)
+	syncWriter := zapcore.AddSync(rotate)
+	cores := []string{"stdout", "file"}
+	closefile := func() { rotate.Close() }
+	if cfg.Get_Info_Bool("logfilelock") {
+		if locked, err := newLockedWriteSyncer(rotate); err != nil {
+			stderr.Println("open log lock file err, ", err.Error())
+		} else {
+			syncWriter = locked
+			closefile = func() { locked.Close() }
+			cores = append(cores, "filelock")
+		}
+	}
+	// 最先注册，Close 时最后关闭
+	onClose(closefile)
+	// 文件连续写入失败时改写 stderr，定期重试文件
+	if cfg.Get_Info_Bool("logfilefallback") {
+		syncWriter = newFallbackWriteSyncer(syncWriter)
+		cores = append(cores, "fallback")
+	}
+	// 开启缓冲后，warn 及以上级别（可配置）的日志会立即刷盘
+	bufferkb := cfg.Get_Info_Int("logbufferkb")
+	flushlevel := flushLevel(cfg.Get_Info_String("logflushlevel"))
+	var buffered *zapcore.BufferedWriteSyncer
+	if bufferkb > 0 {
+		buffered = newBufferedWriter(syncWriter, bufferkb)
+		syncWriter = buffered
+		onClose(func() { buffered.Stop() })
+		cores = append(cores, "buffered")
+	}
 	encoder := zap.NewDevelopmentEncoderConfig()
 	encoder.EncodeTime = zapcore.ISO8601TimeEncoder
+	renameKeys(&encoder, cfg.Get_Info_Map("logkeys"))
 
-	var level zapcore.Level
-	level = zap.DebugLevel
+	level, badlevel := parseLevel(cfg.Get_Info_String("loglevel"))
+	atomLevel.SetLevel(level)
+	levelmode := effectiveLevelMode(cfg.Get_Info_String("loglevelmode"))
+	enabler := newLevelEnabler(levelmode)
+	format := effectiveFormat(cfg.Get_Info_String("logformat"))
 	core := zapcore.NewCore(
-		zapcore.NewConsoleEncoder(encoder),
+		newEncoder(format, encoder),
 		zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout),
 			syncWriter),
-		level,
+		enabler,
 	)
+	if buffered != nil {
+		core = newFlushCore(core, buffered, flushlevel)
+	}
+	// 配置了 logstreaminstance 时同时写一份到该实例的redis stream
+	if streaminstance := cfg.Get_Info_String("logstreaminstance"); streaminstance != "" {
+		stream := newStreamWriter(streaminstance, cfg.Get_Info_String("logstreamkey"), int64(cfg.Get_Info_Int("logstreammaxlen")))
+		core = zapcore.NewTee(core, newStreamCore(encoder, enabler, stream))
+		cores = append(cores, "stream")
+	}
+	// 配置了 loggelfaddr 时同时通过udp发送一份 GELF 格式的日志到 graylog
+	if gelfaddr := cfg.Get_Info_String("loggelfaddr"); gelfaddr != "" {
+		if gelf, err := newGelfWriter(gelfaddr); err != nil {
+			stderr.Println("create gelf writer err, ", err.Error())
+		} else {
+			core = zapcore.NewTee(core, newGelfCore(encoder, enabler, gelf))
+			cores = append(cores, "gelf")
+		}
+	}
+	silence := cfg.Get_Info_Int("logsilencealarmsec")
+	if silence > 0 {
+		core = newWatchdogCore(core, time.Duration(silence)*time.Second)
+		cores = append(cores, "watchdog")
+	}
+	heartbeat := cfg.Get_Info_Int("logheartbeatsec")
+	window := cfg.Get_Info_Int("logdedupwindowms")
+	if window > 0 {
+		core = newDedupCore(core, time.Duration(window)*time.Millisecond)
+		cores = append(cores, "dedup")
+	}
+	// 采样放在最外层，dedup 和 watchdog 的 Write 直接调用内层的 Write，放在里面会绕过采样
+	if levels := sampleLevels(cfg.Get_Info_String("logsamplelevels")); len(levels) > 0 {
+		first, thereafter := cfg.Get_Info_Int("logsamplefirst"), cfg.Get_Info_Int("logsamplethereafter")
+		if first <= 0 {
+			first = 10
+		}
+		if thereafter <= 0 {
+			thereafter = 100
+		}
+		core = newLevelSampleCore(core, levels, first, thereafter)
+		cores = append(cores, "sample")
+	}
+
+	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.Fields(globalFields()...))
+	sugar := logger.Sugar()
+	ErrorLogger = sugar
+	SetLogger(zapLogger{sugar})
+	// 输出一条生效的日志配置，方便排查
+	sugar.Infow("logger initialized",
+		"level", level.String(),
+		"level_mode", levelmode,
+		"format", format,
+		"file", rotate.Filename,
+		"max_size_mb", rotate.MaxSize,
+		"max_backups", rotate.MaxBackups,
+		"max_age_days", rotation.MaxAgeDays,
+		"compress", rotate.Compress,
+		"local_time", rotate.LocalTime,
+		"dedup_window_ms", window,
+		"buffer_kb", bufferkb,
+		"flush_level", flushlevel.String(),
+		"heartbeat_sec", heartbeat,
+		"max_total_mb", cfg.Get_Info_Int("logmaxtotalmb"),
+		"cores", strings.Join(cores, ","),
+	)
+	warnUnknownLevel(sugar, badlevel)
+	if heartbeat > 0 {
+		startHeartbeat(time.Duration(heartbeat) * time.Second)
+	}
+	// 删除超过保留天数的轮转文件，总大小超过限制时从最旧的轮转文件开始删除
+	// lumberjack 不设置 MaxAge，只由这里清理
+	startRetentionSweep(retentionPolicy{
+		maxBytes: int64(cfg.Get_Info_Int("logmaxtotalmb")) * 1024 * 1024,
+		maxAge:   time.Duration(rotation.MaxAgeDays) * 24 * time.Hour,
+	})
+	// 配置了 logconfigmap 时监听 ConfigMap，修改后实时生效
+	if configmap := cfg.Get_Info_String("logconfigmap"); configmap != "" {
+		if err := WatchConfigMap(configmap, cfg.Get_Info_String("logconfigmapkey")); err != nil {
+			sugar.Warnw("watch log configmap failed", "configmap", configmap, "error", err)
+		}
+	}
+	return sugar
+}
 
-	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
-	ErrorLogger = logger.Sugar()
-	return ErrorLogger
+// 每条日志都带上的字段，host 和 version 可以在配置文件里覆盖
+func globalFields() []zap.Field {
+	fields := map[string]string{
+		"service": "redis-manager",
+		"version": Version,
+	}
+	if host, err := os.Hostname(); err == nil {
+		fields["host"] = host
+	}
+	for k, v := range cfg.Get_Info_Map("logfields") {
+		fields[k] = v
+	}
+	var keylist []string
+	for k := range fields {
+		keylist = append(keylist, k)
+	}
+	sort.Strings(keylist)
+	var result []zap.Field
+	for _, k := range keylist {
+		result = append(result, zap.String(k, fields[k]))
+	}
+	return result
+}
+
+// 运行中可以修改的日志级别，所有输出共用
+var atomLevel = zap.NewAtomicLevel()
+
+// 日志级别的匹配方式
+const (
+	LevelModeThreshold = "threshold" // 输出不低于 atomLevel 的日志
+	LevelModeExact     = "exact"     // 只输出等于 atomLevel 的日志
+)
+
+// 未配置或无法识别时使用 threshold
+func effectiveLevelMode(mode string) string {
+	if strings.ToLower(mode) == LevelModeExact {
+		return LevelModeExact
+	}
+	return LevelModeThreshold
+}
+
+// 按 mode 比较 atomLevel，SetLevel 修改后立即生效
+type levelEnabler struct {
+	exact bool
+}
+
+func newLevelEnabler(mode string) zapcore.LevelEnabler {
+	return levelEnabler{exact: effectiveLevelMode(mode) == LevelModeExact}
+}
+
+func (e levelEnabler) Enabled(lvl zapcore.Level) bool {
+	if e.exact {
+		return lvl == atomLevel.Level()
+	}
+	return atomLevel.Enabled(lvl)
+}
+
+// 当前生效的日志级别
+func Level() string {
+	return atomLevel.Level().String()
+}
+
+// 修改日志级别，立即生效
+func SetLevel(name string) error {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(name))); err != nil {
+		return err
+	}
+	if atomLevel.Level() != level {
+		atomLevel.SetLevel(level)
+		Warn("log level changed to ", level.String())
+	}
+	return nil
+}
+
+// 解析日志级别，未配置时为 debug，无法识别时回退到 info 并返回错误的值
+func parseLevel(name string) (zapcore.Level, string) {
+	if name == "" {
+		return zapcore.DebugLevel, ""
+	}
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(name))); err != nil {
+		return zapcore.InfoLevel, name
+	}
+	return level, ""
+}
+
+// 配置的日志级别无法识别时输出一条警告，name 为 parseLevel 返回的错误的值
+func warnUnknownLevel(l *zap.SugaredLogger, name string) {
+	if name != "" {
+		l.Warnw("unknown log level, fallback to info", "level", name)
+	}
+}
+
+// 按配置修改输出的字段名，为空的保持默认
+func renameKeys(encoder *zapcore.EncoderConfig, keys map[string]string) {
+	for k, v := range keys {
+		if v == "" {
+			continue
+		}
+		switch k {
+		case "time":
+			encoder.TimeKey = v
+		case "level":
+			encoder.LevelKey = v
+		case "message":
+			encoder.MessageKey = v
+		case "caller":
+			encoder.CallerKey = v
+		case "name":
+			encoder.NameKey = v
+		case "stacktrace":
+			encoder.StacktraceKey = v
+		}
+	}
+}
+
+func effectiveFormat(format string) string {
+	switch format {
+	case "json", "ndjson", "gelf":
+		return format
+	}
+	return "console"
+}
+
+// 日志格式：console（默认）、json、ndjson、gelf
+func newEncoder(format string, encoder zapcore.EncoderConfig) zapcore.Encoder {
+	switch format {
+	case "json":
+		return zapcore.NewJSONEncoder(encoder)
+	case "ndjson":
+		return newNdjsonEncoder(encoder)
+	case "gelf":
+		return newGelfEncoder(encoder)
+	default:
+		return &humanConsoleEncoder{Encoder: zapcore.NewConsoleEncoder(encoder)}
+	}
 }
 
 func Debug(args ...interface{}) {
-	ErrorLogger.Debug(args...)
+	current().Debug(args...)
 }
 
 func Debugf(template string, args ...interface{}) {
-	ErrorLogger.Debugf(template, args...)
+	current().Debugf(template, args...)
 }
 
 func Info(args ...interface{}) {
-	ErrorLogger.Info(args...)
+	current().Info(args...)
 }
 
 func Infof(template string, args ...interface{}) {
-	ErrorLogger.Infof(template, args...)
+	current().Infof(template, args...)
 }
 
 func Warn(args ...interface{}) {
-	ErrorLogger.Warn(args...)
+	current().Warn(args...)
 }
 
 func Warnf(template string, args ...interface{}) {
-	ErrorLogger.Warnf(template, args...)
+	current().Warnf(template, args...)
 }
 
 func Error(args ...interface{}) {
-	ErrorLogger.Error(args...)
+	current().Error(args...)
 }
 
 func Errorf(template string, args ...interface{}) {
-	ErrorLogger.Errorf(template, args...)
+	current().Errorf(template, args...)
 }
 
 func DPanic(args ...interface{}) {
-	ErrorLogger.DPanic(args...)
+	current().DPanic(args...)
 }
 
 func DPanicf(template string, args ...interface{}) {
-	ErrorLogger.DPanicf(template, args...)
+	current().DPanicf(template, args...)
 }
 
 func Panic(args ...interface{}) {
-	ErrorLogger.Panic(args...)
+	current().Panic(args...)
 }
 
 func Panicf(template string, args ...interface{}) {
-	ErrorLogger.Panicf(template, args...)
+	current().Panicf(template, args...)
 }
 
 func Fatal(args ...interface{}) {
-	ErrorLogger.Fatal(args...)
+	current().Fatal(args...)
 }
 
 func Fatalf(template string, args ...interface{}) {
-	ErrorLogger.Fatalf(template, args...)
+	current().Fatalf(template, args...)
 }
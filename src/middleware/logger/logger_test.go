@@ -0,0 +1,266 @@
+package logger
+
+import (
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := []struct {
+		name string
+		want zapcore.Level
+		bad  string
+	}{
+		{"", zapcore.DebugLevel, ""},
+		{"debug", zapcore.DebugLevel, ""},
+		{"WARN", zapcore.WarnLevel, ""},
+		{"error", zapcore.ErrorLevel, ""},
+		{"verbose", zapcore.InfoLevel, "verbose"},
+	}
+	for _, c := range cases {
+		level, bad := parseLevel(c.name)
+		if level != c.want || bad != c.bad {
+			t.Errorf("parseLevel(%q) = %v, %q, want %v, %q", c.name, level, bad, c.want, c.bad)
+		}
+	}
+}
+
+func TestUnknownLevelFallbackWarns(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(core).Sugar()
+
+	level, bad := parseLevel("verbose")
+	if level != zapcore.InfoLevel {
+		t.Fatalf("fallback level = %v, want info", level)
+	}
+	warnUnknownLevel(l, bad)
+	entries := logs.FilterMessage("unknown log level, fallback to info").All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d warnings, want 1", len(entries))
+	}
+	if entries[0].Level != zapcore.WarnLevel {
+		t.Errorf("warning level = %v, want warn", entries[0].Level)
+	}
+	if got := entries[0].ContextMap()["level"]; got != "verbose" {
+		t.Errorf("warning level field = %v, want verbose", got)
+	}
+
+	_, bad = parseLevel("info")
+	warnUnknownLevel(l, bad)
+	if logs.Len() != 1 {
+		t.Errorf("known level should not warn, got %d entries", logs.Len())
+	}
+}
+
+// SetupLogger 之前打日志不会panic，输出到 stderr
+func TestInfoBeforeSetup(t *testing.T) {
+	std = atomic.Value{}
+	stdOnce = sync.Once{}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() {
+		os.Stderr = stderr
+		std = atomic.Value{}
+		stdOnce = sync.Once{}
+	}()
+
+	Info("logged before setup")
+	Debug("debug is below the default level")
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "logged before setup") || !strings.Contains(string(out), "INFO") {
+		t.Errorf("stderr = %q, want the info entry", out)
+	}
+	if strings.Contains(string(out), "debug is below") {
+		t.Errorf("default logger should drop debug, got %q", out)
+	}
+}
+
+// 替换 logger 和打日志并发执行，需要 go test -race
+func TestSetLoggerConcurrentWithLogging(t *testing.T) {
+	defer func() {
+		std = atomic.Value{}
+		stdOnce = sync.Once{}
+	}()
+	core, _ := observer.New(zapcore.InfoLevel)
+	SetLogger(zapLogger{zap.New(core).Sugar()})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				core, _ := observer.New(zapcore.InfoLevel)
+				SetLogger(zapLogger{zap.New(core).Sugar()})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				Info("concurrent ", j)
+				With("j", j).Warn("concurrent with")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				Close()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Close 停止去重、静默检测和 gelf 发送，去重窗口内还没输出的汇总和 gelf 缓冲里的日志在 Close 时写出
+func TestCloseStopsBackgroundSinks(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	gelf, err := newGelfWriter(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	observed, logs := observer.New(zapcore.DebugLevel)
+	core := zapcore.NewTee(observed, zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(gelf), zapcore.DebugLevel))
+	core = newWatchdogCore(core, time.Hour)
+	core = newDedupCore(core, time.Hour)
+	l := zap.New(core)
+	SetLogger(zapLogger{l.Sugar()})
+	for i := 0; i < 3; i++ {
+		l.Info("same message")
+	}
+
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := logs.FilterMessage("repeated 2 times: same message").Len(); n != 1 {
+		t.Errorf("got %d dedup summaries after Close, want 1", n)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, gelfChunkSize)
+	for _, want := range []string{"same message", "repeated 2 times: same message"} {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read gelf: %v", err)
+		}
+		if !strings.Contains(string(buf[:n]), want) {
+			t.Errorf("gelf got %q, want %q", buf[:n], want)
+		}
+	}
+	// 关闭后写入直接丢弃，不会 panic
+	l.Info("after close")
+	if n := logs.FilterMessage("after close").Len(); n != 1 {
+		t.Errorf("file core should still be written after Close, got %d", n)
+	}
+}
+
+var allLevels = []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel, zapcore.DPanicLevel}
+
+// 每个级别写一条，返回收到的日志的级别
+func writeEachLevel(t *testing.T, l *zap.Logger, logs *observer.ObservedLogs) []zapcore.Level {
+	t.Helper()
+	for _, level := range allLevels {
+		if ce := l.Check(level, level.String()); ce != nil {
+			ce.Write()
+		}
+	}
+	var got []zapcore.Level
+	for _, entry := range logs.TakeAll() {
+		if entry.Message != entry.Level.String() {
+			t.Errorf("entry %q logged at %v", entry.Message, entry.Level)
+		}
+		got = append(got, entry.Level)
+	}
+	return got
+}
+
+// 不低于 threshold 的级别
+func levelsFrom(threshold zapcore.Level) []zapcore.Level {
+	var want []zapcore.Level
+	for _, level := range allLevels {
+		if level >= threshold {
+			want = append(want, level)
+		}
+	}
+	return want
+}
+
+func restoreLevel(t *testing.T) {
+	saved := atomLevel.Level()
+	SetLogger(zapLogger{zap.NewNop().Sugar()})
+	t.Cleanup(func() { atomLevel.SetLevel(saved) })
+}
+
+// threshold 时通过 SetLevel 修改后输出所有不低于该级别的日志，exact 时只输出该级别
+func TestLevelModes(t *testing.T) {
+	restoreLevel(t)
+	for _, mode := range []string{LevelModeThreshold, LevelModeExact} {
+		core, logs := observer.New(newLevelEnabler(mode))
+		l := zap.New(core)
+		for _, name := range []string{"debug", "info", "warn", "error", "dpanic"} {
+			if err := SetLevel(name); err != nil {
+				t.Fatal(err)
+			}
+			got := writeEachLevel(t, l, logs)
+			want := levelsFrom(atomLevel.Level())
+			if mode == LevelModeExact {
+				want = []zapcore.Level{atomLevel.Level()}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("mode %s level %s: got %v, want %v", mode, name, got, want)
+			}
+		}
+	}
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("SetLevel should reject unknown level")
+	}
+}
+
+// 和 SetupLogger 一样多个输出共用 atomLevel，每个输出都收到每一个不低于阈值的级别，且只收到一次
+func TestEveryLevelAtOrAboveThresholdReachesOutput(t *testing.T) {
+	restoreLevel(t)
+	enabler := newLevelEnabler(LevelModeThreshold)
+	file, filelogs := observer.New(enabler)
+	stream, streamlogs := observer.New(enabler)
+	l := zap.New(zapcore.NewTee(file, stream))
+	for _, threshold := range allLevels {
+		atomLevel.SetLevel(threshold)
+		for _, level := range allLevels {
+			if ce := l.Check(level, level.String()); ce != nil {
+				ce.Write()
+			}
+			for name, logs := range map[string]*observer.ObservedLogs{"file": filelogs, "stream": streamlogs} {
+				entries := logs.TakeAll()
+				if level < threshold {
+					if len(entries) != 0 {
+						t.Errorf("threshold %v: %s got %v entry", threshold, name, level)
+					}
+					continue
+				}
+				if len(entries) != 1 || entries[0].Level != level {
+					t.Errorf("threshold %v: %s got %d entries for %v, want exactly 1", threshold, name, len(entries), level)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"encoding/json"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const ndjsonTimeLayout = "2006-01-02T15:04:05.000Z0700"
+
+var ndjsonPool = buffer.NewPool()
+
+// 每行一个json，顶层字段固定按 time、level、msg 的顺序输出，其余字段跟在后面
+// 配置了 logkeys 时使用配置的字段名
+type ndjsonEncoder struct {
+	zapcore.Encoder
+	timeKey    string
+	levelKey   string
+	messageKey string
+}
+
+func newNdjsonEncoder(encoder zapcore.EncoderConfig) zapcore.Encoder {
+	defaults := zap.NewDevelopmentEncoderConfig()
+	e := &ndjsonEncoder{
+		timeKey:    ndjsonKey(encoder.TimeKey, defaults.TimeKey, "time"),
+		levelKey:   ndjsonKey(encoder.LevelKey, defaults.LevelKey, "level"),
+		messageKey: ndjsonKey(encoder.MessageKey, defaults.MessageKey, "msg"),
+	}
+	// time、level、msg 由 ndjsonEncoder 自己输出
+	encoder.TimeKey = ""
+	encoder.LevelKey = ""
+	encoder.MessageKey = ""
+	encoder.LineEnding = "\n"
+	e.Encoder = zapcore.NewJSONEncoder(encoder)
+	return e
+}
+
+// 没有改过默认值时使用 ndjson 自己的字段名
+func ndjsonKey(key, devkey, ndkey string) string {
+	if key == "" || key == devkey {
+		return ndkey
+	}
+	return key
+}
+
+func (e *ndjsonEncoder) Clone() zapcore.Encoder {
+	return &ndjsonEncoder{Encoder: e.Encoder.Clone(), timeKey: e.timeKey, levelKey: e.levelKey, messageKey: e.messageKey}
+}
+
+func (e *ndjsonEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	rest, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer rest.Free()
+	line := ndjsonPool.Get()
+	line.AppendByte('{')
+	appendJSONString(line, e.timeKey)
+	line.AppendByte(':')
+	appendJSONString(line, ent.Time.Format(ndjsonTimeLayout))
+	line.AppendByte(',')
+	appendJSONString(line, e.levelKey)
+	line.AppendByte(':')
+	appendJSONString(line, ent.Level.String())
+	line.AppendByte(',')
+	appendJSONString(line, e.messageKey)
+	line.AppendByte(':')
+	appendJSONString(line, ent.Message)
+	// rest 是 {...}\n 形式，去掉开头的 { 后拼接
+	other := rest.Bytes()
+	for len(other) > 0 && (other[len(other)-1] == '\n' || other[len(other)-1] == '\r') {
+		other = other[:len(other)-1]
+	}
+	if len(other) > 2 {
+		line.AppendByte(',')
+		line.Write(other[1:])
+	} else {
+		line.AppendByte('}')
+	}
+	line.AppendByte('\n')
+	return line, nil
+}
+
+func appendJSONString(buf *buffer.Buffer, s string) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		buf.AppendString(`""`)
+		return
+	}
+	buf.Write(b)
+}
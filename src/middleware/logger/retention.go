@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+)
+
+const (
+	// 检查日志总大小的间隔
+	diskSweepInterval = time.Minute
+	// 只按保留天数清理时的检查间隔
+	retentionInterval = 24 * time.Hour
+)
+
+// lumberjack 轮转文件名里的时间格式
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+var (
+	retentionLock sync.Mutex
+	retentionStop chan struct{}
+	retentionDone chan struct{}
+)
+
+type logSegment struct {
+	path    string
+	size    int64
+	modtime time.Time
+}
+
+// 轮转日志文件的清理规则，为0的规则不检查
+type retentionPolicy struct {
+	maxBytes int64         // app 和 api 日志（包括轮转后的文件）的总大小
+	maxAge   time.Duration // 轮转文件保留的时间
+}
+
+func (p retentionPolicy) enabled() bool {
+	return p.maxBytes > 0 || p.maxAge > 0
+}
+
+// 限制总大小时需要及时检查，只按保留天数清理时每天检查一次
+func (p retentionPolicy) interval() time.Duration {
+	if p.maxBytes > 0 {
+		return diskSweepInterval
+	}
+	return retentionInterval
+}
+
+// 启动时和之后定期按 policy 清理轮转后的日志文件，正在写入的文件不删除
+// 所有规则都在这一个 goroutine 里执行，没有规则时不启动
+func startRetentionSweep(policy retentionPolicy) {
+	stopRetentionSweep()
+	if !policy.enabled() {
+		return
+	}
+	retentionLock.Lock()
+	retentionStop = make(chan struct{})
+	retentionDone = make(chan struct{})
+	stop, done := retentionStop, retentionDone
+	goroutine.Go("log retention sweep", func() { retentionLoop(policy, stop, done) })
+	retentionLock.Unlock()
+}
+
+func stopRetentionSweep() {
+	retentionLock.Lock()
+	stop, done := retentionStop, retentionDone
+	retentionStop, retentionDone = nil, nil
+	retentionLock.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func retentionLoop(policy retentionPolicy, stop, done chan struct{}) {
+	defer close(done)
+	sweepLogFiles(policy)
+	ticker := time.NewTicker(policy.interval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			sweepLogFiles(policy)
+		}
+	}
+}
+
+// 清理 app 和 api 日志，先删除过期的文件，剩下的总大小超过限制时再从最旧的开始删除
+func sweepLogFiles(policy retentionPolicy) {
+	var paths []string
+	for _, name := range []string{"app", "api"} {
+		if path, err := LogFilePath(name); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	if policy.maxAge > 0 {
+		removeExpiredLogs(paths, policy.maxAge)
+	}
+	if policy.maxBytes > 0 {
+		capLogFiles(paths, policy.maxBytes)
+	}
+}
+
+// 删除 paths 轮转后超过 maxage 的文件
+func removeExpiredLogs(paths []string, maxage time.Duration) {
+	cutoff := time.Now().Add(-maxage)
+	for _, path := range paths {
+		for _, segment := range rotatedSegments(path) {
+			if !segmentTime(path, segment).Before(cutoff) {
+				continue
+			}
+			if err := os.Remove(segment.path); err != nil {
+				Error("remove log file ", segment.path, " error: ", err)
+				continue
+			}
+			Info("log file older than ", int(maxage.Hours()/24), " days, removed ", segment.path)
+		}
+	}
+}
+
+// paths 包括轮转文件的总大小超过 maxbytes 时从最旧的轮转文件开始删除
+func capLogFiles(paths []string, maxbytes int64) {
+	var total int64
+	var segments []logSegment
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			total += info.Size()
+		}
+		for _, segment := range rotatedSegments(path) {
+			total += segment.size
+			segments = append(segments, segment)
+		}
+	}
+	if total <= maxbytes {
+		return
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].modtime.Before(segments[j].modtime) })
+	for _, segment := range segments {
+		if total <= maxbytes {
+			return
+		}
+		if err := os.Remove(segment.path); err != nil {
+			Error("remove log file ", segment.path, " error: ", err)
+			continue
+		}
+		total -= segment.size
+		Warn("log files exceed ", maxbytes/1024/1024, "MB, removed ", segment.path)
+	}
+	if total > maxbytes {
+		Warn("log files still exceed ", maxbytes/1024/1024, "MB after removing all rotated files")
+	}
+}
+
+// lumberjack 轮转后的文件名为 app-2006-01-02T15-04-05.000.log，压缩后加 .gz
+func rotatedSegments(path string) []logSegment {
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext + "*")
+	if err != nil {
+		return nil
+	}
+	var result []logSegment
+	for _, match := range matches {
+		if match == path || !(strings.HasSuffix(match, ext) || strings.HasSuffix(match, ext+".gz")) {
+			continue
+		}
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		result = append(result, logSegment{path: match, size: info.Size(), modtime: info.ModTime()})
+	}
+	return result
+}
+
+// 优先使用文件名里的轮转时间，解析不了时用修改时间
+func segmentTime(path string, segment logSegment) time.Time {
+	ext := filepath.Ext(path)
+	stamp := strings.TrimPrefix(filepath.Base(segment.path), strings.TrimSuffix(filepath.Base(path), ext)+"-")
+	stamp = strings.TrimSuffix(strings.TrimSuffix(stamp, ".gz"), ext)
+	if t, err := time.ParseInLocation(rotatedTimeFormat, stamp, time.Local); err == nil {
+		return t
+	}
+	return segment.modtime
+}
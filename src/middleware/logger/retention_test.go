@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 轮转时间按文件名判断，文件名里没有时间时按修改时间，正在写入的文件不删除
+func TestRemoveExpiredLogs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	files := map[string]bool{
+		"app.log": true,
+		"app-" + old.Format(rotatedTimeFormat) + ".log":           false,
+		"app-" + old.Format(rotatedTimeFormat) + ".log.gz":        false,
+		"app-" + time.Now().Format(rotatedTimeFormat) + ".log.gz": true,
+		"app-backdated.log": false,
+		"app-recent.log":    true,
+		"api-" + old.Format(rotatedTimeFormat) + ".log": true,
+	}
+	for name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"app.log", "app-backdated.log"} {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removeExpiredLogs([]string{path}, 7*24*time.Hour)
+	for name, keep := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if keep && err != nil {
+			t.Errorf("%s should be kept, got %v", name, err)
+		}
+		if !keep && !os.IsNotExist(err) {
+			t.Errorf("%s should be removed, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"io"
+	"os"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 没有配置 logmaxsizemb 时 app 日志的轮转大小
+const defaultMaxSizeMB = 1024
+
+// 日志文件的轮转配置，0 表示不限制
+type RotateConfig struct {
+	MaxSizeMB  int // 按大小轮转，MB，0 不轮转
+	MaxBackups int // 保留的轮转文件个数
+	MaxAgeDays int // 轮转文件保留的天数，由 retention sweep 清理，lumberjack 不按时间清理
+}
+
+func currentRotateConfig() RotateConfig {
+	return RotateConfig{
+		MaxSizeMB:  cfg.Get_Info_Int("logmaxsizemb"),
+		MaxBackups: cfg.Get_Info_Int("logmaxbackups"),
+		MaxAgeDays: cfg.Get_Info_Int("logretentiondays"),
+	}
+}
+
+// 按大小轮转的日志文件，MaxAge 不设置，过期文件和总大小统一由 startRetentionSweep 清理
+func newRotateLogger(filename string, rotation RotateConfig) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    rotation.MaxSizeMB,
+		MaxBackups: rotation.MaxBackups,
+		LocalTime:  true,
+		Compress:   true,
+	}
+}
+
+// 接口访问日志的写入，配置了 logmaxsizemb 时按大小轮转，否则追加写入同一个文件
+func ApiLogWriter() (io.Writer, error) {
+	path, err := LogFilePath("api")
+	if err != nil {
+		return nil, err
+	}
+	if rotation := currentRotateConfig(); rotation.MaxSizeMB > 0 {
+		return newRotateLogger(path, rotation), nil
+	}
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 只对指定级别的日志采样，其他级别照常输出
+// 每秒内相同内容的日志输出前 first 条，之后每 thereafter 条输出一条
+type levelSampleCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+	levels  map[zapcore.Level]bool
+}
+
+func newLevelSampleCore(core zapcore.Core, levels map[zapcore.Level]bool, first, thereafter int) zapcore.Core {
+	return &levelSampleCore{
+		Core:    core,
+		sampled: zapcore.NewSamplerWithOptions(core, time.Second, first, thereafter),
+		levels:  levels,
+	}
+}
+
+func (c *levelSampleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelSampleCore{
+		Core:    c.Core.With(fields),
+		sampled: c.sampled.With(fields),
+		levels:  c.levels,
+	}
+}
+
+func (c *levelSampleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.levels[ent.Level] {
+		return c.sampled.Check(ent, ce)
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// 解析逗号分隔的级别列表，无法识别的忽略
+func sampleLevels(value string) map[zapcore.Level]bool {
+	levels := make(map[zapcore.Level]bool)
+	for _, v := range strings.Split(value, ",") {
+		var level zapcore.Level
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" || level.UnmarshalText([]byte(v)) != nil {
+			continue
+		}
+		levels[level] = true
+	}
+	return levels
+}
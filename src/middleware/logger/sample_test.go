@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// 和 SetupLogger 一样采样在最外层，dedup 和 watchdog 写入的日志也要经过采样
+func TestSampleOutermostCore(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := newWatchdogCore(obs, time.Hour)
+	core = newDedupCore(core, time.Millisecond)
+	core = newLevelSampleCore(core, map[zapcore.Level]bool{zapcore.InfoLevel: true}, 2, 1000)
+	l := zap.New(core).Sugar()
+
+	for i := 0; i < 10; i++ {
+		l.Info("sampled")
+		time.Sleep(2 * time.Millisecond)
+		l.Warn("not sampled")
+		time.Sleep(2 * time.Millisecond)
+	}
+	if n := logs.FilterMessage("sampled").Len(); n != 2 {
+		t.Errorf("info entries = %d, want 2", n)
+	}
+	if n := logs.FilterMessage("not sampled").Len(); n != 10 {
+		t.Errorf("warn entries = %d, want 10", n)
+	}
+}
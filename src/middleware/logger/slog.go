@@ -0,0 +1,88 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// slog 实现的日志后端，使用 SetLogger(NewSlogLogger(...)) 替换默认的zap
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l: l}
+}
+
+func (s slogLogger) Debug(args ...interface{}) {
+	s.l.Debug(fmt.Sprint(args...))
+}
+
+func (s slogLogger) Debugf(template string, args ...interface{}) {
+	s.l.Debug(fmt.Sprintf(template, args...))
+}
+
+func (s slogLogger) Info(args ...interface{}) {
+	s.l.Info(fmt.Sprint(args...))
+}
+
+func (s slogLogger) Infof(template string, args ...interface{}) {
+	s.l.Info(fmt.Sprintf(template, args...))
+}
+
+func (s slogLogger) Warn(args ...interface{}) {
+	s.l.Warn(fmt.Sprint(args...))
+}
+
+func (s slogLogger) Warnf(template string, args ...interface{}) {
+	s.l.Warn(fmt.Sprintf(template, args...))
+}
+
+func (s slogLogger) Error(args ...interface{}) {
+	s.l.Error(fmt.Sprint(args...))
+}
+
+func (s slogLogger) Errorf(template string, args ...interface{}) {
+	s.l.Error(fmt.Sprintf(template, args...))
+}
+
+// slog 没有 dpanic 级别，按 error 输出
+func (s slogLogger) DPanic(args ...interface{}) {
+	s.l.Error(fmt.Sprint(args...))
+}
+
+func (s slogLogger) DPanicf(template string, args ...interface{}) {
+	s.l.Error(fmt.Sprintf(template, args...))
+}
+
+func (s slogLogger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	s.l.Error(msg)
+	panic(msg)
+}
+
+func (s slogLogger) Panicf(template string, args ...interface{}) {
+	msg := fmt.Sprintf(template, args...)
+	s.l.Error(msg)
+	panic(msg)
+}
+
+func (s slogLogger) Fatal(args ...interface{}) {
+	s.l.Error(fmt.Sprint(args...))
+	Close()
+	os.Exit(1)
+}
+
+func (s slogLogger) Fatalf(template string, args ...interface{}) {
+	s.l.Error(fmt.Sprintf(template, args...))
+	Close()
+	os.Exit(1)
+}
+
+func (s slogLogger) With(args ...interface{}) Logger {
+	return slogLogger{l: s.l.With(args...)}
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+	"github.com/iguidao/redis-manager/src/middleware/metrics"
+	"github.com/iguidao/redis-manager/src/middleware/stderr"
+
+	"github.com/go-redis/redis/v9"
+	"go.uber.org/zap/zapcore"
+)
+
+const streamBuffer = 1024
+
+// 获取不到链接时重新获取的间隔
+const streamResolveInterval = 10 * time.Second
+
+var (
+	streamLock     sync.Mutex
+	streamResolver func(instanceid string) (*redis.Client, error)
+)
+
+// 注册通过实例ID获取 stream 链接的方法，logger 不能依赖 opredis
+func SetStreamResolver(fn func(instanceid string) (*redis.Client, error)) {
+	streamLock.Lock()
+	streamResolver = fn
+	streamLock.Unlock()
+}
+
+// 把json格式的日志 XADD 到实例的redis stream，写入在单独的goroutine里执行
+// 缓冲满了、还没获取到链接或者redis不可用时直接丢弃并计数，不影响文件日志
+type streamWriter struct {
+	instanceid string
+	key        string
+	maxlen     int64
+	ch         chan []byte
+	stop       chan struct{}
+	done       chan struct{}
+	dropped    uint64
+
+	client      *redis.Client
+	lastresolve time.Time
+}
+
+func newStreamWriter(instanceid, key string, maxlen int64) *streamWriter {
+	w := &streamWriter{
+		instanceid: instanceid,
+		key:        key,
+		maxlen:     maxlen,
+		ch:         make(chan []byte, streamBuffer),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	goroutine.GoRestart("log stream", w.loop, 0, nil)
+	onClose(w.close)
+	return w
+}
+
+// 写完缓冲里的日志后退出，之后写入的日志丢弃并计数，链接由 resolver 管理不在这里关闭
+func (w *streamWriter) close() {
+	close(w.stop)
+	<-w.done
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.stop:
+		w.drop()
+		return len(p), nil
+	default:
+	}
+	entry := make([]byte, len(p))
+	copy(entry, p)
+	select {
+	case w.ch <- entry:
+	default:
+		w.drop()
+	}
+	return len(p), nil
+}
+
+func (w *streamWriter) Sync() error {
+	return nil
+}
+
+// 丢弃的日志条数
+func (w *streamWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+func (w *streamWriter) drop() {
+	atomic.AddUint64(&w.dropped, 1)
+	metrics.Incr("redis_manager_log_stream_dropped_total", nil)
+}
+
+// 只在 loop 里调用，还没有链接时按间隔通过 resolver 获取
+func (w *streamWriter) resolve() *redis.Client {
+	if w.client != nil || time.Since(w.lastresolve) < streamResolveInterval {
+		return w.client
+	}
+	w.lastresolve = time.Now()
+	streamLock.Lock()
+	fn := streamResolver
+	streamLock.Unlock()
+	if fn == nil {
+		return nil
+	}
+	client, err := fn(w.instanceid)
+	if err != nil {
+		stderr.Println("log stream instance "+w.instanceid+" err, ", err.Error())
+		return nil
+	}
+	w.client = client
+	return client
+}
+
+func (w *streamWriter) loop() {
+	var lasterr time.Time
+	for {
+		var entry []byte
+		select {
+		case entry = <-w.ch:
+		case <-w.stop:
+			select {
+			case entry = <-w.ch:
+			default:
+				close(w.done)
+				return
+			}
+		}
+		client := w.resolve()
+		if client == nil {
+			w.drop()
+			continue
+		}
+		err := client.XAdd(context.Background(), &redis.XAddArgs{
+			Stream: w.key,
+			MaxLen: w.maxlen,
+			Approx: true,
+			Values: map[string]interface{}{"log": entry},
+		}).Err()
+		if err == nil {
+			continue
+		}
+		w.drop()
+		// 不能用 logger 输出，避免循环写入，错误每分钟最多打印一次
+		if time.Since(lasterr) > time.Minute {
+			lasterr = time.Now()
+			stderr.Println("log stream xadd err, dropped ", w.Dropped(), ", ", err.Error())
+		}
+	}
+}
+
+// 写入 stream 的 core，固定使用json格式
+func newStreamCore(encoder zapcore.EncoderConfig, level zapcore.LevelEnabler, w *streamWriter) zapcore.Core {
+	return zapcore.NewCore(zapcore.NewJSONEncoder(encoder), zapcore.AddSync(w), level)
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	ErrUnknownLogFile  = errors.New("unknown log file")
+	ErrUnknownLogLevel = errors.New("unknown log level")
+)
+
+const (
+	tailPollInterval = 500 * time.Millisecond
+	tailChunkSize    = 64 * 1024
+)
+
+// 日志名对应的文件，app 是程序日志，api 是gin的访问日志
+func LogFilePath(name string) (string, error) {
+	switch name {
+	case "app":
+		return "./logs/" + cfg.Get_Info_String("logapppath"), nil
+	case "api":
+		return "./logs/" + cfg.Get_Info_String("logapipath"), nil
+	}
+	return "", ErrUnknownLogFile
+}
+
+// 读取 app 日志里不低于 level 的最后 lines 行，follow 为 true 时继续输出新写入的行
+// 文件被轮转（inode变化）或者被截断时重新打开，ctx 结束时关闭channel
+func TailFile(ctx context.Context, level string, follow bool, lines int) (<-chan string, error) {
+	var min zapcore.Level
+	if err := min.UnmarshalText([]byte(strings.ToLower(level))); err != nil {
+		return nil, ErrUnknownLogLevel
+	}
+	path, err := LogFilePath("app")
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	last, offset, err := lastLines(f, lines, min)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	out := make(chan string, 100)
+	goroutine.Go("tail log", func() {
+		defer close(out)
+		defer func() { f.Close() }()
+		for _, line := range last {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if !follow {
+			return
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return
+		}
+		reader := bufio.NewReader(f)
+		filter := levelFilter{min: min}
+		var partial string
+		ticker := time.NewTicker(tailPollInterval)
+		defer ticker.Stop()
+		for {
+			for {
+				text, err := reader.ReadString('\n')
+				offset += int64(len(text))
+				if err != nil {
+					partial += text
+					break
+				}
+				line := strings.TrimRight(partial+text, "\r\n")
+				partial = ""
+				if !filter.match(line) {
+					continue
+				}
+				select {
+				case out <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if reopened, ok := reopenIfRotated(path, f, offset); ok {
+				f.Close()
+				f = reopened
+				offset = 0
+				partial = ""
+				reader = bufio.NewReader(f)
+			}
+		}
+	})
+	return out, nil
+}
+
+// 文件被轮转或者截断时返回新打开的文件
+func reopenIfRotated(path string, f *os.File, offset int64) (*os.File, bool) {
+	current, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	opened, err := f.Stat()
+	if err != nil {
+		return nil, false
+	}
+	if os.SameFile(current, opened) && current.Size() >= offset {
+		return nil, false
+	}
+	reopened, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	return reopened, true
+}
+
+// 从文件末尾往前读，返回不低于 min 的最后 n 行和文件大小
+// 每次读取的长度翻倍，直到找到 n 行或者读到文件开头
+func lastLines(f *os.File, n int, min zapcore.Level) ([]string, int64, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := stat.Size()
+	if n <= 0 {
+		return nil, size, nil
+	}
+	for chunk := int64(tailChunkSize); ; chunk *= 2 {
+		pos := size - chunk
+		if pos < 0 {
+			pos = 0
+		}
+		buf := make([]byte, size-pos)
+		if _, err := f.ReadAt(buf, pos); err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		all := strings.Split(strings.TrimRight(string(buf), "\n"), "\n")
+		// 不是从文件开头读的时候第一行不完整
+		if pos > 0 {
+			all = all[1:]
+		}
+		filter := levelFilter{min: min}
+		var result []string
+		for _, line := range all {
+			if filter.match(line) {
+				result = append(result, line)
+			}
+		}
+		if len(result) >= n || pos == 0 {
+			if len(result) > n {
+				result = result[len(result)-n:]
+			}
+			return result, size, nil
+		}
+	}
+}
+
+// 按级别过滤日志行，不带级别的行（例如堆栈）跟随前面最近一条带级别的行
+type levelFilter struct {
+	min     zapcore.Level
+	current zapcore.Level
+	known   bool
+}
+
+func (f *levelFilter) match(line string) bool {
+	if level, ok := lineLevel(line); ok {
+		f.current, f.known = level, true
+	}
+	return f.known && f.current >= f.min
+}
+
+// 解析一行日志的级别，console 格式为 时间\t级别\t...，json 和 ndjson 格式读取 L 或 level 字段
+func lineLevel(line string) (zapcore.Level, bool) {
+	var text string
+	if strings.HasPrefix(line, "{") {
+		var entry struct {
+			L     string `json:"L"`
+			Level string `json:"level"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return 0, false
+		}
+		text = entry.Level
+		if text == "" {
+			text = entry.L
+		}
+	} else {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			return 0, false
+		}
+		text = fields[1]
+	}
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(text))); err != nil {
+		return 0, false
+	}
+	return level, true
+}
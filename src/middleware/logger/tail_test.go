@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLineLevel(t *testing.T) {
+	cases := []struct {
+		line  string
+		level zapcore.Level
+		ok    bool
+	}{
+		{"2024-01-01T00:00:00.000+0800\tINFO\tmain.go:1\tstarted", zapcore.InfoLevel, true},
+		{"2024-01-01T00:00:00.000+0800\terror\tmain.go:1\tfailed", zapcore.ErrorLevel, true},
+		{`{"level":"warn","msg":"slow"}`, zapcore.WarnLevel, true},
+		{`{"L":"DEBUG","M":"detail"}`, zapcore.DebugLevel, true},
+		{"\tgoroutine 1 [running]:", 0, false},
+		{"{not json", 0, false},
+	}
+	for _, c := range cases {
+		level, ok := lineLevel(c.line)
+		if ok != c.ok || (ok && level != c.level) {
+			t.Errorf("lineLevel(%q) = %v, %v; want %v, %v", c.line, level, ok, c.level, c.ok)
+		}
+	}
+}
+
+// 不带级别的堆栈行跟随前一条日志的级别
+func TestLastLinesFiltersByLevel(t *testing.T) {
+	lines := []string{
+		"t\tINFO\tmain.go:1\tstarted",
+		"t\tERROR\tmain.go:2\tfailed",
+		"main.go:2 stack",
+		"t\tDEBUG\tmain.go:3\tdetail",
+		"t\tWARN\tmain.go:4\tslow",
+		"t\tINFO\tmain.go:5\tdone",
+	}
+	filename := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, _, err := lastLines(f, 10, zapcore.WarnLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{lines[1], lines[2], lines[4]}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("lastLines(warn) = %q, want %q", got, want)
+	}
+
+	got, _, err = lastLines(f, 2, zapcore.DebugLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, "\n") != strings.Join(lines[4:], "\n") {
+		t.Errorf("lastLines(debug, 2) = %q, want %q", got, lines[4:])
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// 带单位的字段，json 格式输出原始数值，console 格式输出 1.5s、12.3MB 这样的字符串
+type unitType int
+
+const (
+	unitDuration unitType = iota
+	unitBytes
+)
+
+// 耗时字段，json 里为纳秒数
+func Duration(key string, d time.Duration) zap.Field {
+	return zap.Field{Key: key, Type: zapcore.Int64Type, Integer: int64(d), Interface: unitDuration}
+}
+
+// 字节数字段，json 里为字节数
+func Bytes(key string, n int64) zap.Field {
+	return zap.Field{Key: key, Type: zapcore.Int64Type, Integer: n, Interface: unitBytes}
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	value := float64(n)
+	for _, suffix := range []string{"KB", "MB", "GB", "TB"} {
+		value /= unit
+		if value < unit && value > -unit || suffix == "TB" {
+			return fmt.Sprintf("%.1f%s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// console 格式把带单位的字段转成字符串，With 带上的字段保持原始数值
+type humanConsoleEncoder struct {
+	zapcore.Encoder
+}
+
+func (e *humanConsoleEncoder) Clone() zapcore.Encoder {
+	return &humanConsoleEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e *humanConsoleEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	human := fields
+	copied := false
+	for i, f := range fields {
+		unit, ok := f.Interface.(unitType)
+		if !ok || f.Type != zapcore.Int64Type {
+			continue
+		}
+		// 不修改调用方的 fields
+		if !copied {
+			human = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		switch unit {
+		case unitDuration:
+			human[i] = zap.String(f.Key, time.Duration(f.Integer).String())
+		case unitBytes:
+			human[i] = zap.String(f.Key, humanBytes(f.Integer))
+		}
+	}
+	return e.Encoder.EncodeEntry(ent, human)
+}
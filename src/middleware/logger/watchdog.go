@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 记录最后一条日志的时间，超过 interval 没有日志时触发回调
+type watchdogCore struct {
+	zapcore.Core
+	last *int64
+}
+
+var (
+	silenceLock      sync.Mutex
+	silenceListeners []func(time.Duration)
+)
+
+// 注册日志静默的回调，参数为距离上一条日志的时间
+// 没有注册回调时输出一条 heartbeat 日志
+func OnSilence(fn func(time.Duration)) {
+	silenceLock.Lock()
+	silenceListeners = append(silenceListeners, fn)
+	silenceLock.Unlock()
+}
+
+func newWatchdogCore(core zapcore.Core, interval time.Duration) zapcore.Core {
+	last := time.Now().UnixNano()
+	c := &watchdogCore{Core: core, last: &last}
+	stop, done := make(chan struct{}), make(chan struct{})
+	goroutine.Go("log watchdog", func() { c.loop(interval, stop, done) })
+	onClose(func() {
+		close(stop)
+		<-done
+	})
+	return c
+}
+
+func (c *watchdogCore) With(fields []zapcore.Field) zapcore.Core {
+	return &watchdogCore{Core: c.Core.With(fields), last: c.last}
+}
+
+func (c *watchdogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *watchdogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	atomic.StoreInt64(c.last, time.Now().UnixNano())
+	return c.Core.Write(ent, fields)
+}
+
+func (c *watchdogCore) loop(interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		silence := time.Since(time.Unix(0, atomic.LoadInt64(c.last)))
+		if silence < interval {
+			continue
+		}
+		silenceLock.Lock()
+		listeners := silenceListeners
+		silenceLock.Unlock()
+		if len(listeners) == 0 {
+			Warn("heartbeat: no logs written for ", silence.Truncate(time.Second).String())
+			continue
+		}
+		// 回调里不一定会打日志，重新计时避免每个周期都触发
+		atomic.StoreInt64(c.last, time.Now().UnixNano())
+		for _, fn := range listeners {
+			callSilence(fn, silence)
+		}
+	}
+}
+
+func callSilence(fn func(time.Duration), silence time.Duration) {
+	goroutine.Run("log watchdog callback", func() { fn(silence) })
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	COUNTER = "counter"
+	GAUGE   = "gauge"
+)
+
+// 进程内的指标
+type Sample struct {
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+var (
+	lock    sync.Mutex
+	samples = make(map[string]*Sample)
+)
+
+func sampleKey(name string, labels map[string]string) string {
+	var keylist []string
+	for k, v := range labels {
+		keylist = append(keylist, k+"="+v)
+	}
+	sort.Strings(keylist)
+	return name + "{" + strings.Join(keylist, ",") + "}"
+}
+
+func getSample(name, mtype string, labels map[string]string) *Sample {
+	key := sampleKey(name, labels)
+	sample, ok := samples[key]
+	if !ok {
+		copylabels := make(map[string]string)
+		for k, v := range labels {
+			copylabels[k] = v
+		}
+		sample = &Sample{Name: name, Type: mtype, Labels: copylabels}
+		samples[key] = sample
+	}
+	return sample
+}
+
+// counter 加1
+func Incr(name string, labels map[string]string) {
+	Add(name, 1, labels)
+}
+
+// counter 累加
+func Add(name string, value float64, labels map[string]string) {
+	lock.Lock()
+	getSample(name, COUNTER, labels).Value += value
+	lock.Unlock()
+}
+
+// gauge 设置
+func Set(name string, value float64, labels map[string]string) {
+	lock.Lock()
+	getSample(name, GAUGE, labels).Value = value
+	lock.Unlock()
+}
+
+// 获取所有指标，按名字排序
+func All() []Sample {
+	var result []Sample
+	var keylist []string
+	lock.Lock()
+	defer lock.Unlock()
+	for k := range samples {
+		keylist = append(keylist, k)
+	}
+	sort.Strings(keylist)
+	for _, k := range keylist {
+		sample := *samples[k]
+		result = append(result, sample)
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 转成 prometheus 的文本格式
+func Exposition(list []Sample) string {
+	var buf bytes.Buffer
+	typed := make(map[string]bool)
+	for _, v := range list {
+		if !typed[v.Name] {
+			fmt.Fprintf(&buf, "# TYPE %s %s\n", v.Name, v.Type)
+			typed[v.Name] = true
+		}
+		buf.WriteString(v.Name)
+		if len(v.Labels) > 0 {
+			var keylist []string
+			for k := range v.Labels {
+				keylist = append(keylist, k)
+			}
+			sort.Strings(keylist)
+			var labels []string
+			for _, k := range keylist {
+				labels = append(labels, k+"="+strconv.Quote(v.Labels[k]))
+			}
+			buf.WriteString("{" + strings.Join(labels, ",") + "}")
+		}
+		buf.WriteString(" " + strconv.FormatFloat(v.Value, 'g', -1, 64) + "\n")
+	}
+	return buf.String()
+}
+
+// 当前所有指标的文本格式，和 /metrics 接口返回的内容一致
+func MetricsText(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return Exposition(All()), nil
+}
+
+// 命令行一次性任务结束时把指标推送到 pushgateway，instance 标签为主机名
+func PushMetrics(gatewayURL, job string) error {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	pushurl := strings.TrimRight(gatewayURL, "/") + "/metrics/job/" + url.PathEscape(job) + "/instance/" + url.PathEscape(instance)
+	text, err := MetricsText(context.Background())
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPut, pushurl, strings.NewReader(text))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	client := &http.Client{Timeout: 10 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("push metrics to %s: status %s", gatewayURL, response.Status)
+	}
+	return nil
+}
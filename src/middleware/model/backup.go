@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+const (
+	BACKUPLOCAL = "local-bgsave"  // 连接节点执行 bgsave
+	BACKUPCLOUD = "cloud-managed" // 调用腾讯云手动备份接口
+)
+
+// 备份策略，Target 为实例ID、节点ID或者 ip:port
+type BackupPolicy struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Region string `json:"region"` // cloud-managed 时必填
+	Target string `json:"target"`
+}
+
+// 不同类型备份统一的结果
+type BackupResult struct {
+	Policy   string        `json:"policy"`
+	Type     string        `json:"type"`
+	Target   string        `json:"target"`
+	Success  bool          `json:"success"`
+	TaskId   int64         `json:"task_id"` // cloud-managed 时为云上的任务ID
+	Start    time.Time     `json:"start"`
+	Duration float64       `json:"duration"`      // 秒
+	Rdb      *BgsaveResult `json:"rdb,omitempty"` // local-bgsave 时生成的rdb文件
+	Error    string        `json:"error"`
+}
+
+// bgsave 完成后的rdb文件，管理机看不到文件时（远程实例）SizeBytes 为 -1
+type BgsaveResult struct {
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"size_bytes"`
+	Duration  float64   `json:"duration"` // 秒，从触发到 LASTSAVE 更新
+	SavedAt   time.Time `json:"saved_at"`
+}
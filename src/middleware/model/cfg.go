@@ -13,8 +13,25 @@ var (
 	ALIAPIURL             = "ali_redis_api_url"                                                                                // 阿里PIURL
 	ALIACCESSKEYID        = "ali_accesskeyid"                                                                                  // 阿里accessKeyId
 	ALIALIACCESSKEYSECRET = "ali_accesskeysecret"                                                                              // 阿里accessKeySecret
-	BGSAVECOMMAND         = "redis_bgsave"                                                                                     // bgsave命令的别名
+	BGSAVECOMMAND         = "redis_bgsave"                                                                                     // bgsave命令的别名，按实例配置时key为 redis_bgsave:ip:port
+	AOFREWRITECOMMAND     = "redis_bgrewriteaof"                                                                               // bgrewriteaof命令的别名，按实例配置时key为 redis_bgrewriteaof:ip:port
+	SLOWLOGCOMMAND        = "redis_slowlog"                                                                                    // slowlog命令的别名，按实例配置时key为 redis_slowlog:ip:port
 	CLOUDREFRESH          = "cloud_refresh"                                                                                    // 云redis定时更新时间，使用cron格式
+	MAINTENANCEWINDOW     = "maintenance_window"                                                                               // 维护窗口，例如 mon-fri 02:00-04:00;sat,sun 00:00-06:00
+	MAINTENANCETZ         = "maintenance_timezone"                                                                             // 维护窗口的时区，例如 Asia/Shanghai
+	CONFIGEXCLUDE         = "config_exclude"                                                                                   // 导出实例参数时排除的参数，逗号分隔
+	HEALTHCHECK           = "health_check"                                                                                     // 实例健康检查时间，使用cron格式
+	EVICTIONSAMPLE        = "eviction_sample"                                                                                  // 驱逐采样时间，使用cron格式
+	INFOSNAPSHOT          = "info_snapshot"                                                                                    // 保存实例指标快照的时间，使用cron格式，用于查看趋势
+	KEYMASK               = "key_mask"                                                                                         // 输出key时需要脱敏的正则，分号分隔，按实例配置时key为 key_mask:实例ID
+	BACKUPPOLICY          = "backup_policy"                                                                                    // 备份策略，json数组，例如 [{"name":"a","type":"local-bgsave","target":"10.0.0.1:6379"}]
+	BACKUPSCHEDULE        = "backup_schedule"                                                                                  // 执行备份策略的时间，使用cron格式
+	INSTANCEENV           = "instance_env"                                                                                     // 已废弃，启动时合并到 instance_labels:实例ID 的 env 标签
+	INSTANCELABELS        = "instance_labels"                                                                                  // 实例的标签，按实例配置，key为 instance_labels:实例ID，例如 policy=ttl-required,env=prod，env=test 时允许 DEBUG RELOAD
+	TTLPOLICY             = "ttl_policy"                                                                                       // TTL检查策略，json，例如 {"default_ttl":"24h","pattern":"*","auto_fix":false}，按实例配置时key为 ttl_policy:实例ID
+	TTLPOLICYSCHEDULE     = "ttl_policy_schedule"                                                                              // 执行TTL检查策略的时间，使用cron格式
+	ALERTWEBHOOK          = "alert_webhook"                                                                                    // 告警和恢复时 POST json 的地址，为空只写错误日志
+	READWEIGHT            = "read_weight"                                                                                      // 从库的读权重，按节点配置，key为 read_weight:节点ID，默认为1，为0时不参与读
 	BOARDCODIS            = "board_codis"                                                                                      // 是否启动自建codis
 	BOARDTXREDIS          = "board_txredis"                                                                                    // 是否启动腾讯redis
 	BOARDALIREDIS         = "board_aliredis"                                                                                   // 是否启动阿里redis
@@ -30,7 +47,23 @@ func init() {
 	DefaultName[TXCOSACCESSKEYID] = "腾讯COS的ACCESSKEYID"
 	DefaultName[TXCOSENDPOINTPUB] = "腾讯COS的ENDPOINTPUB"
 	DefaultName[BGSAVECOMMAND] = "Redis命令bgsave别名"
+	DefaultName[AOFREWRITECOMMAND] = "Redis命令bgrewriteaof别名"
+	DefaultName[SLOWLOGCOMMAND] = "Redis命令slowlog别名"
 	DefaultName[CLOUDREFRESH] = "云redis定时更新时间"
+	DefaultName[MAINTENANCEWINDOW] = "维护窗口"
+	DefaultName[MAINTENANCETZ] = "维护窗口时区"
+	DefaultName[CONFIGEXCLUDE] = "导出实例参数时排除的参数"
+	DefaultName[HEALTHCHECK] = "实例健康检查时间"
+	DefaultName[EVICTIONSAMPLE] = "驱逐采样时间"
+	DefaultName[INFOSNAPSHOT] = "指标快照时间"
+	DefaultName[KEYMASK] = "key脱敏正则"
+	DefaultName[BACKUPPOLICY] = "备份策略"
+	DefaultName[BACKUPSCHEDULE] = "备份执行时间"
+	DefaultName[INSTANCELABELS] = "实例标签"
+	DefaultName[TTLPOLICY] = "TTL检查策略"
+	DefaultName[TTLPOLICYSCHEDULE] = "TTL检查执行时间"
+	DefaultName[READWEIGHT] = "从库读权重"
+	DefaultName[ALERTWEBHOOK] = "告警webhook地址"
 	DefaultName[ALIAPIURL] = "阿里REDIS的APIURL"
 	DefaultName[ALIACCESSKEYID] = "阿里accessKeyId"
 	DefaultName[ALIALIACCESSKEYSECRET] = "阿里accessKeySecret"
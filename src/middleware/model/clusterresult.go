@@ -14,3 +14,39 @@ type ClusterNodeTables struct {
 	SlotNumber int
 	Children   []*ClusterNodeTables
 }
+
+// cluster 健康检查结果
+type ClusterHealth struct {
+	ClusterState   string         `json:"cluster_state"`   // cluster info 里的 cluster_state
+	SlotsCovered   int            `json:"slots_covered"`   // 已经分配的slot个数
+	SlotGaps       []string       `json:"slot_gaps"`       // 没有分配的slot区间
+	FailNodes      []string       `json:"fail_nodes"`      // fail 状态的节点
+	PfailNodes     []string       `json:"pfail_nodes"`     // pfail 状态的节点
+	Masters        int            `json:"masters"`         // master个数
+	Replicas       int            `json:"replicas"`        // slave个数
+	MasterReplicas map[string]int `json:"master_replicas"` // 每个master的slave个数
+	Warnings       []string       `json:"warnings"`        // 异常说明
+	Healthy        bool           `json:"healthy"`
+}
+
+// cluster slot 迁移计划
+type RebalancePlan struct {
+	Masters  []RebalanceMaster `json:"masters"`
+	Moves    []SlotMove        `json:"moves"`
+	Balanced bool              `json:"balanced"` // 不需要迁移
+}
+
+type RebalanceMaster struct {
+	Address     string `json:"address"`
+	Slots       int    `json:"slots"`        // 当前slot个数
+	TargetSlots int    `json:"target_slots"` // 迁移后的slot个数
+	Keys        int64  `json:"keys"`
+}
+
+type SlotMove struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Slots         string `json:"slots"` // slot区间，例如 0-100,200
+	SlotCount     int    `json:"slot_count"`
+	EstimatedKeys int64  `json:"estimated_keys"` // 按源节点平均每个slot的key个数估算
+}
@@ -0,0 +1,43 @@
+package model
+
+// 单个实例的概要信息
+type InstanceSummary struct {
+	Type          string       `json:"type"` // txredis、aliredis、cluster
+	Id            string       `json:"id"`
+	Name          string       `json:"name"`
+	Addr          string       `json:"addr"`
+	Reachable     bool         `json:"reachable"`
+	Status        string       `json:"status"` // ok、unreachable、auth_required、auth_failure
+	Error         string       `json:"error"`
+	Breaker       string       `json:"breaker"` // 熔断器状态 closed、open、half_open
+	Role          string       `json:"role"`
+	UsedMemory    int64        `json:"used_memory"`
+	MaxMemory     int64        `json:"max_memory"`
+	MemoryPercent float64      `json:"memory_percent"` // maxmemory为0时为0
+	OpsPerSec     int64        `json:"ops_per_sec"`
+	HitRatio      float64      `json:"hit_ratio"`
+	ReplLag       int64        `json:"repl_lag"`      // 主从延迟，单位秒
+	LastSaveAge   int64        `json:"last_save_age"` // 距离最后一次保存的时间，单位秒
+	Version       string       `json:"version"`
+	Capabilities  Capabilities `json:"capabilities"`
+	InFlightOps   int          `json:"in_flight_ops"` // 正在执行的管理操作个数
+	Clients       int64        `json:"connected_clients"`
+	Keys          int64        `json:"keys"` // 所有db的key个数
+}
+
+// 根据 redis 版本判断支持的功能
+type Capabilities struct {
+	Unlink      bool `json:"unlink"`       // 4.0
+	MemoryStats bool `json:"memory_stats"` // 4.0
+	ScanType    bool `json:"scan_type"`    // 6.0
+	Copy        bool `json:"copy"`         // 6.2
+	Function    bool `json:"function"`     // 7.0
+}
+
+// 所有实例的概要信息
+type FleetSummary struct {
+	Total       int               `json:"total"`
+	Reachable   int               `json:"reachable"`
+	Unreachable int               `json:"unreachable"`
+	Instances   []InstanceSummary `json:"instances"`
+}
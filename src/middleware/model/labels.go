@@ -0,0 +1,64 @@
+package model
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// instance_env 已废弃，读取配置时合并到 instance_labels
+func init() {
+	RegisterMigration(migrateInstanceEnv)
+}
+
+// 解析实例标签，格式为 k=v,k=v
+func ParseLabels(value string) map[string]string {
+	labels := make(map[string]string)
+	for _, v := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(v), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			continue
+		}
+		labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return labels
+}
+
+// 按key排序输出为 k=v,k=v
+func FormatLabels(labels map[string]string) string {
+	var keylist []string
+	for k := range labels {
+		keylist = append(keylist, k)
+	}
+	sort.Strings(keylist)
+	var result []string
+	for _, k := range keylist {
+		result = append(result, k+"="+labels[k])
+	}
+	return strings.Join(result, ",")
+}
+
+// instance_env:实例ID 合并到 instance_labels:实例ID 的 env 标签，标签里已经有 env 时保留标签里的值
+func migrateInstanceEnv(result map[string]string) {
+	var keylist []string
+	for key := range result {
+		if strings.HasPrefix(key, INSTANCEENV+":") {
+			keylist = append(keylist, key)
+		}
+	}
+	sort.Strings(keylist)
+	for _, key := range keylist {
+		value := result[key]
+		delete(result, key)
+		labelkey := INSTANCELABELS + ":" + strings.TrimPrefix(key, INSTANCEENV+":")
+		labels := ParseLabels(result[labelkey])
+		if _, ok := labels["env"]; ok || value == "" {
+			logger.Warn("配置 ", key, " 已废弃，", labelkey, " 已经有 env 标签，忽略 ", key)
+			continue
+		}
+		logger.Warn("配置 ", key, " 已废弃，合并到 ", labelkey, " 的 env 标签")
+		labels["env"] = value
+		result[labelkey] = FormatLabels(labels)
+	}
+}
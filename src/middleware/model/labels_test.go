@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+)
+
+// instance_env 合并到 instance_labels 的 env 标签，标签里已经有 env 时保留标签里的值
+func TestMigrateInstanceEnv(t *testing.T) {
+	result := Migrate(map[string]string{
+		INSTANCEENV + ":a":    "test",
+		INSTANCELABELS + ":a": "policy=ttl-required",
+		INSTANCEENV + ":b":    "test",
+		INSTANCELABELS + ":b": "env=prod",
+		INSTANCEENV + ":c":    "dev",
+	})
+	want := map[string]string{
+		INSTANCELABELS + ":a": "env=test,policy=ttl-required",
+		INSTANCELABELS + ":b": "env=prod",
+		INSTANCELABELS + ":c": "env=dev",
+	}
+	if len(result) != len(want) {
+		t.Fatalf("got %v, want %v", result, want)
+	}
+	for key, value := range want {
+		if result[key] != value {
+			t.Errorf("%s = %q, want %q", key, result[key], value)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import "strings"
+
+// 差异里密钥类配置的值用这个代替
+const Redacted = "******"
+
+// 配置差异，Old 为空表示新增，New 为空表示删除
+type ChangePair struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// key 里包含这些词的配置认为是密钥，输出差异时隐藏
+var secretWords = []string{"secret", "password", "accesskey", "token", "pw"}
+
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, v := range secretWords {
+		if strings.Contains(key, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// 叠加配置，override 里的值覆盖 base，两边的旧key都会先按 KeyAliases 改成新key
+func Merge(base, override map[string]string) map[string]string {
+	result := Migrate(base)
+	for k, v := range Migrate(override) {
+		result[k] = v
+	}
+	return result
+}
+
+// 比较两份配置，返回值不同的key，密钥类配置的值隐藏
+func Diff(a, b map[string]string) map[string]ChangePair {
+	result := make(map[string]ChangePair)
+	for k, old := range a {
+		if v, ok := b[k]; !ok || v != old {
+			result[k] = redactPair(k, ChangePair{Old: old, New: b[k]})
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			result[k] = redactPair(k, ChangePair{New: v})
+		}
+	}
+	return result
+}
+
+func redactPair(key string, pair ChangePair) ChangePair {
+	if !IsSecretKey(key) {
+		return pair
+	}
+	if pair.Old != "" {
+		pair.Old = Redacted
+	}
+	if pair.New != "" {
+		pair.New = Redacted
+	}
+	return pair
+}
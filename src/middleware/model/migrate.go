@@ -0,0 +1,59 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 改名或废弃的配置key，旧key -> 新key
+// 以后改名时在这里加一行，例如 "tx_region": "tx_regions"
+var KeyAliases = map[string]string{}
+
+// 改名之外的迁移，例如把按实例配置的旧key合并到新key的值里，在改名之后按注册顺序执行
+var migrations []func(result map[string]string)
+
+// 注册一个迁移，只在 init 里调用
+func RegisterMigration(fn func(result map[string]string)) {
+	migrations = append(migrations, fn)
+}
+
+// 把旧的配置key改成新的key，新key已经有值时保留新key的值，旧key的值丢弃
+func Migrate(raw map[string]string) map[string]string {
+	result := make(map[string]string, len(raw))
+	var deprecated []string
+	for key, value := range raw {
+		if _, ok := KeyAliases[key]; ok {
+			deprecated = append(deprecated, key)
+			continue
+		}
+		result[key] = value
+	}
+	sort.Strings(deprecated)
+	for _, key := range deprecated {
+		newkey := resolveAlias(key)
+		if _, ok := result[newkey]; ok {
+			logger.Warn("配置 ", key, " 已废弃，", newkey, " 已经配置，忽略 ", key)
+			continue
+		}
+		logger.Warn("配置 ", key, " 已废弃，请改为 ", newkey)
+		result[newkey] = raw[key]
+	}
+	for _, fn := range migrations {
+		fn(result)
+	}
+	return result
+}
+
+// 连续改名时找到最终的key，有环时停在环上
+func resolveAlias(key string) string {
+	seen := map[string]bool{key: true}
+	for {
+		newkey, ok := KeyAliases[key]
+		if !ok || seen[newkey] {
+			return key
+		}
+		seen[newkey] = true
+		key = newkey
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+)
+
+// 测试时临时注册别名，结束后恢复
+func withAliases(t *testing.T, aliases map[string]string) {
+	previous := KeyAliases
+	KeyAliases = aliases
+	t.Cleanup(func() {
+		KeyAliases = previous
+	})
+}
+
+func TestMigrateRenamedKey(t *testing.T) {
+	withAliases(t, map[string]string{"tx_region": "tx_regions"})
+	result := Migrate(map[string]string{"tx_region": "ap-guangzhou", "tx_secretid": "id"})
+	if _, ok := result["tx_region"]; ok {
+		t.Errorf("deprecated key should be removed, got %v", result)
+	}
+	if result["tx_regions"] != "ap-guangzhou" {
+		t.Errorf("tx_regions = %q, want ap-guangzhou", result["tx_regions"])
+	}
+	if result["tx_secretid"] != "id" {
+		t.Errorf("unrelated key changed, got %v", result)
+	}
+}
+
+// 新key已经有值时保留新key的值
+func TestMigrateKeepsNewKey(t *testing.T) {
+	withAliases(t, map[string]string{"tx_region": "tx_regions"})
+	result := Migrate(map[string]string{"tx_region": "old", "tx_regions": "new"})
+	if len(result) != 1 || result["tx_regions"] != "new" {
+		t.Errorf("got %v, want only tx_regions=new", result)
+	}
+}
+
+// 连续改名时迁移到最终的key，有环时不会死循环
+func TestMigrateChainedRename(t *testing.T) {
+	withAliases(t, map[string]string{"a": "b", "b": "c", "x": "y", "y": "x"})
+	result := Migrate(map[string]string{"a": "1", "x": "2"})
+	if result["c"] != "1" {
+		t.Errorf("c = %q, want 1", result["c"])
+	}
+	if _, ok := result["a"]; ok {
+		t.Errorf("a should be migrated, got %v", result)
+	}
+	if len(result) != 2 {
+		t.Errorf("got %v, want 2 keys", result)
+	}
+}
@@ -12,6 +12,7 @@ var (
 	PATHUSER      = "/redis-manager/user/v1"
 	PATHRULE      = "/redis-manager/rule/v1"
 	PATHAUTH      = "/redis-manager/auth/v1"
+	PATHLOGS      = "/redis-manager/logs/v1"
 	DefaultMethod = make(map[string]string)
 	METHODGET     = "GET"
 	METHODPOST    = "POST"
@@ -30,6 +31,7 @@ func init() {
 	DefaultPath[PATHUSER+"/*"] = "用户管理/用户列表页面权限"
 	DefaultPath[PATHRULE+"/*"] = "用户管理/权限管理页面权限"
 	DefaultPath[PATHAUTH+"/*"] = "用户修改密码权限"
+	DefaultPath[PATHLOGS+"/*"] = "日志查看权限"
 	DefaultMethod[METHODGET] = "读权限"
 	DefaultMethod[METHODPOST] = "写权限"
 	DefaultMethod[METHODDELETE] = "删除权限"
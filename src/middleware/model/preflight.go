@@ -0,0 +1,16 @@
+package model
+
+// 单项检查结果
+type PreflightCheck struct {
+	Name   string `json:"name"` // redis、manager_redis、txcloud
+	Target string `json:"target"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error"`
+	Hint   string `json:"hint"` // 失败时的处理建议
+}
+
+// 启动前的连通性检查
+type PreflightReport struct {
+	Passed bool             `json:"passed"`
+	Checks []PreflightCheck `json:"checks"`
+}
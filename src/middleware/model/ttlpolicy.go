@@ -0,0 +1,23 @@
+package model
+
+// 实例标签里 policy=ttl-required 的实例执行 TTL 检查
+const TTLREQUIRED = "ttl-required"
+
+// TTL 检查策略，AutoFix 为 false 时只告警不修改
+type TTLPolicy struct {
+	DefaultTTL string `json:"default_ttl"` // 自动补上的过期时间，例如 24h
+	Pattern    string `json:"pattern"`     // 自动修复时只处理匹配的key，默认 *
+	Sample     int    `json:"sample"`      // 采样的key个数，默认10000
+	AutoFix    bool   `json:"auto_fix"`
+}
+
+type TTLPolicyResult struct {
+	Instance  string `json:"instance"`
+	Addr      string `json:"addr"`
+	Sampled   int    `json:"sampled"`
+	NoTtl     int    `json:"no_ttl"`
+	Estimated int64  `json:"estimated"` // 按 dbsize 估算的没有过期时间的key个数
+	DryRun    bool   `json:"dry_run"`
+	Fixed     int    `json:"fixed"` // 补上过期时间的key个数，dry run 时为需要补的个数
+	Error     string `json:"error"`
+}
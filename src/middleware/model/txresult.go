@@ -137,3 +137,45 @@ type TxRedisSlowKeyResponseInstanceSlowlogDetail struct {
 	ExecuteTime string `json:"ExecuteTime"`
 	Node        string `json:"Node"`
 }
+
+// tx redis 实例参数
+type TxParams struct {
+	Response TxParamsResponse `json:"Response"`
+}
+
+type TxParamsResponse struct {
+	TotalCount           int       `json:"TotalCount"`
+	InstanceEnumParam    []TxParam `json:"InstanceEnumParam"`
+	InstanceIntegerParam []TxParam `json:"InstanceIntegerParam"`
+	InstanceTextParam    []TxParam `json:"InstanceTextParam"`
+	InstanceMultiParam   []TxParam `json:"InstanceMultiParam"`
+	RequestId            string    `json:"RequestId"`
+}
+
+type TxParam struct {
+	ParamName    string `json:"ParamName"`
+	ValueType    string `json:"ValueType"`
+	NeedRestart  string `json:"NeedRestart"`
+	DefaultValue string `json:"DefaultValue"`
+	CurrentValue string `json:"CurrentValue"`
+	Tips         string `json:"Tips"`
+}
+
+// tx 云监控数据
+type TxMonitorData struct {
+	Response TxMonitorDataResponse `json:"Response"`
+}
+
+type TxMonitorDataResponse struct {
+	Period     int                  `json:"Period"`
+	MetricName string               `json:"MetricName"`
+	DataPoints []TxMonitorDataPoint `json:"DataPoints"`
+	StartTime  string               `json:"StartTime"`
+	EndTime    string               `json:"EndTime"`
+	RequestId  string               `json:"RequestId"`
+}
+
+type TxMonitorDataPoint struct {
+	Timestamps []int64   `json:"Timestamps"`
+	Values     []float64 `json:"Values"`
+}
@@ -75,5 +75,6 @@ func Migrate() {
 		logger.Info("Mysql start create data table Rconfig migrate data schemas...")
 		DB.AutoMigrate(&Rconfig{})
 	}
+	DB.MigrateCfg()
 	logger.Info("Mysql auto check data table done.")
 }
@@ -1,7 +1,12 @@
 package mysql
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/secret"
 )
 
 // add cfg
@@ -61,9 +66,52 @@ func (m *MySQL) GetOneCfg(key string) Rconfig {
 	return cfg
 }
 
-// get one cfg value
+// get one cfg value，值为 vault:path#field 时从 Vault 读取，错误由 secret 限频上报
 func (m *MySQL) GetOneCfgValue(key string) string {
 	var cfg Rconfig
 	m.Where("`key` = ?", key).First(&cfg)
-	return cfg.Value
+	value, err := secret.Resolve(cfg.Value)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// 启动时检查数据库配置里所有 vault: 引用都能读取到，有读取失败的返回错误
+func (m *MySQL) CheckCfgSecrets() error {
+	var failed []string
+	for _, v := range m.GetAllCfg() {
+		if !secret.IsRef(v.Value) {
+			continue
+		}
+		if _, err := secret.Resolve(v.Value); err != nil {
+			failed = append(failed, v.Key+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("resolve cfg secrets failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// 把数据库里废弃的配置key改成新的key
+func (m *MySQL) MigrateCfg() {
+	raw := make(map[string]string)
+	for _, v := range m.GetAllCfg() {
+		raw[v.Key] = v.Value
+	}
+	migrated := model.Migrate(raw)
+	for key := range raw {
+		if _, ok := migrated[key]; !ok {
+			m.DelCfg(key)
+		}
+	}
+	for key, value := range migrated {
+		old, ok := raw[key]
+		if !ok {
+			m.AddCfg(model.DefaultName[key], key, value)
+		} else if old != value {
+			m.UpdateCfg(key, value)
+		}
+	}
 }
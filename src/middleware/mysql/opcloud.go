@@ -47,6 +47,13 @@ func (m *MySQL) GetCloudAddress(cloud, instanceid string) (string, string, int)
 	m.Where("instance_id = ? AND cloud = ?", instanceid, cloud).First(&cloudinfo)
 	return cloudinfo.Password, cloudinfo.PrivateIp, cloudinfo.Port
 }
+func (m *MySQL) GetCloudInfo(cloud, instanceid string) (CloudInfo, bool) {
+	var cloudinfo CloudInfo
+	if err := m.Where("instance_id = ? AND cloud = ?", instanceid, cloud).First(&cloudinfo).Error; err != nil {
+		return cloudinfo, false
+	}
+	return cloudinfo, true
+}
 
 // tx
 func (m *MySQL) UppdateTxCloudRedis(cloud string, redisinfo model.TxLResponseInstanceSet) bool {
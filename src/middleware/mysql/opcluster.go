@@ -62,6 +62,18 @@ func (m *MySQL) GetClusterNodeSlaverAddress(nodeid string) string {
 	m.Where("master_id = ?", nodeid).First(&nodes)
 	return nodes.Ip + ":" + nodes.Port
 }
+func (m *MySQL) GetClusterNodeInfo(nodeid string) (ClusterNode, bool) {
+	var node ClusterNode
+	if err := m.Where("node_id = ?", nodeid).First(&node).Error; err != nil {
+		return node, false
+	}
+	return node, true
+}
+func (m *MySQL) GetClusterNodeSlaves(nodeid string) []ClusterNode {
+	var nodes []ClusterNode
+	m.Model(nodes).Where("master_id = ?", nodeid).Find(&nodes)
+	return nodes
+}
 func (m *MySQL) AddClusterNode(nodeid, ip, port, flags, masterid, linkstate, slotrange string, clusterid, slotnumber int) (int, bool) {
 	addnode := &ClusterNode{
 		CluserId:   clusterid,
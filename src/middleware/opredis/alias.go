@@ -0,0 +1,26 @@
+package opredis
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+)
+
+var ErrEmptyCommandAlias = errors.New("command alias is empty")
+
+// 获取被 rename-command 改名后的命令，实例的配置 key 为 "名称:ip:port"，没有时使用全局配置
+func CommandAlias(name, serverip string) (string, error) {
+	alias := ""
+	if serverip != "" {
+		alias = mysql.DB.GetOneCfgValue(name + ":" + EndpointAddr(serverip))
+	}
+	if alias == "" {
+		alias = mysql.DB.GetOneCfgValue(name)
+	}
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return "", ErrEmptyCommandAlias
+	}
+	return alias, nil
+}
@@ -0,0 +1,104 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+var (
+	ErrAofRewriteInProgress = errors.New("aof rewrite already in progress")
+	ErrAofRewriteFailed     = errors.New("aof rewrite failed")
+	ErrAofDisabled          = errors.New("aof is not enabled")
+)
+
+var (
+	aofLock    sync.Mutex
+	aofRunning = make(map[string]bool)
+)
+
+// 执行 BGREWRITEAOF 并等待完成，命令被改名时使用配置的别名
+// 同一个实例同时只执行一个rewrite，使用单独的链接轮询，不受全局链接切换的影响
+func RewriteAOF(ctx context.Context, serverip, pw string) error {
+	aofLock.Lock()
+	if aofRunning[serverip] {
+		aofLock.Unlock()
+		return ErrAofRewriteInProgress
+	}
+	aofRunning[serverip] = true
+	aofLock.Unlock()
+	defer func() {
+		aofLock.Lock()
+		delete(aofRunning, serverip)
+		aofLock.Unlock()
+	}()
+
+	if IsReadOnlyContext(ctx) {
+		return ErrReadOnlyConnection
+	}
+	rd, err := newCallerClient(ctx, FleetTarget{Addr: serverip, Password: pw})
+	if err != nil {
+		return err
+	}
+	defer ReleaseClient(rd)
+	info, ok := persistenceInfo(ctx, rd)
+	if !ok {
+		return ErrAofRewriteFailed
+	}
+	if info["aof_enabled"] != "1" {
+		return ErrAofDisabled
+	}
+	if info["aof_rewrite_in_progress"] == "1" {
+		return ErrAofRewriteInProgress
+	}
+	_, err = rd.BgRewriteAOF(ctx).Result()
+	if err != nil {
+		logger.Debug("ip: "+serverip+" 执行redis的 BGREWRITEAOF 操作失败：", err)
+		yourewrite, aliaserr := CommandAlias(model.AOFREWRITECOMMAND, serverip)
+		if aliaserr != nil {
+			return err
+		}
+		_, err = rd.Do(ctx, yourewrite).Result()
+		if err != nil {
+			logger.Error("ip: "+serverip+" 执行redis的 "+yourewrite+" 操作失败：", err)
+			return err
+		}
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		info, ok := persistenceInfo(ctx, rd)
+		if !ok {
+			continue
+		}
+		if info["aof_rewrite_in_progress"] == "1" || info["aof_rewrite_scheduled"] == "1" {
+			continue
+		}
+		if info["aof_last_bgrewrite_status"] != "ok" {
+			logger.Error("ip: "+serverip+" BGREWRITEAOF 执行失败，状态：", info["aof_last_bgrewrite_status"])
+			return ErrAofRewriteFailed
+		}
+		logger.Info("ip: " + serverip + " BGREWRITEAOF 执行成功")
+		return nil
+	}
+}
+
+// 通过指定的链接获取 INFO persistence
+func persistenceInfo(ctx context.Context, rd *redis.Client) (map[string]string, bool) {
+	val, err := rd.Info(ctx, "persistence").Result()
+	if err != nil {
+		logger.Error("Redis Info persistence Error: ", err)
+		return nil, false
+	}
+	return ParseInfo(val), true
+}
@@ -0,0 +1,32 @@
+package opredis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrAuthRequired = errors.New("redis authentication required")
+	ErrAuthFailed   = errors.New("redis authentication failed")
+)
+
+// 把 NOAUTH 转成 ErrAuthRequired，WRONGPASS/NOPERM 转成 ErrAuthFailed，其他错误原样返回
+func MapAuthError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "NOAUTH"):
+		return fmt.Errorf("%w: %s", ErrAuthRequired, msg)
+	case strings.HasPrefix(msg, "WRONGPASS"), strings.HasPrefix(msg, "NOPERM"), strings.Contains(msg, "invalid password"):
+		return fmt.Errorf("%w: %s", ErrAuthFailed, msg)
+	}
+	return err
+}
+
+// 是否是认证相关的错误
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrAuthRequired) || errors.Is(err, ErrAuthFailed)
+}
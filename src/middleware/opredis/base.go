@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/iguidao/redis-manager/src/middleware/logger"
-	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/model"
 
 	"github.com/go-redis/redis/v9"
 )
@@ -63,6 +63,10 @@ func GetScanKey(cursor uint64, allnum int64) ([]string, uint64, bool) {
 }
 
 func BgsaveKey() (string, bool) {
+	if err := RD.CheckCommand("bgsave"); err != nil {
+		logger.Error("Redis Bgsave Error: ", err)
+		return "Execution failed", false
+	}
 
 	val, err := RD.BgSave(ctx).Result()
 	if err != nil {
@@ -82,6 +86,10 @@ func DebugKey(keyname string) (string, bool) {
 }
 
 func DelKey(keyname string) (int64, bool) {
+	if err := RD.CheckCommand("del"); err != nil {
+		logger.Error("Redis Del key: ", keyname, " Error: ", err)
+		return -1, false
+	}
 	val, err := RD.Del(ctx, keyname).Result()
 	if err != nil {
 		logger.Error("Redis Del key: ", keyname, " Error: ", err)
@@ -91,6 +99,10 @@ func DelKey(keyname string) (int64, bool) {
 }
 
 func ExpireKey(keyname string, keytime int) bool {
+	if err := RD.CheckCommand("expire"); err != nil {
+		logger.Error("Redis Expire key: ", keyname, " Error: ", err)
+		return false
+	}
 	val, err := RD.Expire(ctx, keyname, time.Duration(keytime)*time.Second).Result()
 	if err != nil {
 		logger.Error("Redis Expire key: ", keyname, " Error: ", err)
@@ -119,6 +131,10 @@ func SizeStringKey(keyname string) (int64, bool) {
 }
 
 func SetStringKey(keyname, keyvalue string) (string, bool) {
+	if err := RD.CheckCommand("set"); err != nil {
+		logger.Error("Redis Set key: ", keyname, " Error: ", err)
+		return "", false
+	}
 	val, err := RD.Set(ctx, keyname, keyvalue, 0).Result()
 	if err != nil {
 		logger.Error("Redis Get key: ", keyname, " Error: ", err)
@@ -128,6 +144,10 @@ func SetStringKey(keyname, keyvalue string) (string, bool) {
 }
 
 func IncrStringKey(keyname string) (int64, bool) {
+	if err := RD.CheckCommand("incr"); err != nil {
+		logger.Error("Redis Incr key: ", keyname, " Error: ", err)
+		return 0, false
+	}
 	val, err := RD.Incr(ctx, keyname).Result()
 	if err != nil {
 		logger.Error("Redis Incr key: ", keyname, " Error: ", err)
@@ -229,6 +249,10 @@ func SizeZsetKey(keyname string) (int64, bool) {
 
 // lock
 func LockOp(lockkeyname string, timekey time.Duration) bool {
+	if err := RD.CheckCommand("setnx"); err != nil {
+		logger.Error("lock error: ", err)
+		return false
+	}
 	var lockKey = lockkeyname
 	// lock
 	resp := RD.SetNX(ctx, lockKey, 1, timekey)
@@ -242,6 +266,10 @@ func LockOp(lockkeyname string, timekey time.Duration) bool {
 }
 
 func UnLockOp(lockkeyname string) bool {
+	if err := RD.CheckCommand("del"); err != nil {
+		logger.Error("unlock failed error: ", err)
+		return false
+	}
 	var lockKey = lockkeyname
 	delResp := RD.Del(ctx, lockKey)
 	unlockSuccess, err := delResp.Result()
@@ -257,11 +285,24 @@ func UnLockOp(lockkeyname string) bool {
 
 // SAVE
 func RedisSave(serverip string) bool {
-	_, err := RD.BgSave(ctx).Result()
+	if err := RD.CheckCommand("bgsave"); err != nil {
+		logger.Error("ip: "+serverip+" 执行redis的 BGSAVE 操作失败：", err)
+		return false
+	}
+	return redisSave(ctx, RD.Client, serverip)
+}
+
+// 通过指定的链接执行 BGSAVE，命令被改名时使用配置的别名
+func redisSave(ctx context.Context, rd *redis.Client, serverip string) bool {
+	_, err := rd.BgSave(ctx).Result()
 	if err != nil {
 		logger.Debug("ip: "+serverip+" 执行redis的 BGSAVE 操作失败：", err)
-		youbgsave := mysql.DB.GetOneCfgValue("redis_bgsave")
-		_, err := RD.Do(ctx, youbgsave).Result()
+		youbgsave, aliaserr := CommandAlias(model.BGSAVECOMMAND, serverip)
+		if aliaserr != nil {
+			logger.Error("ip: "+serverip+" 没有配置 bgsave 的别名：", aliaserr)
+			return false
+		}
+		_, err := rd.Do(ctx, youbgsave).Result()
 		if err != nil {
 			logger.Debug("ip: "+serverip+" 执行redis的 "+youbgsave+" 操作失败：", err)
 			return false
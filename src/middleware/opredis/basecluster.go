@@ -1,8 +1,11 @@
 package opredis
 
 import (
+	"context"
 	"strings"
+	"sync"
 
+	"github.com/go-redis/redis/v9"
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 )
 
@@ -74,6 +77,10 @@ func CGetZsetKey(keyname string) ([]string, bool) {
 	return val, true
 }
 func CDelKey(keyname string) (int64, bool) {
+	if err := CRD.CheckCommand("del"); err != nil {
+		logger.Error("Redis Del key: ", keyname, " Error: ", err)
+		return -1, false
+	}
 	val, err := CRD.Del(ctx, keyname).Result()
 	if err != nil {
 		logger.Error("Redis Del key: ", keyname, " Error: ", err)
@@ -86,3 +93,33 @@ func CGetClusterNode() []string {
 	nodeinfo := strings.Split(clusternode.Val(), "\n")
 	return nodeinfo
 }
+
+func CGetClusterInfo() (string, bool) {
+	val, err := CRD.ClusterInfo(ctx).Result()
+	if err != nil {
+		logger.Error("Redis Cluster Info Error: ", err)
+		return "", false
+	}
+	return val, true
+}
+
+// 每个master的key个数，key为master地址
+func CMasterKeyCount() (map[string]int64, bool) {
+	var lock sync.Mutex
+	result := make(map[string]int64)
+	err := CRD.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		size, err := client.DBSize(ctx).Result()
+		if err != nil {
+			return err
+		}
+		lock.Lock()
+		result[client.Options().Addr] = size
+		lock.Unlock()
+		return nil
+	})
+	if err != nil {
+		logger.Error("Redis Cluster Dbsize Error: ", err)
+		return result, false
+	}
+	return result, true
+}
@@ -0,0 +1,146 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/tools"
+)
+
+var (
+	ErrBatchOpNotAllowed    = errors.New("op is not allowed in batch")
+	ErrBatchUnknownInstance = errors.New("unknown instance")
+)
+
+// 批量请求里同时执行的个数
+const batchConcurrency = 10
+
+// 可以批量执行的只读操作，都使用单独的链接，不影响全局的 RD
+var BatchOps = []string{"summary", "ping", "info", "eviction", "breaker"}
+
+type BatchItem struct {
+	Op       string            `json:"op"`
+	Instance string            `json:"instance"` // * 表示所有实例
+	Role     string            `json:"role"`     // 为空、master 或 replica，角色不符合的实例跳过
+	Args     map[string]string `json:"args"`
+}
+
+type BatchResult struct {
+	Op       string      `json:"op"`
+	Instance string      `json:"instance"`
+	Data     interface{} `json:"data"`
+	Error    string      `json:"error"`
+	Skipped  string      `json:"skipped,omitempty"` // 角色不符合时跳过的原因
+}
+
+// 并发执行批量请求，结果和请求的顺序一致，deadline 为整个批次的超时时间
+func Batch(ctx context.Context, items []BatchItem, deadline time.Duration) []BatchResult {
+	batchctx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+	targets := make(map[string]FleetTarget)
+	fleet := FleetTargets()
+	for _, v := range fleet {
+		targets[v.Id] = v
+	}
+	items = expandBatchItems(items, fleet)
+	result := make([]BatchResult, len(items))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, batchConcurrency)
+	for i, item := range items {
+		i, item := i, item
+		result[i] = BatchResult{Op: item.Op, Instance: item.Instance}
+		target, ok := targets[item.Instance]
+		filter, ferr := ParseRoleFilter(item.Role)
+		switch {
+		case !tools.CheckStringInArray(item.Op, BatchOps):
+			result[i].Error = ErrBatchOpNotAllowed.Error()
+			continue
+		case !ok:
+			result[i].Error = ErrBatchUnknownInstance.Error()
+			continue
+		case ferr != nil:
+			result[i].Error = ferr.Error()
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-batchctx.Done():
+			result[i].Error = batchctx.Err().Error()
+			continue
+		}
+		wg.Add(1)
+		tools.SafeGo("batch", func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			data, err := execWithTimeout(batchctx, target, deadline, func(ctx context.Context, target FleetTarget) (interface{}, error) {
+				if err := checkRole(ctx, target, filter); err != nil {
+					return nil, err
+				}
+				return batchOne(ctx, item, target)
+			})
+			result[i].Data = data
+			var skip *RoleSkip
+			if errors.As(err, &skip) {
+				result[i].Skipped = skip.Error()
+			} else if err != nil {
+				result[i].Error = err.Error()
+			}
+		})
+	}
+	wg.Wait()
+	return result
+}
+
+// instance 为 * 的请求展开为每个实例一个
+func expandBatchItems(items []BatchItem, fleet []FleetTarget) []BatchItem {
+	var result []BatchItem
+	for _, item := range items {
+		if item.Instance != "*" {
+			result = append(result, item)
+			continue
+		}
+		for _, v := range fleet {
+			expanded := item
+			expanded.Instance = v.Id
+			result = append(result, expanded)
+		}
+	}
+	return result
+}
+
+func batchOne(ctx context.Context, item BatchItem, target FleetTarget) (interface{}, error) {
+	switch item.Op {
+	case "summary":
+		return InstanceSummary(ctx, target), nil
+	case "ping":
+		return nil, ProbeTarget(ctx, target)
+	case "info":
+		rd, err := newTargetClient(target)
+		if err != nil {
+			return nil, err
+		}
+		defer ReleaseClient(rd)
+		var section []string
+		if item.Args["section"] != "" {
+			section = append(section, item.Args["section"])
+		}
+		val, err := rd.Info(ctx, section...).Result()
+		if err != nil {
+			return nil, MapAuthError(err)
+		}
+		return ParseInfo(val), nil
+	case "eviction":
+		expired, evicted, err := EvictionRate(target.Id)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]float64{"expired_per_sec": expired, "evicted_per_sec": evicted}, nil
+	case "breaker":
+		return GetBreaker(EndpointAddr(target.Addr)).State(), nil
+	}
+	return nil, ErrBatchOpNotAllowed
+}
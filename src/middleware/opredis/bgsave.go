@@ -0,0 +1,186 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+var (
+	ErrBgsaveInProgress  = errors.New("bgsave already in progress")
+	ErrBgsaveTooFrequent = errors.New("bgsave interval too short")
+	ErrBgsaveFailed      = errors.New("bgsave execution failed")
+	ErrWaitSaveTimeout   = errors.New("wait for save timeout")
+)
+
+var (
+	bgsaveLock    sync.Mutex
+	bgsaveRunning = make(map[string]bool)
+	bgsaveLast    = make(map[string]time.Time)
+)
+
+// 检查是否正在执行bgsave
+func BgsaveInProgress() (bool, bool) {
+	return bgsaveInProgress(ctx, RD.Client)
+}
+
+func bgsaveInProgress(ctx context.Context, rd *redis.Client) (bool, bool) {
+	info, ok := persistenceInfo(ctx, rd)
+	if !ok {
+		return false, false
+	}
+	return info["rdb_bgsave_in_progress"] == "1", true
+}
+
+// 同一个实例同时只执行一个bgsave，并且两次bgsave之间要间隔 bgsaveinterval 秒
+func SafeBgsave(serverip string) error {
+	if err := RD.CheckCommand("bgsave"); err != nil {
+		return err
+	}
+	return safeBgsave(ctx, RD.Client, serverip)
+}
+
+// 和 SafeBgsave 一样，通过指定的链接执行
+func safeBgsave(ctx context.Context, rd *redis.Client, serverip string) error {
+	bgsaveLock.Lock()
+	if bgsaveRunning[serverip] {
+		bgsaveLock.Unlock()
+		return ErrBgsaveInProgress
+	}
+	interval := time.Duration(cfg.Get_Info_Int("bgsaveinterval")) * time.Second
+	if last, ok := bgsaveLast[serverip]; ok && time.Since(last) < interval {
+		bgsaveLock.Unlock()
+		logger.Warn("ip: "+serverip+" bgsave 间隔太短，上次执行时间：", last.Format("2006-01-02 15:04:05"))
+		return ErrBgsaveTooFrequent
+	}
+	bgsaveRunning[serverip] = true
+	bgsaveLock.Unlock()
+	defer func() {
+		bgsaveLock.Lock()
+		delete(bgsaveRunning, serverip)
+		bgsaveLock.Unlock()
+	}()
+
+	running, ok := bgsaveInProgress(ctx, rd)
+	if ok && running {
+		logger.Warn("ip: " + serverip + " 正在执行bgsave，跳过")
+		return ErrBgsaveInProgress
+	}
+	if !redisSave(ctx, rd, serverip) {
+		return ErrBgsaveFailed
+	}
+	bgsaveLock.Lock()
+	bgsaveLast[serverip] = time.Now()
+	bgsaveLock.Unlock()
+	logger.Info("ip: " + serverip + " bgsave 执行成功，最小间隔 " + strconv.Itoa(int(interval.Seconds())) + " 秒")
+	return nil
+}
+
+// 获取最后一次保存成功的时间
+func LastSave() (time.Time, bool) {
+	return lastSave(ctx, RD.Client)
+}
+
+func lastSave(ctx context.Context, rd *redis.Client) (time.Time, bool) {
+	val, err := rd.LastSave(ctx).Result()
+	if err != nil {
+		logger.Error("Redis Lastsave Error: ", err)
+		return time.Time{}, false
+	}
+	return time.Unix(val, 0), true
+}
+
+// 通过 rd 轮询 persistence 信息直到bgsave结束，previous 为触发保存前 LastSave 的结果
+// LASTSAVE 只精确到秒，所以和上一次的值比较是否变化，并且要求 rdb_bgsave_in_progress 已经为0
+// bgsave 结束但 rdb_last_bgsave_status 不是 ok 时返回 ErrBgsaveFailed，超时的时候错误信息里带上最后一次保存的时间
+func WaitForSave(ctx context.Context, rd *redis.Client, previous time.Time, timeout time.Duration) (time.Time, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var lastsave time.Time
+	for {
+		info, ok := persistenceInfo(ctx, rd)
+		if !ok {
+			return lastsave, ErrBgsaveFailed
+		}
+		val, err := strconv.ParseInt(info["rdb_last_save_time"], 10, 64)
+		if err != nil {
+			logger.Error("Redis Lastsave Error: ", err)
+			return lastsave, err
+		}
+		lastsave = time.Unix(val, 0)
+		if info["rdb_bgsave_in_progress"] == "0" {
+			if info["rdb_last_bgsave_status"] != "ok" {
+				return lastsave, fmt.Errorf("%w, last bgsave status %s", ErrBgsaveFailed, info["rdb_last_bgsave_status"])
+			}
+			if !lastsave.Equal(previous) {
+				return lastsave, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return lastsave, ctx.Err()
+		case <-timer.C:
+			return lastsave, fmt.Errorf("%w, last save at %s", ErrWaitSaveTimeout, lastsave.Format("2006-01-02 15:04:05"))
+		case <-ticker.C:
+		}
+	}
+}
+
+// 执行 bgsave 并等待 LASTSAVE 更新，返回rdb文件的路径和大小
+// 使用 target 单独的链接，不受全局链接切换的影响
+func BgsaveAndWait(ctx context.Context, target FleetTarget, timeout time.Duration) (model.BgsaveResult, error) {
+	var result model.BgsaveResult
+	rd, err := newTargetClient(target)
+	if err != nil {
+		return result, err
+	}
+	defer ReleaseClient(rd)
+	previous, ok := lastSave(ctx, rd)
+	if !ok {
+		return result, ErrBgsaveFailed
+	}
+	start := time.Now()
+	if err := safeBgsave(ctx, rd, target.Addr); err != nil {
+		return result, err
+	}
+	savedat, err := WaitForSave(ctx, rd, previous, timeout)
+	if err != nil {
+		return result, err
+	}
+	result.SavedAt = savedat
+	result.Duration = time.Since(start).Seconds()
+	result.Path, result.SizeBytes = rdbFile(ctx, rd)
+	return result, nil
+}
+
+// 通过 CONFIG GET dir 和 dbfilename 拼出rdb文件路径，本机上找不到文件时大小为 -1
+func rdbFile(ctx context.Context, rd *redis.Client) (string, int64) {
+	var dir, dbfilename string
+	if val, err := rd.Do(ctx, "config", "get", "dir").Result(); err == nil {
+		dir = configResult(val)["dir"]
+	}
+	if val, err := rd.Do(ctx, "config", "get", "dbfilename").Result(); err == nil {
+		dbfilename = configResult(val)["dbfilename"]
+	}
+	if dbfilename == "" {
+		return "", -1
+	}
+	path := filepath.Join(dir, dbfilename)
+	stat, err := os.Stat(path)
+	if err != nil {
+		return path, -1
+	}
+	return path, stat.Size()
+}
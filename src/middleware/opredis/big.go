@@ -3,6 +3,8 @@ package opredis
 import (
 	"sort"
 	"sync"
+
+	"github.com/iguidao/redis-manager/src/middleware/tools"
 )
 
 var (
@@ -28,7 +30,11 @@ func BigKey() map[string]interface{} {
 		return nil
 	}
 	wg.Add(1)
-	go Countkey(val)
+	firstkeys := val
+	tools.SafeGo("bigkeycount", func() {
+		defer wg.Done()
+		Countkey(firstkeys)
+	})
 	for {
 		val, num, scanok = GetScanKey(num, 1000)
 		if !scanok {
@@ -36,7 +42,11 @@ func BigKey() map[string]interface{} {
 		}
 		if num != 0 {
 			wg.Add(1)
-			go Countkey(val)
+			keylist := val
+			tools.SafeGo("bigkeycount", func() {
+				defer wg.Done()
+				Countkey(keylist)
+			})
 		} else {
 			break
 		}
@@ -123,7 +133,6 @@ func Countkey(keylist []string) {
 	hashkeymap = AppendMap(hashkeymap, chashkeymap)
 	setkeymap = AppendMap(setkeymap, csetkeymap)
 	zsetkeymap = AppendMap(zsetkeymap, czsetkeymap)
-	defer wg.Done()
 }
 
 func AppendMap(result, val map[string]int64) map[string]int64 {
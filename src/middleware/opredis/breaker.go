@@ -0,0 +1,137 @@
+package opredis
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	BREAKERCLOSED   = "closed"
+	BREAKEROPEN     = "open"
+	BREAKERHALFOPEN = "half_open"
+)
+
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// 熔断器，连续失败 Failures 次后打开，Cooldown 时间内直接返回 ErrCircuitOpen
+// 冷却结束后半开，只放一个请求探测，成功则关闭，失败继续打开
+type Breaker struct {
+	sync.Mutex
+	Failures    int
+	Cooldown    time.Duration
+	state       string
+	consecutive int
+	openedAt    time.Time
+	probing     bool
+}
+
+var (
+	breakerLock sync.Mutex
+	breakers    = make(map[string]*Breaker)
+)
+
+// 获取实例的熔断器，没有的时候按配置文件的默认值创建
+func GetBreaker(addr string) *Breaker {
+	breakerLock.Lock()
+	defer breakerLock.Unlock()
+	b, ok := breakers[addr]
+	if !ok {
+		b = &Breaker{Failures: defaultFailures(), Cooldown: defaultCooldown(), state: BREAKERCLOSED}
+		breakers[addr] = b
+	}
+	return b
+}
+
+// 单独设置实例的熔断阈值
+func SetBreakerConfig(addr string, failures int, cooldown time.Duration) {
+	b := GetBreaker(addr)
+	b.Lock()
+	b.Failures = failures
+	b.Cooldown = cooldown
+	b.Unlock()
+}
+
+func defaultFailures() int {
+	if failures := cfg.Get_Info_Int("breakerfailures"); failures > 0 {
+		return failures
+	}
+	return defaultBreakerFailures
+}
+
+func defaultCooldown() time.Duration {
+	if cooldown := cfg.Get_Info_Int("breakercooldown"); cooldown > 0 {
+		return time.Duration(cooldown) * time.Second
+	}
+	return defaultBreakerCooldown
+}
+
+func (b *Breaker) Allow() error {
+	b.Lock()
+	defer b.Unlock()
+	switch b.state {
+	case BREAKEROPEN:
+		if time.Since(b.openedAt) < b.Cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BREAKERHALFOPEN
+		b.probing = true
+		return nil
+	case BREAKERHALFOPEN:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// 记录请求结果，redis.Nil 不算失败
+func (b *Breaker) Record(err error) {
+	b.Lock()
+	defer b.Unlock()
+	b.probing = false
+	if err == nil || err == redis.Nil {
+		b.consecutive = 0
+		b.state = BREAKERCLOSED
+		return
+	}
+	b.consecutive++
+	if b.state == BREAKERHALFOPEN || b.consecutive >= b.Failures {
+		if b.state != BREAKEROPEN {
+			logger.Warn("熔断器打开，连续失败次数：", b.consecutive, " error: ", err)
+		}
+		b.state = BREAKEROPEN
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *Breaker) State() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.state
+}
+
+// 有熔断器的实例状态
+func BreakerStates() map[string]string {
+	breakerLock.Lock()
+	list := make(map[string]*Breaker)
+	for k, v := range breakers {
+		list[k] = v
+	}
+	breakerLock.Unlock()
+	result := make(map[string]string)
+	for k, v := range list {
+		result[k] = v.State()
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package opredis
+
+import (
+	"errors"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+var ErrWrongType = errors.New("key type does not support cardinality")
+
+// HyperLogLog 的值以 HYLL 开头
+const hllMagic = "HYLL"
+
+type CardinalityResult struct {
+	Key     string `json:"key"`
+	Type    string `json:"type"`    // hyperloglog、set、bitmap
+	Command string `json:"command"` // 使用的统计命令
+	Count   int64  `json:"count"`
+}
+
+// 根据key的类型选择 PFCOUNT、SCARD 或者 BITCOUNT
+func Cardinality(keyname string) (CardinalityResult, error) {
+	result := CardinalityResult{Key: keyname}
+	keytype, err := RD.Type(ctx, keyname).Result()
+	if err != nil {
+		logger.Error("Redis Type key: ", keyname, " Error: ", err)
+		return result, err
+	}
+	switch keytype {
+	case "none":
+		return result, ErrNoSuchKey
+	case "set":
+		result.Type, result.Command = "set", "scard"
+		result.Count, err = RD.SCard(ctx, keyname).Result()
+	case "string":
+		magic, rerr := RD.GetRange(ctx, keyname, 0, int64(len(hllMagic)-1)).Result()
+		if rerr != nil {
+			logger.Error("Redis Getrange key: ", keyname, " Error: ", rerr)
+			return result, rerr
+		}
+		if magic == hllMagic {
+			result.Type, result.Command = "hyperloglog", "pfcount"
+			result.Count, err = RD.PFCount(ctx, keyname).Result()
+		} else {
+			result.Type, result.Command = "bitmap", "bitcount"
+			result.Count, err = RD.BitCount(ctx, keyname, nil).Result()
+		}
+	default:
+		return result, ErrWrongType
+	}
+	if err != nil {
+		logger.Error("Redis ", result.Command, " key: ", keyname, " Error: ", err)
+	}
+	return result, err
+}
+
+// 扫描匹配 pattern 的key并统计，不支持的类型跳过，最多返回 limit 个
+func CardinalityByPattern(pattern string, limit int) ([]CardinalityResult, bool) {
+	var result []CardinalityResult
+	var cursor uint64
+	for fornum := 0; fornum < cfg.Get_Info_Int("allkeyfornum"); fornum++ {
+		keylist, next, err := RD.Scan(ctx, cursor, pattern, 1000).Result()
+		if err != nil {
+			logger.Error("Redis Scan ", pattern, " Error: ", err)
+			return result, false
+		}
+		for _, keyname := range keylist {
+			count, err := Cardinality(keyname)
+			if err != nil {
+				continue
+			}
+			result = append(result, count)
+			if len(result) >= limit {
+				return result, true
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return result, true
+}
@@ -0,0 +1,65 @@
+package opredis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// INFO commandstats 的一行
+type CommandStat struct {
+	Command     string  `json:"command"` // 子命令用 | 分隔，例如 client|list
+	Calls       int64   `json:"calls"`
+	Usec        int64   `json:"usec"`
+	UsecPerCall float64 `json:"usec_per_call"`
+	Rejected    int64   `json:"rejected"` // redis 7 才有
+	Failed      int64   `json:"failed"`   // redis 7 才有
+}
+
+const cmdstatPrefix = "cmdstat_"
+
+// 获取命令统计，按总耗时从大到小排序
+func CommandStats() ([]CommandStat, bool) {
+	info, ok := GetInfo("commandstats")
+	if !ok {
+		return nil, false
+	}
+	return ParseCommandStats(info), true
+}
+
+// 解析 cmdstat_xxx:calls=1,usec=2,usec_per_call=2.00,rejected_calls=0,failed_calls=0
+func ParseCommandStats(info map[string]string) []CommandStat {
+	var result []CommandStat
+	for k, v := range info {
+		if !strings.HasPrefix(k, cmdstatPrefix) {
+			continue
+		}
+		stat := CommandStat{Command: strings.TrimPrefix(k, cmdstatPrefix)}
+		for _, field := range strings.Split(v, ",") {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "calls":
+				stat.Calls, _ = strconv.ParseInt(kv[1], 10, 64)
+			case "usec":
+				stat.Usec, _ = strconv.ParseInt(kv[1], 10, 64)
+			case "usec_per_call":
+				stat.UsecPerCall, _ = strconv.ParseFloat(kv[1], 64)
+			case "rejected_calls":
+				stat.Rejected, _ = strconv.ParseInt(kv[1], 10, 64)
+			case "failed_calls":
+				stat.Failed, _ = strconv.ParseInt(kv[1], 10, 64)
+			}
+		}
+		result = append(result, stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Usec != result[j].Usec {
+			return result[i].Usec > result[j].Usec
+		}
+		return result[i].Command < result[j].Command
+	})
+	return result
+}
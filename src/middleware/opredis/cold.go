@@ -0,0 +1,94 @@
+package opredis
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+type ColdKey struct {
+	Key      string `json:"key"`
+	IdleTime int64  `json:"idletime"` // 空闲秒数，LRU时有效
+	Freq     int64  `json:"freq"`     // 访问频率，LFU时有效
+}
+
+type ColdKeyResult struct {
+	Policy string    `json:"policy"` // maxmemory-policy
+	Metric string    `json:"metric"` // 使用的指标 idletime/freq
+	Keys   []ColdKey `json:"keys"`
+}
+
+// 判断是否是LFU淘汰策略
+func IsLfuPolicy(policy string) bool {
+	return strings.Contains(policy, "lfu")
+}
+
+// limit 小于等于0时最多返回的冷key个数
+const defaultColdKeyLimit = 100
+
+// 扫描key找出冷key，LRU策略下使用 OBJECT IDLETIME，LFU策略下使用 OBJECT FREQ
+// LFU时访问频率小于等于 maxfreq 的key认为是冷key，每个key一次 OBJECT 请求，受实例的扫描限速控制
+func ColdKeys(serverip string, idlethreshold time.Duration, maxfreq int64, limit int) (ColdKeyResult, bool) {
+	var result ColdKeyResult
+	if limit <= 0 {
+		limit = defaultColdKeyLimit
+	}
+	policy, ok := GetOneConfig("maxmemory-policy")
+	if !ok {
+		return result, false
+	}
+	result.Policy = policy
+	result.Metric = "idletime"
+	if IsLfuPolicy(policy) {
+		result.Metric = "freq"
+	}
+	limiter := GetRateLimiter(serverip)
+	var cursor uint64
+	for fornum := 0; fornum < cfg.Get_Info_Int("allkeyfornum"); fornum++ {
+		keylist, next, scanok := GetScanKey(cursor, 1000)
+		if !scanok {
+			return result, false
+		}
+		if err := limiter.Wait(ctx, len(keylist)); err != nil {
+			return result, false
+		}
+		for _, keyname := range keylist {
+			if result.Metric == "freq" {
+				freq, err := RD.Do(ctx, "object", "freq", keyname).Int64()
+				if err != nil {
+					logger.Error("Redis Object Freq key: ", keyname, " Error: ", err)
+					continue
+				}
+				if freq <= maxfreq {
+					result.Keys = append(result.Keys, ColdKey{Key: keyname, Freq: freq})
+				}
+			} else {
+				idle, err := RD.ObjectIdleTime(ctx, keyname).Result()
+				if err != nil {
+					logger.Error("Redis Object Idletime key: ", keyname, " Error: ", err)
+					continue
+				}
+				if idle >= idlethreshold {
+					result.Keys = append(result.Keys, ColdKey{Key: keyname, IdleTime: int64(idle.Seconds())})
+				}
+			}
+			if len(result.Keys) >= limit {
+				break
+			}
+		}
+		cursor = next
+		if cursor == 0 || len(result.Keys) >= limit {
+			break
+		}
+	}
+	sort.Slice(result.Keys, func(i, j int) bool {
+		if result.Metric == "freq" {
+			return result.Keys[i].Freq < result.Keys[j].Freq
+		}
+		return result.Keys[i].IdleTime > result.Keys[j].IdleTime
+	})
+	return result, true
+}
@@ -0,0 +1,148 @@
+package opredis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 最多返回的元素个数
+const collectionSampleMax = 1000
+
+type CollectionElement struct {
+	Member string  `json:"member"`          // hash 的 field、list/set/zset 的元素、stream 的消息ID
+	Value  string  `json:"value,omitempty"` // hash 的 value、stream 的消息内容
+	Score  float64 `json:"score,omitempty"` // zset 的分数
+}
+
+type CollectionSampleResult struct {
+	Key      string              `json:"key"`
+	Type     string              `json:"type"`
+	Total    int64               `json:"total"` // 集合的元素总数
+	Elements []CollectionElement `json:"elements"`
+}
+
+// 查看大集合的前 n 个元素和总数，不用把整个集合取出来
+// hash/set/zset 用 HSCAN/SSCAN/ZSCAN，list 用 LRANGE，stream 用 XRANGE，string 返回 ErrWrongType
+func CollectionSample(ctx context.Context, serverip, keyname string, n int) (CollectionSampleResult, error) {
+	result := CollectionSampleResult{Key: keyname}
+	if n <= 0 || n > collectionSampleMax {
+		n = collectionSampleMax
+	}
+	keytype, err := RD.Type(ctx, keyname).Result()
+	if err != nil {
+		logger.Error("Redis Type key: ", keyname, " Error: ", err)
+		return result, NewOpError(serverip, "sample", err)
+	}
+	result.Type = keytype
+	limiter := GetRateLimiter(serverip)
+	switch keytype {
+	case "none":
+		return result, NewOpError(serverip, "sample", ErrNoSuchKey)
+	case "hash":
+		result.Total, err = RD.HLen(ctx, keyname).Result()
+		if err == nil {
+			err = scanCollection(ctx, limiter, n, func(cursor uint64) ([]string, uint64, error) {
+				return RD.HScan(ctx, keyname, cursor, "*", 100).Result()
+			}, func(items []string) int {
+				for i := 0; i+1 < len(items) && len(result.Elements) < n; i += 2 {
+					result.Elements = append(result.Elements, CollectionElement{Member: items[i], Value: items[i+1]})
+				}
+				return len(result.Elements)
+			})
+		}
+	case "set":
+		result.Total, err = RD.SCard(ctx, keyname).Result()
+		if err == nil {
+			err = scanCollection(ctx, limiter, n, func(cursor uint64) ([]string, uint64, error) {
+				return RD.SScan(ctx, keyname, cursor, "*", 100).Result()
+			}, func(items []string) int {
+				for i := 0; i < len(items) && len(result.Elements) < n; i++ {
+					result.Elements = append(result.Elements, CollectionElement{Member: items[i]})
+				}
+				return len(result.Elements)
+			})
+		}
+	case "zset":
+		result.Total, err = RD.ZCard(ctx, keyname).Result()
+		if err == nil {
+			err = scanCollection(ctx, limiter, n, func(cursor uint64) ([]string, uint64, error) {
+				return RD.ZScan(ctx, keyname, cursor, "*", 100).Result()
+			}, func(items []string) int {
+				for i := 0; i+1 < len(items) && len(result.Elements) < n; i += 2 {
+					score, _ := strconv.ParseFloat(items[i+1], 64)
+					result.Elements = append(result.Elements, CollectionElement{Member: items[i], Score: score})
+				}
+				return len(result.Elements)
+			})
+		}
+	case "list":
+		result.Total, err = RD.LLen(ctx, keyname).Result()
+		if err == nil {
+			err = limiter.Wait(ctx, n)
+		}
+		if err == nil {
+			var items []string
+			items, err = RD.LRange(ctx, keyname, 0, int64(n-1)).Result()
+			for _, v := range items {
+				result.Elements = append(result.Elements, CollectionElement{Member: v})
+			}
+		}
+	case "stream":
+		result.Total, err = RD.XLen(ctx, keyname).Result()
+		if err == nil {
+			err = limiter.Wait(ctx, n)
+		}
+		if err == nil {
+			msgs, xerr := RD.XRangeN(ctx, keyname, "-", "+", int64(n)).Result()
+			err = xerr
+			for _, msg := range msgs {
+				result.Elements = append(result.Elements, CollectionElement{Member: msg.ID, Value: streamValues(msg.Values)})
+			}
+		}
+	default:
+		return result, NewOpError(serverip, "sample", ErrWrongType)
+	}
+	if err != nil {
+		logger.Error("Redis sample ", keytype, " key: ", keyname, " Error: ", err)
+		return result, NewOpError(serverip, "sample", err)
+	}
+	return result, nil
+}
+
+// 按 cursor 扫描，直到取够 n 个元素或者扫描结束，每批都经过限速
+func scanCollection(ctx context.Context, limiter *RateLimiter, n int, scan func(uint64) ([]string, uint64, error), add func([]string) int) error {
+	var cursor uint64
+	for got := 0; got < n; {
+		items, next, err := scan(cursor)
+		if err != nil {
+			return err
+		}
+		if err := limiter.Wait(ctx, len(items)); err != nil {
+			return err
+		}
+		got = add(items)
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// stream 消息的字段按名字排序，格式为 field=value
+func streamValues(values map[string]interface{}) string {
+	fields := make([]string, 0, len(values))
+	for k := range values {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	for i, k := range fields {
+		fields[i] = k + "=" + fmt.Sprint(values[k])
+	}
+	return strings.Join(fields, " ")
+}
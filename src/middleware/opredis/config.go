@@ -0,0 +1,42 @@
+package opredis
+
+import (
+	"fmt"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 获取redis配置，pattern支持通配符
+func GetConfig(pattern string) (map[string]string, bool) {
+	val, err := RD.Do(ctx, "config", "get", pattern).Result()
+	if err != nil {
+		logger.Error("Redis Config Get ", pattern, " Error: ", err)
+		return nil, false
+	}
+	return configResult(val), true
+}
+
+// RESP2 返回 key value 交替的数组，RESP3 返回map
+func configResult(val interface{}) map[string]string {
+	result := make(map[string]string)
+	switch val := val.(type) {
+	case []interface{}:
+		for i := 0; i+1 < len(val); i += 2 {
+			result[fmt.Sprint(val[i])] = fmt.Sprint(val[i+1])
+		}
+	case map[interface{}]interface{}:
+		for k, v := range val {
+			result[fmt.Sprint(k)] = fmt.Sprint(v)
+		}
+	}
+	return result
+}
+
+// 获取单个redis配置
+func GetOneConfig(name string) (string, bool) {
+	result, ok := GetConfig(name)
+	if !ok {
+		return "", false
+	}
+	return result[name], true
+}
@@ -1,6 +1,9 @@
 package opredis
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 
 	"github.com/go-redis/redis/v9"
@@ -9,40 +12,99 @@ import (
 // 单点和codis链接
 type ClientConnect struct {
 	*redis.Client
+	ReadOnly bool // 只读链接，写命令在客户端直接拒绝
 }
 
 var RD ClientConnect
 
+var ErrClusterDB = errors.New("redis cluster only supports db 0")
+
 func ConnectRedis(addr, password string) bool {
-	rd := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password, // no password set
-		// DB:       0,        // use default DB
-	})
-	RD = ClientConnect{rd}
-	_, err := RD.Ping(ctx).Result()
+	return connectRedis(addr, password, false)
+}
+
+// 只读链接，给只能查询的用户使用
+func ConnectRedisReadOnly(addr, password string) bool {
+	return connectRedis(addr, password, true)
+}
+
+func connectRedis(addr, password string, readonly bool) bool {
+	return DialRedis(addr, password, 0, readonly) == nil
+}
+
+// 建立单点链接并ping，addr 可以是 redis:// 格式的url，db 为要操作的逻辑库，认证失败时返回 ErrAuthRequired 或 ErrAuthFailed
+func DialRedis(addr, password string, db int, readonly bool) error {
+	opt, err := RedisOptions(addr, password, db)
 	if err != nil {
 		logger.Error("Redis Connect Error: ", err)
-		return false
+		return NewOpError(EndpointAddr(addr), "connect", err)
 	}
-	return true
+	breaker := GetBreaker(opt.Addr)
+	if err := breaker.Allow(); err != nil {
+		logger.Error("Redis Connect ", opt.Addr, " Error: ", err)
+		return NewOpError(opt.Addr, "connect", err)
+	}
+	timeout := DefaultTimeout()
+	opt.DialTimeout = timeout
+	opt.ReadTimeout = timeout
+	opt.WriteTimeout = timeout
+	// 切换实例时不释放之前的链接，其他请求可能还在使用
+	rd := holdClient(opt, readonly)
+	RD = ClientConnect{Client: rd, ReadOnly: readonly}
+	clearVersion(opt.Addr)
+	pingctx, cancel := TimeoutCtx()
+	defer cancel()
+	_, err = RD.Ping(pingctx).Result()
+	breaker.Record(err)
+	if err != nil {
+		err = NewOpError(opt.Addr, "connect", MapAuthError(err))
+		logger.Error("Redis Connect Error: ", err)
+		return err
+	}
+	return nil
 }
 
 // 集群链接
 type ClientClusterConnect struct {
 	*redis.ClusterClient
+	ReadOnly bool // 只读链接，写命令在客户端直接拒绝
 }
 
 var CRD ClientClusterConnect
 
 func ConnectRedisCluster(addr []string, password string) bool {
+	return connectRedisCluster(addr, password, false)
+}
+
+// 只读集群链接，从节点上会执行 READONLY
+func ConnectRedisClusterReadOnly(addr []string, password string) bool {
+	return connectRedisCluster(addr, password, true)
+}
+
+// cluster 节点只支持 host:port
+func connectRedisCluster(addr []string, password string, readonly bool) bool {
+	for _, v := range addr {
+		network, _, err := ParseEndpoint(v)
+		if err == nil && network != "tcp" {
+			err = fmt.Errorf("%w: cluster does not support unix socket: %q", ErrInvalidEndpoint, v)
+		}
+		if err != nil {
+			logger.Error("Redis Cluster Connect Error: ", err)
+			return false
+		}
+	}
+	timeout := DefaultTimeout()
 	rd := redis.NewClusterClient(&redis.ClusterOptions{
-		Addrs:    addr,
-		Password: password,
-		// DialTimeout:  200 * time.Microsecond,
-		// ReadTimeout:  200 * time.Microsecond,
-		// WriteTimeout: 200 * time.Microsecond,
+		Addrs:        addr,
+		Password:     password,
+		ReadOnly:     readonly,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
 	})
-	CRD = ClientClusterConnect{rd}
+	if readonly {
+		rd.AddHook(readOnlyHook{})
+	}
+	CRD = ClientClusterConnect{ClusterClient: rd, ReadOnly: readonly}
 	return true
 }
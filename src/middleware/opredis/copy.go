@@ -0,0 +1,85 @@
+package opredis
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+var (
+	ErrBusyKey   = errors.New("BUSYKEY target key already exists")
+	ErrNoSuchKey = errors.New("source key does not exist")
+)
+
+// 复制key，优先使用 COPY，redis 6.2 以下使用 DUMP/RESTORE
+// ttl 大于0时覆盖目标key的过期时间，否则保留源key的过期时间
+func CopyKey(src, dst string, replace bool, ttl time.Duration) error {
+	if err := RD.CheckCommand("copy"); err != nil {
+		return err
+	}
+	exists, err := RD.Exists(ctx, src).Result()
+	if err != nil {
+		logger.Error("Redis Exists key: ", src, " Error: ", err)
+		return err
+	}
+	if exists == 0 {
+		return ErrNoSuchKey
+	}
+	var copied int64
+	if caps, ok := ServerCapabilities(); ok && !caps.Copy {
+		// 6.2 以下没有 COPY
+		err = restoreCopy(src, dst, replace)
+		copied = 1
+	} else {
+		copied, err = RD.Copy(ctx, src, dst, RD.Options().DB, replace).Result()
+		if err != nil && strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+			err = restoreCopy(src, dst, replace)
+			copied = 1
+		}
+	}
+	if err != nil {
+		logger.Error("Redis Copy key: ", src, " to ", dst, " Error: ", err)
+		return err
+	}
+	if copied == 0 {
+		return ErrBusyKey
+	}
+	if ttl > 0 {
+		if err := RD.Expire(ctx, dst, ttl).Err(); err != nil {
+			logger.Error("Redis Expire key: ", dst, " Error: ", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreCopy(src, dst string, replace bool) error {
+	value, err := RD.Dump(ctx, src).Result()
+	if err != nil {
+		return err
+	}
+	pttl, err := RD.PTTL(ctx, src).Result()
+	if err != nil {
+		return err
+	}
+	// 没有过期时间的时候 PTTL 返回负数
+	if pttl < 0 {
+		pttl = 0
+	}
+	var cmd *redis.StatusCmd
+	if replace {
+		cmd = RD.RestoreReplace(ctx, dst, pttl, value)
+	} else {
+		cmd = RD.Restore(ctx, dst, pttl, value)
+	}
+	if err := cmd.Err(); err != nil {
+		if strings.HasPrefix(err.Error(), "BUSYKEY") {
+			return ErrBusyKey
+		}
+		return err
+	}
+	return nil
+}
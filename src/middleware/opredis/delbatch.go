@@ -0,0 +1,58 @@
+package opredis
+
+import (
+	"context"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+
+	"github.com/go-redis/redis/v9"
+)
+
+const deleteBatchSize = 100
+
+// 按实例限速，分批 pipeline 删除key，useunlink 为 true 时使用非阻塞的 UNLINK
+// 返回实际删除的数量，ctx 取消时返回已删除的数量和 ctx.Err()
+func DeleteKeys(delctx context.Context, serverip string, keys []string, useunlink bool) (int, error) {
+	command := "del"
+	// 4.0 以下没有 UNLINK，使用 DEL
+	if caps, ok := ServerCapabilities(); ok && !caps.Unlink {
+		useunlink = false
+	}
+	if useunlink {
+		command = "unlink"
+	}
+	if err := RD.CheckCommand(command); err != nil {
+		return 0, err
+	}
+	limiter := GetRateLimiter(serverip)
+	deleted := 0
+	for start := 0; start < len(keys); start += deleteBatchSize {
+		end := start + deleteBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batch := keys[start:end]
+		if err := limiter.Wait(delctx, len(batch)); err != nil {
+			return deleted, err
+		}
+		pipe := RD.Pipeline()
+		for _, keyname := range batch {
+			if useunlink {
+				pipe.Unlink(delctx, keyname)
+			} else {
+				pipe.Del(delctx, keyname)
+			}
+		}
+		cmds, err := pipe.Exec(delctx)
+		for _, cmd := range cmds {
+			if intcmd, ok := cmd.(*redis.IntCmd); ok {
+				deleted += int(intcmd.Val())
+			}
+		}
+		if err != nil {
+			logger.Error("ip: ", serverip, " ", command, " keys error: ", err)
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,99 @@
+package opredis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+
+	"github.com/go-redis/redis/v9"
+)
+
+var ErrScanFailed = errors.New("scan failed")
+
+// 每组最多返回的key个数
+const duplicateGroupLimit = 100
+
+// 采样找出值完全相同的 string key，返回 值的sha1 -> key列表，只返回有两个及以上key的组
+// 只是采样，不能保证找全；只比较长度不超过 maxvaluebytes 的小值，大值请用大key分析
+func FindDuplicateValues(ctx context.Context, serverip string, maxvaluebytes, samplesize int) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	if maxvaluebytes <= 0 || samplesize <= 0 {
+		return groups, nil
+	}
+	limiter := GetRateLimiter(serverip)
+	var cursor uint64
+	sampled := 0
+	for sampled < samplesize {
+		keys, next, ok := ScanKeys(cursor, "*", 1000, "string")
+		if !ok {
+			return filterDuplicates(groups), NewOpError(serverip, "duplicates", ErrScanFailed)
+		}
+		if len(keys) > samplesize-sampled {
+			keys = keys[:samplesize-sampled]
+		}
+		if err := limiter.Wait(ctx, len(keys)); err != nil {
+			return filterDuplicates(groups), NewOpError(serverip, "duplicates", err)
+		}
+		small, err := smallStringKeys(ctx, keys, int64(maxvaluebytes))
+		if err != nil {
+			return filterDuplicates(groups), NewOpError(serverip, "duplicates", err)
+		}
+		pipe := RD.Pipeline()
+		cmds := make([]*redis.StringCmd, len(small))
+		for i, keyname := range small {
+			cmds[i] = pipe.Get(ctx, keyname)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+			return filterDuplicates(groups), NewOpError(serverip, "duplicates", err)
+		}
+		for i, cmd := range cmds {
+			// 采样期间被删除或者改了类型的key跳过
+			val, err := cmd.Bytes()
+			if err != nil {
+				continue
+			}
+			sum := sha1.Sum(val)
+			hash := hex.EncodeToString(sum[:])
+			if len(groups[hash]) < duplicateGroupLimit {
+				groups[hash] = append(groups[hash], small[i])
+			}
+		}
+		sampled += len(keys)
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return filterDuplicates(groups), nil
+}
+
+// STRLEN 过滤掉超过大小的值，避免 GET 大value
+func smallStringKeys(ctx context.Context, keys []string, maxvaluebytes int64) ([]string, error) {
+	pipe := RD.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, keyname := range keys {
+		cmds[i] = pipe.StrLen(ctx, keyname)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+	var result []string
+	for i, cmd := range cmds {
+		size, err := cmd.Result()
+		if err != nil || size > maxvaluebytes {
+			continue
+		}
+		result = append(result, keys[i])
+	}
+	return result, nil
+}
+
+func filterDuplicates(groups map[string][]string) map[string][]string {
+	for hash, keys := range groups {
+		if len(keys) < 2 {
+			delete(groups, hash)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,148 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/metrics"
+)
+
+var ErrNotEnoughSample = errors.New("not enough eviction samples")
+
+// 默认的统计窗口
+const defaultEvictionWindow = 5 * time.Minute
+
+// INFO stats 里 expired_keys 和 evicted_keys 的采样
+type evictionSample struct {
+	Time    time.Time
+	Expired int64
+	Evicted int64
+}
+
+// 实例的过期和驱逐速率
+type EvictionRateResult struct {
+	Id            string  `json:"id"`
+	Name          string  `json:"name"`
+	Addr          string  `json:"addr"`
+	ExpiredPerSec float64 `json:"expired_per_sec"`
+	EvictedPerSec float64 `json:"evicted_per_sec"`
+}
+
+var (
+	evictionLock    sync.Mutex
+	evictionSamples = make(map[string][]evictionSample)
+)
+
+func evictionWindow() time.Duration {
+	if window := cfg.Get_Info_Int("evictionwindow"); window > 0 {
+		return time.Duration(window) * time.Second
+	}
+	return defaultEvictionWindow
+}
+
+// 采样一次实例的 expired_keys 和 evicted_keys
+func SampleEviction(ctx context.Context, target FleetTarget) error {
+	rd, err := newTargetClient(target)
+	if err != nil {
+		logger.Error("Redis Eviction Sample ", target.Addr, " Error: ", err)
+		return err
+	}
+	defer ReleaseClient(rd)
+	infoctx, cancel := context.WithTimeout(ctx, DefaultTimeout())
+	defer cancel()
+	val, err := rd.Info(infoctx, "stats").Result()
+	if err != nil {
+		logger.Error("Redis Eviction Sample ", target.Addr, " Error: ", err)
+		return err
+	}
+	info := ParseInfo(val)
+	RecordEvictionSample(target.Id, infoInt(info, "expired_keys"), infoInt(info, "evicted_keys"), time.Now())
+	return nil
+}
+
+// 删除已经不在 targets 里的实例的采样，实例下线后不再占用内存
+func PruneEvictionSamples(targets []FleetTarget) {
+	alive := make(map[string]bool, len(targets))
+	for _, v := range targets {
+		alive[v.Id] = true
+	}
+	evictionLock.Lock()
+	defer evictionLock.Unlock()
+	for id := range evictionSamples {
+		if !alive[id] {
+			delete(evictionSamples, id)
+		}
+	}
+}
+
+// 记录采样，只保留窗口内的数据，并把增量累加到指标里
+func RecordEvictionSample(instanceid string, expired, evicted int64, now time.Time) {
+	evictionLock.Lock()
+	defer evictionLock.Unlock()
+	samples := evictionSamples[instanceid]
+	if n := len(samples); n > 0 {
+		last := samples[n-1]
+		labels := map[string]string{"instance": instanceid}
+		// 实例重启后计数会变小，这时不累加
+		if expired >= last.Expired {
+			metrics.Add("redis_manager_expired_keys_total", float64(expired-last.Expired), labels)
+		}
+		if evicted >= last.Evicted {
+			metrics.Add("redis_manager_evicted_keys_total", float64(evicted-last.Evicted), labels)
+		}
+		if expired < last.Expired || evicted < last.Evicted {
+			samples = nil
+		}
+	}
+	samples = append(samples, evictionSample{Time: now, Expired: expired, Evicted: evicted})
+	start := 0
+	window := evictionWindow()
+	for start < len(samples)-1 && now.Sub(samples[start].Time) > window {
+		start++
+	}
+	evictionSamples[instanceid] = samples[start:]
+}
+
+// 窗口内每秒过期和驱逐的key个数
+func EvictionRate(instanceid string) (float64, float64, error) {
+	evictionLock.Lock()
+	defer evictionLock.Unlock()
+	samples := evictionSamples[instanceid]
+	if len(samples) < 2 {
+		return 0, 0, ErrNotEnoughSample
+	}
+	first := samples[0]
+	last := samples[len(samples)-1]
+	seconds := last.Time.Sub(first.Time).Seconds()
+	if seconds <= 0 {
+		return 0, 0, ErrNotEnoughSample
+	}
+	return float64(last.Expired-first.Expired) / seconds, float64(last.Evicted-first.Evicted) / seconds, nil
+}
+
+// 窗口内有驱逐的实例，按驱逐速率从大到小排序，说明实例内存有压力
+func EvictingInstances(targets []FleetTarget) []EvictionRateResult {
+	var result []EvictionRateResult
+	for _, v := range targets {
+		expired, evicted, err := EvictionRate(v.Id)
+		if err != nil || evicted <= 0 {
+			continue
+		}
+		result = append(result, EvictionRateResult{
+			Id:            v.Id,
+			Name:          v.Name,
+			Addr:          v.Addr,
+			ExpiredPerSec: expired,
+			EvictedPerSec: evicted,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].EvictedPerSec > result[j].EvictedPerSec
+	})
+	return result
+}
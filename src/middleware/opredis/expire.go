@@ -0,0 +1,81 @@
+package opredis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 过期和惰性删除相关的配置和统计，用来排查过期引起的延迟抖动
+type ExpireSettings struct {
+	Hz                     string   `json:"hz"`
+	ActiveRehashing        string   `json:"activerehashing"`
+	ActiveExpireEffort     string   `json:"active_expire_effort"` // 6.0 之前没有这个配置
+	LazyfreeLazyExpire     string   `json:"lazyfree_lazy_expire"`
+	LazyfreeLazyEviction   string   `json:"lazyfree_lazy_eviction"`
+	LazyfreeLazyServerDel  string   `json:"lazyfree_lazy_server_del"`
+	LazyfreeLazyUserDel    string   `json:"lazyfree_lazy_user_del"` // 6.0 之前没有这个配置
+	LazyfreeEnabled        bool     `json:"lazyfree_enabled"`       // 有任意一个 lazyfree 配置开启
+	ExpiredKeys            int64    `json:"expired_keys"`
+	EvictedKeys            int64    `json:"evicted_keys"`
+	ExpiredStalePerc       string   `json:"expired_stale_perc"`
+	ExpiredTimeCapReached  int64    `json:"expired_time_cap_reached_count"` // 过期循环因为超时提前退出的次数
+	ExpireCycleCpuMs       int64    `json:"expire_cycle_cpu_milliseconds"`
+	LazyfreePendingObjects int64    `json:"lazyfree_pending_objects"`
+	Warnings               []string `json:"warnings"`
+}
+
+var expireConfigs = []string{"hz", "activerehashing", "active-expire-effort", "lazyfree-lazy-expire", "lazyfree-lazy-eviction", "lazyfree-lazy-server-del", "lazyfree-lazy-user-del"}
+
+// 从 CONFIG GET 和 INFO 里汇总过期和惰性删除的状态
+func GetExpireSettings(ctx context.Context, serverip string) (ExpireSettings, error) {
+	var result ExpireSettings
+	config := make(map[string]string)
+	for _, name := range expireConfigs {
+		val, err := RD.Do(ctx, "config", "get", name).Result()
+		if err != nil {
+			logger.Error("Redis Config Get ", name, " Error: ", err)
+			return result, NewOpError(serverip, "expire", err)
+		}
+		for k, v := range configResult(val) {
+			config[k] = v
+		}
+	}
+	stats, err := RD.Info(ctx, "stats").Result()
+	if err != nil {
+		logger.Error("Redis Info stats Error: ", err)
+		return result, NewOpError(serverip, "expire", err)
+	}
+	memory, err := RD.Info(ctx, "memory").Result()
+	if err != nil {
+		logger.Error("Redis Info memory Error: ", err)
+		return result, NewOpError(serverip, "expire", err)
+	}
+	info := ParseInfo(stats + "\n" + memory)
+	result.Hz = config["hz"]
+	result.ActiveRehashing = config["activerehashing"]
+	result.ActiveExpireEffort = config["active-expire-effort"]
+	result.LazyfreeLazyExpire = config["lazyfree-lazy-expire"]
+	result.LazyfreeLazyEviction = config["lazyfree-lazy-eviction"]
+	result.LazyfreeLazyServerDel = config["lazyfree-lazy-server-del"]
+	result.LazyfreeLazyUserDel = config["lazyfree-lazy-user-del"]
+	for _, v := range []string{result.LazyfreeLazyExpire, result.LazyfreeLazyEviction, result.LazyfreeLazyServerDel, result.LazyfreeLazyUserDel} {
+		if v == "yes" {
+			result.LazyfreeEnabled = true
+		}
+	}
+	result.ExpiredKeys = infoInt(info, "expired_keys")
+	result.EvictedKeys = infoInt(info, "evicted_keys")
+	result.ExpiredStalePerc = info["expired_stale_perc"]
+	result.ExpiredTimeCapReached = infoInt(info, "expired_time_cap_reached_count")
+	result.ExpireCycleCpuMs = infoInt(info, "expire_cycle_cpu_milliseconds")
+	result.LazyfreePendingObjects = infoInt(info, "lazyfree_pending_objects")
+	if !result.LazyfreeEnabled {
+		result.Warnings = append(result.Warnings, "没有开启 lazyfree，删除大key时会阻塞")
+	}
+	if result.ExpiredTimeCapReached > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("过期循环超时退出 %d 次，过期key较多时可能引起延迟", result.ExpiredTimeCapReached))
+	}
+	return result, nil
+}
@@ -0,0 +1,232 @@
+package opredis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+// 大key导出的行
+type BigKeyRow struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+// 慢日志导出的行
+type SlowLogRow struct {
+	Source     string `json:"source"`
+	Id         int64  `json:"id"`
+	Time       string `json:"time"`
+	DurationUs int64  `json:"duration_us"`
+	Command    string `json:"command"`
+	Client     string `json:"client"`
+}
+
+var (
+	bigKeyHeader      = []string{"type", "key", "size"}
+	slowLogHeader     = []string{"source", "id", "time", "duration_us", "command", "client"}
+	commandStatHeader = []string{"command", "calls", "usec", "usec_per_call", "rejected", "failed"}
+)
+
+var (
+	ErrExportOp     = errors.New("export report: op does not produce a report")
+	ErrExportFormat = errors.New("export report: unsupported format")
+	ErrExportReport = errors.New("export report: unsupported report type")
+)
+
+// 可以导出的操作，只有这些操作的结果是报表
+var ExportOps = []string{"big", "slow", "cmdstats"}
+
+// 执行操作前检查是否可以导出，避免修改类操作执行后才发现不能导出
+func CheckExport(op, format string) error {
+	supported := false
+	for _, v := range ExportOps {
+		if v == op {
+			supported = true
+		}
+	}
+	if !supported {
+		return ErrExportOp
+	}
+	switch strings.ToLower(format) {
+	case "csv", "json":
+		return nil
+	}
+	return ErrExportFormat
+}
+
+// 把大key、慢日志或者命令统计的结果导出为csv或者json，op 决定按哪种报表解析
+func ExportReport(w io.Writer, op string, report interface{}, format string) error {
+	if err := CheckExport(op, format); err != nil {
+		return err
+	}
+	var header []string
+	var rows [][]string
+	var jsonrows interface{}
+	switch report := report.(type) {
+	case []redis.SlowLog:
+		if op != "slow" {
+			return ErrExportReport
+		}
+		slowrows := SlowLogRows(report)
+		header, rows, jsonrows = slowLogHeader, slowLogRecords(slowrows), slowrows
+	case []CommandStat:
+		if op != "cmdstats" {
+			return ErrExportReport
+		}
+		header, rows, jsonrows = commandStatHeader, commandStatRecords(report), report
+	case map[string]interface{}:
+		switch op {
+		case "slow":
+			slowrows, ok := txSlowLogRows(report)
+			if !ok {
+				return ErrExportReport
+			}
+			header, rows, jsonrows = slowLogHeader, slowLogRecords(slowrows), slowrows
+		case "big":
+			bigrows := BigKeyRows(report)
+			header, rows, jsonrows = bigKeyHeader, bigKeyRecords(bigrows), bigrows
+		default:
+			return ErrExportReport
+		}
+	default:
+		return ErrExportReport
+	}
+	switch strings.ToLower(format) {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case "json":
+		if len(rows) == 0 {
+			_, err := io.WriteString(w, "[]\n")
+			return err
+		}
+		return json.NewEncoder(w).Encode(jsonrows)
+	}
+	return ErrExportFormat
+}
+
+// 大key结果转为行，按类型和大小排序
+func BigKeyRows(report map[string]interface{}) []BigKeyRow {
+	var result []BigKeyRow
+	for name, value := range report {
+		keytype := bigKeyType(name)
+		switch value := value.(type) {
+		case map[string]int64:
+			for k, v := range value {
+				result = append(result, BigKeyRow{keytype, k, v})
+			}
+		case map[string]int:
+			for k, v := range value {
+				result = append(result, BigKeyRow{keytype, k, int64(v)})
+			}
+		case map[string]interface{}:
+			for k, v := range value {
+				switch v := v.(type) {
+				case float64:
+					result = append(result, BigKeyRow{keytype, k, int64(v)})
+				case map[string]interface{}:
+					result = append(result, BigKeyRows(map[string]interface{}{k: v})...)
+				}
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		if result[i].Size != result[j].Size {
+			return result[i].Size > result[j].Size
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// string-Top10、String-Big-Key-Top10 都转为 string
+func bigKeyType(name string) string {
+	keytype := strings.ToLower(name)
+	keytype = strings.TrimSuffix(keytype, "-top10")
+	keytype = strings.TrimSuffix(keytype, "-big-key")
+	return keytype
+}
+
+func bigKeyRecords(bigrows []BigKeyRow) [][]string {
+	var records [][]string
+	for _, v := range bigrows {
+		records = append(records, []string{v.Type, v.Key, strconv.FormatInt(v.Size, 10)})
+	}
+	return records
+}
+
+// 自建redis的慢日志转为行
+func SlowLogRows(slowlog []redis.SlowLog) []SlowLogRow {
+	var result []SlowLogRow
+	for _, v := range slowlog {
+		result = append(result, SlowLogRow{
+			Source:     "redis",
+			Id:         v.ID,
+			Time:       v.Time.Format("2006-01-02 15:04:05"),
+			DurationUs: v.Duration.Microseconds(),
+			Command:    strings.Join(v.Args, " "),
+			Client:     v.ClientAddr,
+		})
+	}
+	return result
+}
+
+// 腾讯云的慢日志转为行，耗时单位是毫秒
+func txSlowLogRows(report map[string]interface{}) ([]SlowLogRow, bool) {
+	var result []SlowLogRow
+	var found bool
+	if proxylog, ok := report["proxy_slowlog"].([]model.TxProxySlowKeyResponseInstanceProxySlowLogDetail); ok {
+		found = true
+		for i, v := range proxylog {
+			result = append(result, SlowLogRow{"proxy", int64(i), v.ExecuteTime, int64(v.Duration) * 1000, v.CommandLine, v.Client})
+		}
+	}
+	if redislog, ok := report["redis_slowlog"].([]model.TxRedisSlowKeyResponseInstanceSlowlogDetail); ok {
+		found = true
+		for i, v := range redislog {
+			result = append(result, SlowLogRow{"redis-" + v.Node, int64(i), v.ExecuteTime, int64(v.Duration) * 1000, v.CommandLine, v.Client})
+		}
+	}
+	return result, found
+}
+
+func slowLogRecords(slowrows []SlowLogRow) [][]string {
+	var records [][]string
+	for _, v := range slowrows {
+		records = append(records, []string{v.Source, strconv.FormatInt(v.Id, 10), v.Time, strconv.FormatInt(v.DurationUs, 10), v.Command, v.Client})
+	}
+	return records
+}
+
+func commandStatRecords(stats []CommandStat) [][]string {
+	var records [][]string
+	for _, v := range stats {
+		records = append(records, []string{
+			v.Command,
+			strconv.FormatInt(v.Calls, 10),
+			strconv.FormatInt(v.Usec, 10),
+			strconv.FormatFloat(v.UsecPerCall, 'f', 2, 64),
+			strconv.FormatInt(v.Rejected, 10),
+			strconv.FormatInt(v.Failed, 10),
+		})
+	}
+	return records
+}
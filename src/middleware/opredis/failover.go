@@ -0,0 +1,95 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+)
+
+var (
+	ErrUnknownNode         = errors.New("unknown cluster node")
+	ErrInvalidFailoverMode = errors.New("failover mode should be empty, force or takeover")
+)
+
+// CLUSTER FAILOVER 之后等待拓扑稳定的默认时间
+const failoverSettle = 3 * time.Second
+
+type ClusterFailoverResult struct {
+	NodeId      string   `json:"node_id"`
+	Addr        string   `json:"addr"`
+	Mode        string   `json:"mode"`
+	Role        string   `json:"role"`  // 切换后节点的角色，成功时为 master
+	Nodes       []string `json:"nodes"` // 切换后 cluster nodes 的结果
+	ClusterInfo string   `json:"cluster_info"`
+}
+
+// 在从节点上执行 CLUSTER FAILOVER，mode 为空、force 或 takeover
+// 执行后等待 settle 再读取 cluster nodes，settle 小于等于0时使用默认值
+func ClusterFailover(ctx context.Context, nodeid, mode string, settle time.Duration) (ClusterFailoverResult, error) {
+	mode = strings.ToLower(mode)
+	result := ClusterFailoverResult{NodeId: nodeid, Mode: mode}
+	if mode != "" && mode != "force" && mode != "takeover" {
+		return result, NewOpError(nodeid, "cluster failover", ErrInvalidFailoverMode)
+	}
+	node, ok := mysql.DB.GetClusterNodeInfo(nodeid)
+	if !ok {
+		return result, NewOpError(nodeid, "cluster failover", ErrUnknownNode)
+	}
+	result.Addr = net.JoinHostPort(node.Ip, node.Port)
+	rd, err := newCallerClient(ctx, FleetTarget{
+		Type:     "cluster",
+		Id:       nodeid,
+		Addr:     result.Addr,
+		Password: mysql.DB.GetClusterPassword(strconv.Itoa(node.CluserId)),
+	})
+	if err != nil {
+		return result, NewOpError(nodeid, "cluster failover", err)
+	}
+	defer ReleaseClient(rd)
+	val, err := rd.Info(ctx, "replication").Result()
+	if err != nil {
+		return result, NewOpError(nodeid, "cluster failover", err)
+	}
+	if ParseInfo(val)["role"] != "slave" {
+		return result, NewOpError(nodeid, "cluster failover", ErrNotReplica)
+	}
+	args := []interface{}{"cluster", "failover"}
+	if mode != "" {
+		args = append(args, mode)
+	}
+	if err := rd.Do(ctx, args...).Err(); err != nil {
+		logger.Error("Redis Cluster Failover ", result.Addr, " Error: ", err)
+		return result, NewOpError(nodeid, "cluster failover", err)
+	}
+	logger.Warn("节点 ", nodeid, "(", result.Addr, ") 执行 cluster failover ", mode)
+	if settle <= 0 {
+		settle = failoverSettle
+	}
+	select {
+	case <-ctx.Done():
+		return result, NewOpError(nodeid, "cluster failover", ctx.Err())
+	case <-time.After(settle):
+	}
+	if val, err = rd.Info(ctx, "replication").Result(); err == nil {
+		result.Role = ParseInfo(val)["role"]
+	}
+	nodes, err := rd.ClusterNodes(ctx).Result()
+	if err != nil {
+		return result, NewOpError(nodeid, "cluster failover", err)
+	}
+	for _, line := range strings.Split(nodes, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result.Nodes = append(result.Nodes, line)
+		}
+	}
+	if result.ClusterInfo, err = rd.ClusterInfo(ctx).Result(); err != nil {
+		return result, NewOpError(nodeid, "cluster failover", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package opredis
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 测试用的最小 redis 服务端，只实现用到的命令，记录收到的命令
+type fakeServer struct {
+	listener net.Listener
+	version  string
+	types    map[string]string
+
+	lock     sync.Mutex
+	commands [][]string
+}
+
+func newFakeServer(t *testing.T, version string, types map[string]string) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{listener: listener, version: version, types: types}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// 收到过的 name 命令
+func (s *fakeServer) Received(name string) [][]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var result [][]string
+	for _, v := range s.commands {
+		if strings.EqualFold(v[0], name) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.lock.Lock()
+		s.commands = append(s.commands, args)
+		s.lock.Unlock()
+		io.WriteString(conn, s.reply(args))
+	}
+}
+
+func (s *fakeServer) reply(args []string) string {
+	switch strings.ToLower(args[0]) {
+	case "ping":
+		return "+PONG\r\n"
+	case "info":
+		return bulk("# Server\r\nredis_version:" + s.version + "\r\n")
+	case "type":
+		if t, ok := s.types[args[1]]; ok {
+			return "+" + t + "\r\n"
+		}
+		return "+none\r\n"
+	case "scan":
+		typefilter := ""
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.EqualFold(args[i], "type") {
+				typefilter = args[i+1]
+			}
+		}
+		var keys []string
+		for k, t := range s.types {
+			if typefilter == "" || t == typefilter {
+				keys = append(keys, k)
+			}
+		}
+		reply := "*2\r\n" + bulk("0") + "*" + strconv.Itoa(len(keys)) + "\r\n"
+		for _, k := range keys {
+			reply += bulk(k)
+		}
+		return reply
+	}
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
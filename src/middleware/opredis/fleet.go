@@ -0,0 +1,233 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+)
+
+var ErrFleetTargetNotFound = errors.New("instance not found in fleet")
+
+// 同时检查的实例个数
+const fleetConcurrency = 20
+
+const (
+	INSTANCEOK          = "ok"
+	INSTANCEUNREACHABLE = "unreachable"
+)
+
+// 需要汇总的实例
+type FleetTarget struct {
+	Type     string
+	Id       string
+	Name     string
+	Addr     string
+	Password string
+}
+
+// 数据库里所有的云redis实例和自建cluster节点
+func FleetTargets() []FleetTarget {
+	var targets []FleetTarget
+	for _, v := range mysql.DB.GetAllCloudredis() {
+		targets = append(targets, FleetTarget{
+			Type:     v.Cloud,
+			Id:       v.InstanceId,
+			Name:     v.InstanceName,
+			Addr:     net.JoinHostPort(v.PrivateIp, strconv.Itoa(v.Port)),
+			Password: v.Password,
+		})
+	}
+	for _, v := range mysql.DB.GetAllCluster() {
+		clusterid := strconv.Itoa(v.ID)
+		for _, node := range mysql.DB.GetClusterNode(clusterid) {
+			targets = append(targets, FleetTarget{
+				Type:     "cluster",
+				Id:       node.NodeId,
+				Name:     v.Name,
+				Addr:     net.JoinHostPort(node.Ip, node.Port),
+				Password: v.Password,
+			})
+		}
+	}
+	return targets
+}
+
+// 通过实例ID获取链接，云redis为实例ID，自建cluster为节点ID，用完后调用 ReleaseClient
+func FleetClient(instanceid string) (*redis.Client, error) {
+	for _, v := range FleetTargets() {
+		if v.Id == instanceid {
+			return newTargetClient(v)
+		}
+	}
+	return nil, ErrFleetTargetNotFound
+}
+
+// 给 opredis 外面的定时任务使用，readonly 时安装只读 hook，用完后调用 ReleaseClient
+func TargetClient(target FleetTarget, readonly bool) (*redis.Client, error) {
+	return targetClient(target, readonly)
+}
+
+// 单独建立链接，不影响全局的 RD，相同地址和认证的链接共用，用完后调用 ReleaseClient
+func newTargetClient(target FleetTarget) (*redis.Client, error) {
+	return targetClient(target, false)
+}
+
+// 用户发起的操作按 ctx 决定是否只读
+func newCallerClient(ctx context.Context, target FleetTarget) (*redis.Client, error) {
+	return targetClient(target, IsReadOnlyContext(ctx))
+}
+
+func targetClient(target FleetTarget, readonly bool) (*redis.Client, error) {
+	opt, err := RedisOptions(target.Addr, target.Password, 0)
+	if err != nil {
+		return nil, err
+	}
+	timeout := DefaultTimeout()
+	opt.DialTimeout = timeout
+	opt.ReadTimeout = timeout
+	opt.WriteTimeout = timeout
+	return acquireClient(opt, readonly), nil
+}
+
+// 并发获取所有实例的概要信息，连不上或者超时的实例也会返回，status 为 unreachable
+func FleetSummary(ctx context.Context, targets []FleetTarget) model.FleetSummary {
+	result := model.FleetSummary{
+		Total:     len(targets),
+		Instances: make([]model.InstanceSummary, len(targets)),
+	}
+	group := GroupExec(ctx, targets, 2*DefaultTimeout(), func(ctx context.Context, target FleetTarget) (interface{}, error) {
+		return InstanceSummary(ctx, target), nil
+	})
+	for i, target := range targets {
+		if v, ok := group.Results[target.Id].(model.InstanceSummary); ok {
+			result.Instances[i] = v
+			continue
+		}
+		result.Instances[i] = model.InstanceSummary{
+			Type:   target.Type,
+			Id:     target.Id,
+			Name:   target.Name,
+			Addr:   target.Addr,
+			Status: INSTANCEUNREACHABLE,
+		}
+		if err, ok := group.Errors[target.Id]; ok {
+			result.Instances[i].Error = err.Error()
+		}
+	}
+	for _, v := range result.Instances {
+		if v.Reachable {
+			result.Reachable++
+		} else {
+			result.Unreachable++
+		}
+	}
+	return result
+}
+
+// 获取单个实例的概要信息
+func InstanceSummary(ctx context.Context, target FleetTarget) model.InstanceSummary {
+	summary := model.InstanceSummary{
+		Type:        target.Type,
+		Id:          target.Id,
+		Name:        target.Name,
+		Addr:        target.Addr,
+		Status:      INSTANCEUNREACHABLE,
+		Breaker:     GetBreaker(EndpointAddr(target.Addr)).State(),
+		InFlightOps: InFlightOps(target.Addr),
+	}
+	rd, err := newTargetClient(target)
+	if err != nil {
+		logger.Error("Redis Fleet Info ", target.Addr, " Error: ", err)
+		summary.Error = err.Error()
+		return summary
+	}
+	defer ReleaseClient(rd)
+	infoctx, cancel := context.WithTimeout(ctx, DefaultTimeout())
+	defer cancel()
+	val, err := rd.Info(infoctx).Result()
+	if err != nil {
+		logger.Error("Redis Fleet Info ", target.Addr, " Error: ", err)
+		err = MapAuthError(err)
+		summary.Status = authReason(err, INSTANCEUNREACHABLE)
+		summary.Error = err.Error()
+		return summary
+	}
+	info := ParseInfo(val)
+	summary.Reachable = true
+	summary.Status = INSTANCEOK
+	summary.Role = info["role"]
+	summary.Version = info["redis_version"]
+	summary.Capabilities = VersionCapabilities(summary.Version)
+	summary.UsedMemory = infoInt(info, "used_memory")
+	summary.MaxMemory = infoInt(info, "maxmemory")
+	if summary.MaxMemory > 0 {
+		summary.MemoryPercent = float64(summary.UsedMemory) * 100 / float64(summary.MaxMemory)
+	}
+	summary.OpsPerSec = infoInt(info, "instantaneous_ops_per_sec")
+	hits := infoInt(info, "keyspace_hits")
+	misses := infoInt(info, "keyspace_misses")
+	if hits+misses > 0 {
+		summary.HitRatio = float64(hits) / float64(hits+misses)
+	}
+	summary.Clients = infoInt(info, "connected_clients")
+	summary.Keys = keyspaceKeys(info)
+	summary.ReplLag = replLag(info)
+	if lastsave := infoInt(info, "rdb_last_save_time"); lastsave > 0 {
+		summary.LastSaveAge = time.Now().Unix() - lastsave
+	}
+	return summary
+}
+
+func infoInt(info map[string]string, name string) int64 {
+	val, err := strconv.ParseInt(info[name], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return val
+}
+
+// keyspace 里每个db的格式为 keys=1,expires=0,avg_ttl=0
+func keyspaceKeys(info map[string]string) int64 {
+	var keys int64
+	for k, v := range info {
+		if !strings.HasPrefix(k, "db") || !strings.HasPrefix(v, "keys=") {
+			continue
+		}
+		field := strings.SplitN(strings.TrimPrefix(v, "keys="), ",", 2)[0]
+		if n, err := strconv.ParseInt(field, 10, 64); err == nil {
+			keys += n
+		}
+	}
+	return keys
+}
+
+// slave 取 master_last_io_seconds_ago，master 取所有 slave 里最大的 lag
+func replLag(info map[string]string) int64 {
+	if info["role"] == "slave" {
+		return infoInt(info, "master_last_io_seconds_ago")
+	}
+	var lag int64
+	for k, v := range info {
+		if !strings.HasPrefix(k, "slave") || !strings.Contains(v, "lag=") {
+			continue
+		}
+		for _, field := range strings.Split(v, ",") {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 || kv[0] != "lag" {
+				continue
+			}
+			if l, err := strconv.ParseInt(kv[1], 10, 64); err == nil && l > lag {
+				lag = l
+			}
+		}
+	}
+	return lag
+}
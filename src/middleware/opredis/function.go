@@ -0,0 +1,142 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+var ErrFunctionUnsupported = errors.New("redis functions require redis 7.0 or later")
+
+type FunctionInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Flags       []string `json:"flags"`
+}
+
+// FUNCTION LIST 返回的函数库
+type FunctionLib struct {
+	Name      string         `json:"library_name"`
+	Engine    string         `json:"engine"`
+	Functions []FunctionInfo `json:"functions"`
+}
+
+// FUNCTION STATS 里的引擎统计和 INFO memory 里函数占用的内存
+type FunctionStats struct {
+	Libraries      int64  `json:"libraries"`
+	Functions      int64  `json:"functions"`
+	RunningScript  string `json:"running_script"`  // 正在执行的函数名，没有为空
+	MemoryEngine   int64  `json:"memory_engine"`   // used_memory_vm_functions，脚本引擎占用的内存
+	MemoryOverhead int64  `json:"memory_overhead"` // used_memory_functions，函数库元数据占用的内存
+}
+
+func checkFunction() error {
+	if caps, ok := ServerCapabilities(); ok && !caps.Function {
+		return ErrFunctionUnsupported
+	}
+	return nil
+}
+
+// 列出实例上的函数库
+func ListFunctions(ctx context.Context, serverip string) ([]FunctionLib, error) {
+	if err := checkFunction(); err != nil {
+		return nil, NewOpError(serverip, "function list", err)
+	}
+	val, err := RD.Do(ctx, "function", "list").Result()
+	if err != nil {
+		logger.Error("Redis Function List Error: ", err)
+		return nil, NewOpError(serverip, "function list", err)
+	}
+	var result []FunctionLib
+	libs, _ := val.([]interface{})
+	for _, v := range libs {
+		lib := memoryStatsMap(v)
+		item := FunctionLib{Name: fmt.Sprint(lib["library_name"]), Engine: fmt.Sprint(lib["engine"])}
+		functions, _ := lib["functions"].([]interface{})
+		for _, f := range functions {
+			fn := memoryStatsMap(f)
+			info := FunctionInfo{Name: fmt.Sprint(fn["name"])}
+			if fn["description"] != nil {
+				info.Description = fmt.Sprint(fn["description"])
+			}
+			flags, _ := fn["flags"].([]interface{})
+			for _, flag := range flags {
+				info.Flags = append(info.Flags, fmt.Sprint(flag))
+			}
+			item.Functions = append(item.Functions, info)
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// 函数的数量和内存占用
+func GetFunctionStats(ctx context.Context, serverip string) (FunctionStats, error) {
+	var result FunctionStats
+	if err := checkFunction(); err != nil {
+		return result, NewOpError(serverip, "function stats", err)
+	}
+	val, err := RD.Do(ctx, "function", "stats").Result()
+	if err != nil {
+		logger.Error("Redis Function Stats Error: ", err)
+		return result, NewOpError(serverip, "function stats", err)
+	}
+	stats := memoryStatsMap(val)
+	if running := memoryStatsMap(stats["running_script"]); len(running) > 0 {
+		result.RunningScript = fmt.Sprint(running["name"])
+	}
+	for _, engine := range memoryStatsMap(stats["engines"]) {
+		counts := memoryStatsMap(engine)
+		result.Libraries += statInt(counts["libraries_count"])
+		result.Functions += statInt(counts["functions_count"])
+	}
+	info, err := RD.Info(ctx, "memory").Result()
+	if err != nil {
+		logger.Error("Redis Info memory Error: ", err)
+		return result, NewOpError(serverip, "function stats", err)
+	}
+	memory := ParseInfo(info)
+	result.MemoryEngine = infoInt(memory, "used_memory_vm_functions")
+	result.MemoryOverhead = infoInt(memory, "used_memory_functions")
+	return result, nil
+}
+
+// 加载函数库，replace 为 true 时覆盖同名的库，返回库名
+func LoadFunction(code string, replace bool) (string, error) {
+	if err := RD.CheckCommand("function", "load"); err != nil {
+		return "", err
+	}
+	if err := checkFunction(); err != nil {
+		return "", err
+	}
+	args := []interface{}{"function", "load"}
+	if replace {
+		args = append(args, "replace")
+	}
+	args = append(args, code)
+	name, err := RD.Do(ctx, args...).Text()
+	if err != nil {
+		logger.Error("Redis Function Load Error: ", err)
+		return "", err
+	}
+	logger.Warn("Redis Function Load: ", name, " replace: ", replace)
+	return name, nil
+}
+
+// 删除函数库
+func DeleteFunction(name string) error {
+	if err := RD.CheckCommand("function", "delete"); err != nil {
+		return err
+	}
+	if err := checkFunction(); err != nil {
+		return err
+	}
+	if err := RD.Do(ctx, "function", "delete", name).Err(); err != nil {
+		logger.Error("Redis Function Delete ", name, " Error: ", err)
+		return err
+	}
+	logger.Warn("Redis Function Delete: ", name)
+	return nil
+}
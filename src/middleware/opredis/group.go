@@ -0,0 +1,95 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/tools"
+)
+
+// 批量操作的结果，完成的实例在 Results 里，失败或者超时的在 Errors 里，角色不符合的在 Skipped 里
+type GroupResult struct {
+	Results map[string]interface{}
+	Errors  map[string]error
+	Skipped map[string]string
+}
+
+// 并发对所有实例执行 fn，每个实例最多执行 timeout，超时的实例记为 context.DeadlineExceeded
+// ctx 结束后还没开始的实例直接记为 ctx.Err()，已经完成的结果照常返回
+func GroupExec(ctx context.Context, targets []FleetTarget, timeout time.Duration, fn func(context.Context, FleetTarget) (interface{}, error)) GroupResult {
+	return GroupExecRole(ctx, targets, ROLEANY, timeout, fn)
+}
+
+// 和 GroupExec 一样，执行 fn 前先通过 INFO replication 确认角色，不符合 filter 的实例跳过并记录原因
+func GroupExecRole(ctx context.Context, targets []FleetTarget, filter RoleFilter, timeout time.Duration, fn func(context.Context, FleetTarget) (interface{}, error)) GroupResult {
+	result := GroupResult{
+		Results: make(map[string]interface{}),
+		Errors:  make(map[string]error),
+		Skipped: make(map[string]string),
+	}
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+	)
+	record := func(id string, value interface{}, err error) {
+		lock.Lock()
+		var skip *RoleSkip
+		if errors.As(err, &skip) {
+			result.Skipped[id] = skip.Error()
+		} else if err != nil {
+			result.Errors[id] = NewOpError(id, "group", err)
+		} else {
+			result.Results[id] = value
+		}
+		lock.Unlock()
+	}
+	sem := make(chan struct{}, fleetConcurrency)
+	for _, target := range targets {
+		target := target
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			record(target.Id, nil, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		tools.SafeGo("groupexec", func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			value, err := execWithTimeout(ctx, target, timeout, func(ctx context.Context, target FleetTarget) (interface{}, error) {
+				if err := checkRole(ctx, target, filter); err != nil {
+					return nil, err
+				}
+				return fn(ctx, target)
+			})
+			record(target.Id, value, err)
+		})
+	}
+	wg.Wait()
+	return result
+}
+
+// fn 不响应ctx时也不会阻塞整个批次，超时后直接返回，fn 的结果丢弃
+func execWithTimeout(ctx context.Context, target FleetTarget, timeout time.Duration, fn func(context.Context, FleetTarget) (interface{}, error)) (interface{}, error) {
+	instancectx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type execResult struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan execResult, 1)
+	tools.SafeGo("groupexecone", func() {
+		value, err := fn(instancectx, target)
+		done <- execResult{value, err}
+	})
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-instancectx.Done():
+		return nil, instancectx.Err()
+	}
+}
@@ -0,0 +1,150 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/alert"
+	"github.com/iguidao/redis-manager/src/middleware/events"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/metrics"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
+)
+
+const (
+	REASONOK           = "ok"
+	REASONPINGERROR    = "ping_error"
+	REASONAUTHREQUIRED = "auth_required"
+	REASONAUTHFAILURE  = "auth_failure"
+	REASONCIRCUITOPEN  = "circuit_open"
+)
+
+// 实例健康状态变化的事件
+type StateEvent struct {
+	Id      string    `json:"id"`
+	Name    string    `json:"name"`
+	Addr    string    `json:"addr"`
+	Healthy bool      `json:"healthy"`
+	Reason  string    `json:"reason"` // ok、ping_error、auth_required、auth_failure、circuit_open
+	Error   string    `json:"error"`
+	Time    time.Time `json:"time"`
+}
+
+var (
+	stateLock      sync.Mutex
+	instanceStates = make(map[string]bool)
+	stateListeners []func(StateEvent)
+)
+
+// 注册实例健康状态变化的回调，只在状态切换的时候触发，回调在单独的goroutine里执行
+func OnStateChange(fn func(StateEvent)) {
+	stateLock.Lock()
+	stateListeners = append(stateListeners, fn)
+	stateLock.Unlock()
+}
+
+// 检查所有实例，状态发生变化的时候通知回调，返回所有不健康实例的错误
+func CheckInstanceHealth(ctx context.Context, targets []FleetTarget) []*OpError {
+	var (
+		wg      sync.WaitGroup
+		errLock sync.Mutex
+		errs    []*OpError
+	)
+	sem := make(chan struct{}, fleetConcurrency)
+	for _, target := range targets {
+		target := target
+		wg.Add(1)
+		sem <- struct{}{}
+		tools.SafeGo("instancehealth", func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			event, err := pingTarget(ctx, target)
+			updateState(event)
+			if err != nil {
+				errLock.Lock()
+				errs = append(errs, &OpError{InstanceID: target.Id, Op: "ping", Err: err})
+				errLock.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return errs
+}
+
+func pingTarget(ctx context.Context, target FleetTarget) (StateEvent, error) {
+	event := StateEvent{
+		Id:      target.Id,
+		Name:    target.Name,
+		Addr:    target.Addr,
+		Healthy: true,
+		Reason:  REASONOK,
+		Time:    time.Now(),
+	}
+	breaker := GetBreaker(EndpointAddr(target.Addr))
+	if err := breaker.Allow(); err != nil {
+		event.Healthy = false
+		event.Reason = REASONCIRCUITOPEN
+		event.Error = err.Error()
+		return event, err
+	}
+	err := ProbeTarget(ctx, target)
+	breaker.Record(err)
+	if err != nil {
+		event.Healthy = false
+		event.Reason = authReason(err, REASONPINGERROR)
+		event.Error = err.Error()
+	}
+	return event, err
+}
+
+// ping 实例，认证失败时返回 ErrAuthRequired 或 ErrAuthFailed
+func ProbeTarget(ctx context.Context, target FleetTarget) error {
+	rd, err := newTargetClient(target)
+	if err != nil {
+		return err
+	}
+	defer ReleaseClient(rd)
+	pingctx, cancel := context.WithTimeout(ctx, DefaultTimeout())
+	defer cancel()
+	return MapAuthError(rd.Ping(pingctx).Err())
+}
+
+// 认证错误返回对应的原因，其他错误返回 other
+func authReason(err error, other string) string {
+	switch {
+	case errors.Is(err, ErrAuthRequired):
+		return REASONAUTHREQUIRED
+	case errors.Is(err, ErrAuthFailed):
+		return REASONAUTHFAILURE
+	}
+	return other
+}
+
+// 第一次检查只记录状态，之后状态变化时通知回调
+func updateState(event StateEvent) {
+	healthy := 0.0
+	if event.Healthy {
+		healthy = 1
+	}
+	metrics.Set("redis_manager_instance_healthy", healthy, map[string]string{"instance": event.Id})
+	alert.Check("instance_down", event.Id, !event.Healthy, healthy, 1, event.Addr+" "+event.Reason+" "+event.Error)
+	stateLock.Lock()
+	last, ok := instanceStates[event.Id]
+	instanceStates[event.Id] = event.Healthy
+	listeners := stateListeners
+	stateLock.Unlock()
+	if !ok || last == event.Healthy {
+		return
+	}
+	logger.Warn("实例 ", event.Id, "(", event.Addr, ") 状态变化，healthy: ", event.Healthy, " reason: ", event.Reason, " ", event.Error)
+	events.Publish(events.STATECHANGE, event.Id, map[string]string{"addr": event.Addr, "healthy": strconv.FormatBool(event.Healthy), "reason": event.Reason, "error": event.Error})
+	for _, fn := range listeners {
+		fn := fn
+		tools.SafeGo("statechange", func() { fn(event) })
+	}
+}
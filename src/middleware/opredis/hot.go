@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
 )
 
 func HotKey(serverip, pw string) map[string]int {
@@ -18,10 +19,10 @@ func HotKey(serverip, pw string) map[string]int {
 	ch := make(chan string)
 	timeout, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	go func() {
+	tools.SafeGo("hotkeymonitor", func() {
 		monitor, knowtime = TelnetCommond(serverip, "monitor", pw)
 		ch <- "done"
-	}()
+	})
 
 	select {
 	case res := <-ch:
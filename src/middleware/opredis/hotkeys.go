@@ -0,0 +1,117 @@
+package opredis
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+
+	"github.com/go-redis/redis/v9"
+)
+
+const (
+	HOTKEYMONITOR = "monitor"  // 非LFU策略时通过 MONITOR 采样统计访问次数
+	HOTKEYFREQ    = "lfu-freq" // LFU策略时通过 OBJECT FREQ 读取访问频率
+)
+
+// 最多返回的热key个数
+const hotKeyLimit = 50
+
+// 不带key的命令，MONITOR 统计时跳过
+var keylessCommands = map[string]bool{
+	"ping": true, "info": true, "select": true, "auth": true, "client": true, "config": true,
+	"dbsize": true, "time": true, "command": true, "slowlog": true, "cluster": true, "monitor": true,
+	"scan": true, "multi": true, "exec": true, "discard": true, "hello": true, "echo": true,
+}
+
+type HotKeyStat struct {
+	Key    string `json:"key"`
+	Count  int64  `json:"count"`  // monitor 时为采样期间的访问次数，lfu-freq 时为 OBJECT FREQ 的值
+	Method string `json:"method"` // monitor 或 lfu-freq
+}
+
+// 找出访问最多的key
+// maxmemory-policy 是 LFU 时扫描key读取 OBJECT FREQ，不影响线上；否则执行 duration 的 MONITOR 采样，最多60秒
+func HotKeys(ctx context.Context, instanceid, serverip string, duration time.Duration) ([]HotKeyStat, error) {
+	policy, ok := GetOneConfig("maxmemory-policy")
+	if !ok {
+		return nil, NewOpError(serverip, "hotkeys", ErrScanFailed)
+	}
+	if IsLfuPolicy(policy) {
+		return hotKeysByFreq(ctx, serverip)
+	}
+	return hotKeysByMonitor(ctx, instanceid, serverip, duration)
+}
+
+func hotKeysByFreq(ctx context.Context, serverip string) ([]HotKeyStat, error) {
+	counts := make(map[string]int64)
+	limiter := GetRateLimiter(serverip)
+	var cursor uint64
+	for fornum := 0; fornum < cfg.Get_Info_Int("allkeyfornum"); fornum++ {
+		keys, next, ok := GetScanKey(cursor, 1000)
+		if !ok {
+			return nil, NewOpError(serverip, "hotkeys", ErrScanFailed)
+		}
+		if err := limiter.Wait(ctx, len(keys)); err != nil {
+			return topHotKeys(counts, HOTKEYFREQ), NewOpError(serverip, "hotkeys", err)
+		}
+		pipe := RD.Pipeline()
+		cmds := make([]*redis.Cmd, len(keys))
+		for i, keyname := range keys {
+			cmds[i] = pipe.Do(ctx, "object", "freq", keyname)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+			return topHotKeys(counts, HOTKEYFREQ), NewOpError(serverip, "hotkeys", err)
+		}
+		for i, cmd := range cmds {
+			freq, err := cmd.Int64()
+			if err != nil {
+				continue
+			}
+			counts[keys[i]] = freq
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return topHotKeys(counts, HOTKEYFREQ), nil
+}
+
+func hotKeysByMonitor(ctx context.Context, instanceid, serverip string, duration time.Duration) ([]HotKeyStat, error) {
+	lines, err := Monitor(ctx, instanceid, duration)
+	if err != nil {
+		return nil, NewOpError(serverip, "hotkeys", err)
+	}
+	counts := make(map[string]int64)
+	total := 0
+	for line := range lines {
+		total++
+		if len(line.Args) == 0 || keylessCommands[strings.ToLower(line.Command)] {
+			continue
+		}
+		counts[line.Args[0]]++
+	}
+	logger.Info("ip: ", serverip, " hotkeys monitor ", total, " commands")
+	return topHotKeys(counts, HOTKEYMONITOR), nil
+}
+
+func topHotKeys(counts map[string]int64, method string) []HotKeyStat {
+	result := make([]HotKeyStat, 0, len(counts))
+	for k, v := range counts {
+		result = append(result, HotKeyStat{Key: k, Count: v, Method: method})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	if len(result) > hotKeyLimit {
+		result = result[:hotKeyLimit]
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+
+	"github.com/go-redis/redis/v9"
+)
+
+// LFU 策略下 redis 不记录空闲时间，OBJECT IDLETIME 会报错
+var ErrIdleTimeNotTracked = errors.New("idle time is not tracked under lfu maxmemory-policy, use hotkeys or cold instead")
+
+// 默认的空闲时间分桶：<1m、1m-1h、1h-1d、1d-7d、>7d
+var DefaultIdleBuckets = []time.Duration{time.Minute, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour}
+
+// 默认采样的key个数
+const idleHistogramSample = 10000
+
+// SCAN 采样 samplesize 个key，按 OBJECT IDLETIME 落到 buckets 划分的区间里计数，用来调整淘汰策略和TTL
+// 扫描受实例的扫描限速控制，ctx 取消时返回已经统计的结果和错误
+func IdleTimeHistogram(ctx context.Context, serverip string, samplesize int, buckets []time.Duration) (map[string]int, error) {
+	policy, ok := GetOneConfig("maxmemory-policy")
+	if !ok {
+		return nil, NewOpError(serverip, "idlehistogram", ErrScanFailed)
+	}
+	if IsLfuPolicy(policy) {
+		return nil, NewOpError(serverip, "idlehistogram", ErrIdleTimeNotTracked)
+	}
+	if samplesize <= 0 {
+		samplesize = idleHistogramSample
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultIdleBuckets
+	}
+	buckets = append([]time.Duration(nil), buckets...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	labels := ttlBucketLabels(buckets)
+	result := make(map[string]int, len(labels))
+	for _, label := range labels {
+		result[label] = 0
+	}
+	limiter := GetRateLimiter(serverip)
+	var cursor uint64
+	total := 0
+	for {
+		keys, next, err := RD.Scan(ctx, cursor, "*", 1000).Result()
+		if err != nil {
+			logger.Error("Redis Scan Error: ", err)
+			return result, NewOpError(serverip, "idlehistogram", err)
+		}
+		if len(keys) > samplesize-total {
+			keys = keys[:samplesize-total]
+		}
+		if err := limiter.Wait(ctx, len(keys)); err != nil {
+			return result, NewOpError(serverip, "idlehistogram", err)
+		}
+		pipe := RD.Pipeline()
+		cmds := make([]*redis.DurationCmd, 0, len(keys))
+		for _, keyname := range keys {
+			cmds = append(cmds, pipe.ObjectIdleTime(ctx, keyname))
+		}
+		if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+			return result, NewOpError(serverip, "idlehistogram", err)
+		}
+		for _, cmd := range cmds {
+			// key 已经不存在时返回 nil
+			idle, err := cmd.Result()
+			if err != nil {
+				continue
+			}
+			total++
+			result[labels[ttlBucket(buckets, idle)]]++
+		}
+		cursor = next
+		if cursor == 0 || total >= samplesize {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package opredis
+
+import (
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 获取 info 信息并解析成map，section为空时获取默认信息
+func GetInfo(section ...string) (map[string]string, bool) {
+	val, err := RD.Info(ctx, section...).Result()
+	if err != nil {
+		logger.Error("Redis Info ", section, " Error: ", err)
+		return nil, false
+	}
+	return ParseInfo(val), true
+}
+
+func ParseInfo(info string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) == 2 {
+			result[kv[0]] = kv[1]
+		}
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package opredis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// info 快照，修改前后各取一次用于对比
+type InfoStats struct {
+	Addr   string            `json:"addr"`
+	Time   time.Time         `json:"time"`
+	Fields map[string]string `json:"fields"`
+}
+
+type InfoChange struct {
+	Field  string  `json:"field"`
+	Before string  `json:"before"`
+	After  string  `json:"after"`
+	Delta  float64 `json:"delta"` // 非数值字段为0
+}
+
+type InfoDiff struct {
+	Addr       string       `json:"addr"`
+	Seconds    float64      `json:"seconds"`
+	Changes    []InfoChange `json:"changes"`
+	Highlights []string     `json:"highlights"`
+}
+
+// 每次都会变化的字段，不参与对比
+var infoDiffIgnore = []string{
+	"uptime_in_seconds", "uptime_in_days", "lru_clock", "server_time_usec", "hz",
+	"used_cpu_sys", "used_cpu_user", "used_cpu_sys_children", "used_cpu_user_children",
+	"used_cpu_sys_main_thread", "used_cpu_user_main_thread",
+}
+
+// 需要重点关注的错误类计数
+var infoDiffErrors = []string{
+	"rejected_connections", "total_error_replies", "evicted_keys", "errorstat_",
+	"rdb_last_bgsave_status", "aof_last_bgrewrite_status", "aof_last_write_status",
+}
+
+func CaptureInfo(serverip string) (InfoStats, bool) {
+	info, ok := GetInfo("all")
+	if !ok {
+		return InfoStats{}, false
+	}
+	return InfoStats{Addr: serverip, Time: time.Now(), Fields: info}, true
+}
+
+// 对比两次 info，返回有变化的字段和需要关注的变化
+func DiffInfo(before, after InfoStats) InfoDiff {
+	diff := InfoDiff{Addr: after.Addr}
+	if !before.Time.IsZero() && !after.Time.IsZero() {
+		diff.Seconds = after.Time.Sub(before.Time).Seconds()
+	}
+	var keylist []string
+	for k := range before.Fields {
+		keylist = append(keylist, k)
+	}
+	for k := range after.Fields {
+		if _, ok := before.Fields[k]; !ok {
+			keylist = append(keylist, k)
+		}
+	}
+	sort.Strings(keylist)
+	for _, k := range keylist {
+		if infoIgnored(k) || before.Fields[k] == after.Fields[k] {
+			continue
+		}
+		change := InfoChange{Field: k, Before: before.Fields[k], After: after.Fields[k]}
+		b, berr := strconv.ParseFloat(change.Before, 64)
+		a, aerr := strconv.ParseFloat(change.After, 64)
+		if berr == nil && aerr == nil {
+			change.Delta = a - b
+		}
+		diff.Changes = append(diff.Changes, change)
+		if msg := infoHighlight(change, berr == nil && aerr == nil, b, a); msg != "" {
+			diff.Highlights = append(diff.Highlights, msg)
+		}
+	}
+	return diff
+}
+
+func infoIgnored(field string) bool {
+	for _, v := range infoDiffIgnore {
+		if field == v {
+			return true
+		}
+	}
+	return false
+}
+
+// 内存增长超过10%、连接数增长超过50%、出现新的错误
+func infoHighlight(change InfoChange, numeric bool, before, after float64) string {
+	switch change.Field {
+	case "used_memory":
+		if numeric && before > 0 && (after-before)/before > 0.1 {
+			return fmt.Sprintf("used_memory grew %.1f%% (%s -> %s)", (after-before)/before*100, change.Before, change.After)
+		}
+		return ""
+	case "connected_clients", "blocked_clients":
+		if numeric && after-before > 10 && (before == 0 || (after-before)/before > 0.5) {
+			return fmt.Sprintf("%s spiked %s -> %s", change.Field, change.Before, change.After)
+		}
+		return ""
+	case "role", "maxmemory_policy", "maxmemory":
+		return fmt.Sprintf("%s changed %s -> %s", change.Field, change.Before, change.After)
+	}
+	for _, v := range infoDiffErrors {
+		if !strings.HasPrefix(change.Field, v) {
+			continue
+		}
+		if change.Before == "" {
+			return fmt.Sprintf("new %s: %s", change.Field, change.After)
+		}
+		if !numeric || change.Delta > 0 {
+			return fmt.Sprintf("%s changed %s -> %s", change.Field, change.Before, change.After)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package opredis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 单个key的详细信息，拿不到的字段为 -1
+type KeyDetail struct {
+	Key              string `json:"key"`
+	Exists           bool   `json:"exists"`
+	Type             string `json:"type"`
+	Ttl              int64  `json:"ttl"` // 秒，-1 表示不过期
+	Encoding         string `json:"encoding"`
+	IdleTime         int64  `json:"idletime"` // LRU策略时有效
+	Freq             int64  `json:"freq"`     // LFU策略时有效
+	MemoryUsage      int64  `json:"memory_usage"`
+	SerializedLength int64  `json:"serialized_length"` // DEBUG OBJECT，被禁用时为 -1
+	Elements         int64  `json:"elements"`          // 集合类型的元素个数
+}
+
+// 汇总 TYPE、TTL、OBJECT、MEMORY USAGE、DEBUG OBJECT 和元素个数
+// key 不存在时返回 Exists 为 false
+func GetKeyDetail(keyname string) (KeyDetail, error) {
+	detail := KeyDetail{Key: keyname, Ttl: -1, IdleTime: -1, Freq: -1, MemoryUsage: -1, SerializedLength: -1, Elements: -1}
+	keytype, err := RD.Type(ctx, keyname).Result()
+	if err != nil {
+		logger.Error("Redis Type key: ", keyname, " Error: ", err)
+		return detail, err
+	}
+	if keytype == "none" {
+		return detail, nil
+	}
+	detail.Exists = true
+	detail.Type = keytype
+	if ttl, err := RD.TTL(ctx, keyname).Result(); err == nil && ttl > 0 {
+		detail.Ttl = int64(ttl.Seconds())
+	}
+	if encoding, err := RD.ObjectEncoding(ctx, keyname).Result(); err == nil {
+		detail.Encoding = encoding
+	}
+	// IDLETIME 和 FREQ 只有一个可用，取决于淘汰策略
+	if idle, err := RD.ObjectIdleTime(ctx, keyname).Result(); err == nil {
+		detail.IdleTime = int64(idle.Seconds())
+	} else if freq, err := RD.Do(ctx, "object", "freq", keyname).Int64(); err == nil {
+		detail.Freq = freq
+	}
+	if usage, err := RD.MemoryUsage(ctx, keyname).Result(); err == nil {
+		detail.MemoryUsage = usage
+	}
+	if debug, err := RD.DebugObject(ctx, keyname).Result(); err == nil {
+		detail.SerializedLength = serializedLength(debug)
+	}
+	detail.Elements, err = keyElements(keyname, keytype)
+	if err != nil {
+		logger.Error("Redis Count key: ", keyname, " Error: ", err)
+		detail.Elements = -1
+	}
+	return detail, nil
+}
+
+func keyElements(keyname, keytype string) (int64, error) {
+	switch keytype {
+	case "list":
+		return RD.LLen(ctx, keyname).Result()
+	case "hash":
+		return RD.HLen(ctx, keyname).Result()
+	case "set":
+		return RD.SCard(ctx, keyname).Result()
+	case "zset":
+		return RD.ZCard(ctx, keyname).Result()
+	case "stream":
+		return RD.XLen(ctx, keyname).Result()
+	}
+	return -1, nil
+}
+
+// DEBUG OBJECT 返回: Value at:0x... refcount:1 encoding:raw serializedlength:6 ...
+func serializedLength(debug string) int64 {
+	for _, field := range strings.Fields(debug) {
+		if strings.HasPrefix(field, "serializedlength:") {
+			val, err := strconv.ParseInt(strings.TrimPrefix(field, "serializedlength:"), 10, 64)
+			if err == nil {
+				return val
+			}
+		}
+	}
+	return -1
+}
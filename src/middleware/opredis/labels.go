@@ -0,0 +1,16 @@
+package opredis
+
+import (
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+)
+
+// 读取实例的标签，配置的 key 为 instance_labels:实例ID，格式为 k=v,k=v
+func InstanceLabels(target string) map[string]string {
+	return model.ParseLabels(mysql.DB.GetOneCfgValue(model.INSTANCELABELS + ":" + target))
+}
+
+// 实例的环境标签，为实例标签里的 env
+func InstanceEnv(target string) string {
+	return InstanceLabels(target)["env"]
+}
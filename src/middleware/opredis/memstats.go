@@ -0,0 +1,106 @@
+package opredis
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+const (
+	fragmentationWarn = 1.5
+	clientBufferWarn  = 64 * 1024 * 1024
+)
+
+// MEMORY STATS 拆分后的内存占用，单位字节
+type MemoryBreakdown struct {
+	TotalAllocated     int64    `json:"total_allocated"`
+	PeakAllocated      int64    `json:"peak_allocated"`
+	Dataset            int64    `json:"dataset"`
+	DatasetPercent     float64  `json:"dataset_percent"`
+	Overhead           int64    `json:"overhead"`
+	ClientsNormal      int64    `json:"clients_normal"`  // 普通客户端的输入输出缓冲
+	ClientsReplica     int64    `json:"clients_replica"` // 从库的输出缓冲
+	ReplBacklog        int64    `json:"repl_backlog"`
+	AofBuffer          int64    `json:"aof_buffer"`
+	LuaCaches          int64    `json:"lua_caches"`
+	Keys               int64    `json:"keys"`
+	FragmentationRatio float64  `json:"fragmentation_ratio"`
+	Warnings           []string `json:"warnings"`
+}
+
+// 通过 MEMORY STATS 解释 used_memory 比数据量大的原因
+func MemoryOverhead() (MemoryBreakdown, error) {
+	var result MemoryBreakdown
+	if err := RD.CheckCommand("memory", "stats"); err != nil {
+		return result, err
+	}
+	val, err := RD.Do(ctx, "memory", "stats").Result()
+	if err != nil {
+		logger.Error("Redis Memory Stats Error: ", err)
+		return result, err
+	}
+	stats := memoryStatsMap(val)
+	result.TotalAllocated = statInt(stats["total.allocated"])
+	result.PeakAllocated = statInt(stats["peak.allocated"])
+	result.Dataset = statInt(stats["dataset.bytes"])
+	result.DatasetPercent = statFloat(stats["dataset.percentage"])
+	result.Overhead = statInt(stats["overhead.total"])
+	result.ClientsNormal = statInt(stats["clients.normal"])
+	result.ClientsReplica = statInt(stats["clients.slaves"])
+	result.ReplBacklog = statInt(stats["replication.backlog"])
+	result.AofBuffer = statInt(stats["aof.buffer"])
+	result.LuaCaches = statInt(stats["lua.caches"])
+	result.Keys = statInt(stats["keys.count"])
+	result.FragmentationRatio = statFloat(stats["fragmentation"])
+	if result.FragmentationRatio > fragmentationWarn {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("内存碎片率过高: %.2f", result.FragmentationRatio))
+	}
+	if result.ClientsNormal > clientBufferWarn {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("客户端缓冲占用过大: %d", result.ClientsNormal))
+	}
+	if result.ClientsReplica > clientBufferWarn {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("从库输出缓冲占用过大: %d", result.ClientsReplica))
+	}
+	return result, nil
+}
+
+// RESP2 返回 key value 交替的数组，RESP3 返回map
+func memoryStatsMap(val interface{}) map[string]interface{} {
+	stats := make(map[string]interface{})
+	switch v := val.(type) {
+	case []interface{}:
+		for i := 0; i+1 < len(v); i += 2 {
+			stats[fmt.Sprint(v[i])] = v[i+1]
+		}
+	case map[interface{}]interface{}:
+		for k, value := range v {
+			stats[fmt.Sprint(k)] = value
+		}
+	}
+	return stats
+}
+
+func statInt(val interface{}) int64 {
+	switch v := val.(type) {
+	case int64:
+		return v
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
+func statFloat(val interface{}) float64 {
+	switch v := val.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		n, _ := strconv.ParseFloat(v, 64)
+		return n
+	}
+	return 0
+}
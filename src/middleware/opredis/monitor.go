@@ -0,0 +1,191 @@
+package opredis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
+)
+
+// MONITOR 对性能影响很大，最多执行的时间
+const MonitorMaxDuration = 60 * time.Second
+
+var ErrMonitorFailed = errors.New("monitor command failed")
+
+// MONITOR 输出的一行：1339518083.107412 [0 127.0.0.1:60866] "keys" "*"
+type MonitorLine struct {
+	Time    time.Time `json:"time"`
+	DB      int       `json:"db"`
+	Client  string    `json:"client"`
+	Command string    `json:"command"`
+	Args    []string  `json:"args"`
+}
+
+// 对实例ID（云redis为实例ID，自建cluster为节点ID）执行 MONITOR，duration 后自动停止并关闭channel
+// 超过 MonitorMaxDuration 时按最大值执行，连接方式和其它命令一致，支持 unix socket 和 rediss://
+func Monitor(ctx context.Context, instanceid string, duration time.Duration) (<-chan MonitorLine, error) {
+	if duration <= 0 || duration > MonitorMaxDuration {
+		duration = MonitorMaxDuration
+	}
+	var target FleetTarget
+	found := false
+	for _, v := range FleetTargets() {
+		if v.Id == instanceid {
+			target, found = v, true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrFleetTargetNotFound
+	}
+	opt, err := RedisOptions(target.Addr, target.Password, 0)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := monitorDial(opt)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(DefaultTimeout()))
+	if opt.Password != "" {
+		auth := []string{"AUTH", opt.Password}
+		if opt.Username != "" {
+			auth = []string{"AUTH", opt.Username, opt.Password}
+		}
+		if err := monitorSend(conn, reader, auth...); err != nil {
+			conn.Close()
+			return nil, MapAuthError(err)
+		}
+	}
+	if err := monitorSend(conn, reader, "MONITOR"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	monitorctx, cancel := context.WithTimeout(ctx, duration)
+	conn.SetDeadline(time.Now().Add(duration))
+	ch := make(chan MonitorLine, 100)
+	tools.SafeGo("monitor", func() {
+		<-monitorctx.Done()
+		conn.Close()
+	})
+	tools.SafeGo("monitorread", func() {
+		defer close(ch)
+		defer cancel()
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			entry, ok := ParseMonitorLine(strings.TrimPrefix(strings.TrimSpace(line), "+"))
+			if !ok {
+				continue
+			}
+			select {
+			case ch <- entry:
+			case <-monitorctx.Done():
+				return
+			}
+		}
+	})
+	logger.Warn("instance: ", instanceid, " ip: ", EndpointAddr(target.Addr), " 开始执行 MONITOR，持续 ", duration.String())
+	return ch, nil
+}
+
+// 按连接参数建立连接，rediss:// 使用 TLS
+func monitorDial(opt *redis.Options) (net.Conn, error) {
+	network := opt.Network
+	if network == "" {
+		network = "tcp"
+	}
+	dialer := &net.Dialer{Timeout: DefaultTimeout()}
+	if opt.TLSConfig != nil {
+		return tls.DialWithDialer(dialer, network, opt.Addr, opt.TLSConfig)
+	}
+	return dialer.Dial(network, opt.Addr)
+}
+
+// 发送命令并读取一行回复，回复不是 +OK 时返回错误
+func monitorSend(conn net.Conn, reader *bufio.Reader, args ...string) error {
+	command := "*" + strconv.Itoa(len(args)) + "\r\n"
+	for _, v := range args {
+		command += "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+	}
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return err
+	}
+	reply, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	reply = strings.TrimSpace(reply)
+	if strings.HasPrefix(reply, "-") {
+		return errors.New(strings.TrimPrefix(reply, "-"))
+	}
+	if reply != "+OK" {
+		return ErrMonitorFailed
+	}
+	return nil
+}
+
+func ParseMonitorLine(line string) (MonitorLine, bool) {
+	var entry MonitorLine
+	start := strings.Index(line, " [")
+	end := strings.Index(line, "] ")
+	if start < 0 || end < start {
+		return entry, false
+	}
+	ts, err := strconv.ParseFloat(line[:start], 64)
+	if err != nil {
+		return entry, false
+	}
+	sec := int64(ts)
+	entry.Time = time.Unix(sec, int64((ts-float64(sec))*1e9))
+	client := strings.SplitN(line[start+2:end], " ", 2)
+	entry.DB, _ = strconv.Atoi(client[0])
+	if len(client) == 2 {
+		entry.Client = client[1]
+	}
+	args := monitorArgs(line[end+2:])
+	if len(args) == 0 {
+		return entry, false
+	}
+	entry.Command = strings.ToLower(args[0])
+	entry.Args = args[1:]
+	return entry, true
+}
+
+// 解析 "a" "b c" 这样带引号的参数
+func monitorArgs(s string) []string {
+	var result []string
+	for {
+		s = strings.TrimLeft(s, " ")
+		if !strings.HasPrefix(s, "\"") {
+			return result
+		}
+		i := 1
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			return result
+		}
+		arg, err := strconv.Unquote(s[:i+1])
+		if err != nil {
+			arg = s[1:i]
+		}
+		result = append(result, arg)
+		s = s[i+1:]
+	}
+}
@@ -0,0 +1,48 @@
+package opredis
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+// unix socket 地址按 unix 网络连接，不再固定使用 tcp
+func TestMonitorDialUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "redis.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	accepted := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+		accepted <- err
+	}()
+
+	opt, err := RedisOptions("unix://"+path, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := monitorDial(opt)
+	if err != nil {
+		t.Fatalf("monitorDial(%s) error: %v", opt.Addr, err)
+	}
+	conn.Close()
+	if err := <-accepted; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseMonitorLine(t *testing.T) {
+	entry, ok := ParseMonitorLine(`1339518083.107412 [0 127.0.0.1:60866] "SET" "a b" "1"`)
+	if !ok {
+		t.Fatal("ParseMonitorLine failed")
+	}
+	if entry.Command != "set" || entry.Client != "127.0.0.1:60866" || len(entry.Args) != 2 || entry.Args[0] != "a b" {
+		t.Errorf("got %+v", entry)
+	}
+}
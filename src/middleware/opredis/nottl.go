@@ -0,0 +1,76 @@
+package opredis
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 最多返回的没有过期时间的key
+const noTtlKeyLimit = 100
+
+type NoTtlResult struct {
+	DbSize        int64    `json:"dbsize"`
+	Sampled       int      `json:"sampled"`         // 实际采样的key个数
+	NoTtl         int      `json:"no_ttl"`          // 采样里没有过期时间的key个数
+	Estimated     int64    `json:"estimated"`       // 按 dbsize 放大后的估算值
+	MarginOfError float64  `json:"margin_of_error"` // 95%置信度下占比的最大误差
+	Keys          []string `json:"keys"`            // 采样到的没有过期时间的key，最多100个
+}
+
+// 通过 rd 用 SCAN 采样找出 TTL 为 -1 的key，再按 DBSIZE 放大估算总数
+// 估算是统计值，误差的计算方式和 TypeHistogram 相同
+func KeysWithoutTtl(rd *redis.Client, samplesize int) (NoTtlResult, bool) {
+	var result NoTtlResult
+	dbsize, err := rd.DBSize(ctx).Result()
+	if err != nil {
+		logger.Error("Redis Dbsize Error: ", err)
+		return result, false
+	}
+	result.DbSize = dbsize
+	if dbsize == 0 || samplesize <= 0 {
+		return result, true
+	}
+	var cursor uint64
+	for result.Sampled < samplesize {
+		keylist, next, err := rd.Scan(ctx, cursor, "*", 1000).Result()
+		if err != nil {
+			logger.Error("Redis Get Scan "+strconv.FormatUint(cursor, 10)+"Error: ", err)
+			return result, false
+		}
+		for _, keyname := range keylist {
+			ttl, err := rd.TTL(ctx, keyname).Result()
+			// -2 表示key已经不存在
+			if err != nil || ttl == -2*time.Nanosecond {
+				continue
+			}
+			result.Sampled++
+			if ttl == -1*time.Nanosecond {
+				result.NoTtl++
+				if len(result.Keys) < noTtlKeyLimit {
+					result.Keys = append(result.Keys, keyname)
+				}
+			}
+			if result.Sampled >= samplesize {
+				break
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	if result.Sampled == 0 {
+		return result, true
+	}
+	result.Estimated = int64(math.Round(float64(result.NoTtl) * float64(dbsize) / float64(result.Sampled)))
+	n := float64(result.Sampled)
+	total := float64(dbsize)
+	if n < total && total > 1 {
+		result.MarginOfError = 1.96 * math.Sqrt(0.25/n) * math.Sqrt((total-n)/(total-1))
+	}
+	return result, true
+}
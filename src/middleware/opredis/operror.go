@@ -0,0 +1,27 @@
+package opredis
+
+// 带上实例和操作的错误，批量操作时可以知道是哪个实例失败了
+type OpError struct {
+	InstanceID string
+	Op         string
+	Err        error
+}
+
+func (e *OpError) Error() string {
+	return e.Op + " " + e.InstanceID + ": " + e.Err.Error()
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// err 为 nil 时返回 nil，已经是 OpError 的不重复包装
+func NewOpError(instanceid, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*OpError); ok {
+		return err
+	}
+	return &OpError{InstanceID: instanceid, Op: op, Err: err}
+}
@@ -0,0 +1,71 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+)
+
+var ErrInstanceBusy = errors.New("too many concurrent operations on this instance")
+
+// 按实例限制同时执行的管理操作个数，max 为0时不限制
+type opSemaphore struct {
+	ch chan struct{}
+}
+
+var (
+	semLock sync.Mutex
+	sems    = make(map[string]*opSemaphore)
+)
+
+// 没有的时候按配置文件的 maxconcurrentops 创建
+func getOpSemaphore(addr string) *opSemaphore {
+	semLock.Lock()
+	defer semLock.Unlock()
+	s, ok := sems[addr]
+	if !ok {
+		s = &opSemaphore{}
+		if max := cfg.Get_Info_Int("maxconcurrentops"); max > 0 {
+			s.ch = make(chan struct{}, max)
+		}
+		sems[addr] = s
+	}
+	return s
+}
+
+// 执行操作前申请，用完调用返回的函数释放
+// 满了的时候 opsfailfast 为 true 直接返回 ErrInstanceBusy，否则等待直到 ctx 取消
+func AcquireOp(ctx context.Context, addr string) (func(), error) {
+	s := getOpSemaphore(EndpointAddr(addr))
+	if s.ch == nil {
+		return func() {}, nil
+	}
+	release := func() { <-s.ch }
+	select {
+	case s.ch <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if cfg.Get_Info_Bool("opsfailfast") {
+		return nil, NewOpError(addr, "acquire", ErrInstanceBusy)
+	}
+	select {
+	case s.ch <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, NewOpError(addr, "acquire", ErrInstanceBusy)
+	}
+}
+
+// 实例上正在执行的管理操作个数
+func InFlightOps(addr string) int {
+	semLock.Lock()
+	s, ok := sems[EndpointAddr(addr)]
+	semLock.Unlock()
+	if !ok || s.ch == nil {
+		return 0
+	}
+	return len(s.ch)
+}
@@ -0,0 +1,128 @@
+package opredis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 管理操作会用到的命令，没有指定时按这些检查
+var DefaultRequiredCommands = []string{"info", "config", "scan", "type", "object", "memory", "slowlog", "client", "bgsave", "bgrewriteaof", "monitor", "cluster"}
+
+type PermissionReport struct {
+	User    string   `json:"user"`
+	Acl     bool     `json:"acl"` // redis 6.0 以下没有 ACL，只要认证通过所有命令都可以执行
+	Allowed []string `json:"allowed"`
+	Missing []string `json:"missing"`
+}
+
+// 通过 ACL WHOAMI、ACL GETUSER 确认当前用户能执行 required 里的命令，避免长任务执行到一半遇到 NOPERM
+// 命令可以带子命令，例如 config|set，只检查命令权限，不检查key和channel的权限
+func CheckPermissions(ctx context.Context, serverip string, required []string) (PermissionReport, error) {
+	if len(required) == 0 {
+		required = DefaultRequiredCommands
+	}
+	var report PermissionReport
+	user, err := RD.Do(ctx, "acl", "whoami").Text()
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "unknown") {
+			report.Allowed = required
+			return report, nil
+		}
+		logger.Error("Redis ACL WHOAMI ", serverip, " Error: ", err)
+		return report, NewOpError(serverip, "permissions", MapAuthError(err))
+	}
+	report.User, report.Acl = user, true
+	val, err := RD.Do(ctx, "acl", "getuser", user).Result()
+	if err != nil {
+		logger.Error("Redis ACL GETUSER ", serverip, " Error: ", err)
+		return report, NewOpError(serverip, "permissions", err)
+	}
+	rules := strings.Fields(aclField(val, "commands"))
+	categories := make(map[string]map[string]bool)
+	for _, command := range required {
+		command = strings.ToLower(command)
+		allowed, err := commandAllowed(ctx, rules, command, categories)
+		if err != nil {
+			logger.Error("Redis ACL CAT ", serverip, " Error: ", err)
+			return report, NewOpError(serverip, "permissions", err)
+		}
+		if allowed {
+			report.Allowed = append(report.Allowed, command)
+		} else {
+			report.Missing = append(report.Missing, command)
+		}
+	}
+	return report, nil
+}
+
+// ACL GETUSER 在 RESP2 下返回 key value 交替的数组，RESP3 下返回map
+func aclField(val interface{}, name string) string {
+	switch v := val.(type) {
+	case []interface{}:
+		for i := 0; i+1 < len(v); i += 2 {
+			if fmt.Sprint(v[i]) == name {
+				return fmt.Sprint(v[i+1])
+			}
+		}
+	case map[interface{}]interface{}:
+		if field, ok := v[name]; ok {
+			return fmt.Sprint(field)
+		}
+	}
+	return ""
+}
+
+// 按顺序应用规则，后面的覆盖前面的，例如 +@all -debug
+func commandAllowed(ctx context.Context, rules []string, command string, categories map[string]map[string]bool) (bool, error) {
+	base := strings.SplitN(command, "|", 2)[0]
+	allowed := false
+	for _, rule := range rules {
+		switch rule {
+		case "allcommands":
+			rule = "+@all"
+		case "nocommands":
+			rule = "-@all"
+		}
+		if len(rule) < 2 || (rule[0] != '+' && rule[0] != '-') {
+			continue
+		}
+		name := strings.ToLower(rule[1:])
+		match := false
+		switch {
+		case name == "@all":
+			match = true
+		case strings.HasPrefix(name, "@"):
+			members, err := aclCategory(ctx, name[1:], categories)
+			if err != nil {
+				return false, err
+			}
+			match = members[command] || members[base]
+		default:
+			match = name == command || name == base
+		}
+		if match {
+			allowed = rule[0] == '+'
+		}
+	}
+	return allowed, nil
+}
+
+// ACL CAT 返回分类里的命令，同一次检查里缓存
+func aclCategory(ctx context.Context, category string, categories map[string]map[string]bool) (map[string]bool, error) {
+	if members, ok := categories[category]; ok {
+		return members, nil
+	}
+	commands, err := RD.Do(ctx, "acl", "cat", category).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]bool, len(commands))
+	for _, v := range commands {
+		members[strings.ToLower(v)] = true
+	}
+	categories[category] = members
+	return members, nil
+}
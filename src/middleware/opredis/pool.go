@@ -0,0 +1,109 @@
+package opredis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v9"
+)
+
+// 相同地址和认证信息的链接共用一个 client，引用计数为0时才关闭
+type pooledClient struct {
+	key    string
+	client *redis.Client
+	refs   int
+	held   bool // 全局 RD 持有的引用
+}
+
+var (
+	poolLock    sync.Mutex
+	poolClients = make(map[string]*pooledClient)
+	poolByConn  = make(map[*redis.Client]*pooledClient)
+)
+
+// 只读和读写的链接分开，只读的 client 上装了拒绝写命令的 hook
+func poolKey(opt *redis.Options, readonly bool) string {
+	return opt.Network + "|" + opt.Addr + "|" + opt.Username + "|" + opt.Password + "|" + strconv.Itoa(opt.DB) + "|" + strconv.FormatBool(opt.TLSConfig != nil) + "|" + strconv.FormatBool(readonly)
+}
+
+// 获取共用的 client，用完后调用 ReleaseClient
+func AcquireClient(opt *redis.Options) *redis.Client {
+	return acquireClient(opt, false)
+}
+
+// 获取共用的只读 client，写命令在发出前被拒绝
+func AcquireReadOnlyClient(opt *redis.Options) *redis.Client {
+	return acquireClient(opt, true)
+}
+
+func acquireClient(opt *redis.Options, readonly bool) *redis.Client {
+	return acquirePooled(poolKey(opt, readonly), opt, readonly)
+}
+
+// cluster 从库的只读 client，建立链接时先执行 READONLY，和其他只读 client 分开
+func acquireReplicaClient(opt *redis.Options) *redis.Client {
+	opt.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		return cn.ReadOnly(ctx).Err()
+	}
+	return acquirePooled(poolKey(opt, true)+"|replica", opt, true)
+}
+
+func acquirePooled(key string, opt *redis.Options, readonly bool) *redis.Client {
+	poolLock.Lock()
+	defer poolLock.Unlock()
+	if pc, ok := poolClients[key]; ok {
+		pc.refs++
+		return pc.client
+	}
+	rd := redis.NewClient(opt)
+	if readonly {
+		rd.AddHook(readOnlyHook{})
+	}
+	pc := &pooledClient{key: key, client: rd, refs: 1}
+	poolClients[key] = pc
+	poolByConn[pc.client] = pc
+	return pc.client
+}
+
+// 全局 RD 使用的 client，每个 key 只持有一个引用并且不释放
+// 切换实例时之前的 client 可能还在被其他请求使用，不能关闭，共用后个数不超过实例个数
+func holdClient(opt *redis.Options, readonly bool) *redis.Client {
+	rd := acquireClient(opt, readonly)
+	poolLock.Lock()
+	defer poolLock.Unlock()
+	pc := poolByConn[rd]
+	if pc.held {
+		pc.refs--
+	} else {
+		pc.held = true
+	}
+	return rd
+}
+
+// 释放引用，最后一个引用释放时关闭链接，不是从 AcquireClient 获取的 client 直接关闭
+func ReleaseClient(rd *redis.Client) {
+	if rd == nil {
+		return
+	}
+	poolLock.Lock()
+	pc, ok := poolByConn[rd]
+	if ok {
+		pc.refs--
+		if pc.refs > 0 {
+			poolLock.Unlock()
+			return
+		}
+		delete(poolClients, pc.key)
+		delete(poolByConn, rd)
+	}
+	poolLock.Unlock()
+	rd.Close()
+}
+
+// 当前共用的链接个数
+func PoolSize() int {
+	poolLock.Lock()
+	defer poolLock.Unlock()
+	return len(poolClients)
+}
@@ -0,0 +1,70 @@
+package opredis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-redis/redis/v9"
+)
+
+func TestAcquireClientShared(t *testing.T) {
+	server := newFakeServer(t, "6.2.6", nil)
+	size := PoolSize()
+
+	a := AcquireClient(&redis.Options{Addr: server.Addr()})
+	b := AcquireClient(&redis.Options{Addr: server.Addr()})
+	if a != b {
+		t.Fatal("same options should share one client")
+	}
+	ro := AcquireReadOnlyClient(&redis.Options{Addr: server.Addr()})
+	if ro == a {
+		t.Fatal("read-only client should not be shared with the writable one")
+	}
+	if got := PoolSize(); got != size+2 {
+		t.Errorf("PoolSize = %d, want %d", got, size+2)
+	}
+
+	ReleaseClient(b)
+	if err := a.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("client closed before last release: %v", err)
+	}
+	ReleaseClient(a)
+	if err := a.Ping(context.Background()).Err(); err != redis.ErrClosed {
+		t.Errorf("ping after last release = %v, want %v", err, redis.ErrClosed)
+	}
+	ReleaseClient(ro)
+	if got := PoolSize(); got != size {
+		t.Errorf("PoolSize after release = %d, want %d", got, size)
+	}
+}
+
+// 切换全局链接后之前的 client 还能继续使用，再切回来时复用并且只持有一个引用
+func TestDialRedisKeepsPreviousClient(t *testing.T) {
+	first := newFakeServer(t, "6.2.6", nil)
+	second := newFakeServer(t, "6.2.6", nil)
+	previous := RD
+	defer func() { RD = previous }()
+
+	if err := DialRedis(first.Addr(), "", 0, false); err != nil {
+		t.Fatal(err)
+	}
+	client := RD.Client
+	if err := DialRedis(second.Addr(), "", 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("previous global client closed: %v", err)
+	}
+	if err := DialRedis(first.Addr(), "", 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if RD.Client != client {
+		t.Error("dialing the same address again should reuse the client")
+	}
+	poolLock.Lock()
+	refs := poolByConn[client].refs
+	poolLock.Unlock()
+	if refs != 1 {
+		t.Errorf("global client refs = %d, want 1", refs)
+	}
+}
@@ -0,0 +1,87 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+
+	"github.com/go-redis/redis/v9"
+)
+
+var (
+	ErrNotReplica      = errors.New("instance is not a replica")
+	ErrReplicaLinkDown = errors.New("replica master link is down")
+	ErrReplicaLagging  = errors.New("replica has not caught up with master")
+)
+
+// 提升自建的从库，确认复制偏移量追上master后，cluster 执行 CLUSTER FAILOVER，其他执行 REPLICAOF NO ONE
+// 追上之前应该先停掉master的写入，ctx 超时后返回 ErrReplicaLagging
+func PromoteNode(ctx context.Context, target FleetTarget) error {
+	rd, err := newCallerClient(ctx, target)
+	if err != nil {
+		return NewOpError(target.Id, "promote", err)
+	}
+	defer ReleaseClient(rd)
+	val, err := rd.Info(ctx, "replication").Result()
+	if err != nil {
+		return NewOpError(target.Id, "promote", err)
+	}
+	info := ParseInfo(val)
+	if info["role"] != "slave" {
+		return NewOpError(target.Id, "promote", ErrNotReplica)
+	}
+	if info["master_link_status"] != "up" {
+		return NewOpError(target.Id, "promote", ErrReplicaLinkDown)
+	}
+	master, err := newCallerClient(ctx, FleetTarget{Addr: net.JoinHostPort(info["master_host"], info["master_port"]), Password: target.Password})
+	if err != nil {
+		return NewOpError(target.Id, "promote", err)
+	}
+	defer ReleaseClient(master)
+	if err := waitCaughtUp(ctx, master, rd); err != nil {
+		return NewOpError(target.Id, "promote", err)
+	}
+	val, err = rd.Info(ctx, "cluster").Result()
+	if err != nil {
+		return NewOpError(target.Id, "promote", err)
+	}
+	if ParseInfo(val)["cluster_enabled"] == "1" {
+		err = rd.Do(ctx, "cluster", "failover").Err()
+	} else {
+		err = rd.SlaveOf(ctx, "no", "one").Err()
+	}
+	if err != nil {
+		return NewOpError(target.Id, "promote", err)
+	}
+	logger.Warn("从库 ", target.Id, "(", target.Addr, ") 已提升为master")
+	return nil
+}
+
+// 每200毫秒比较一次 master_repl_offset 和 slave_repl_offset
+func waitCaughtUp(ctx context.Context, master, replica *redis.Client) error {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		masterval, err := master.Info(ctx, "replication").Result()
+		if err != nil {
+			return err
+		}
+		replicaval, err := replica.Info(ctx, "replication").Result()
+		if err != nil {
+			return err
+		}
+		masteroffset := infoInt(ParseInfo(masterval), "master_repl_offset")
+		replicaoffset := infoInt(ParseInfo(replicaval), "slave_repl_offset")
+		if masteroffset > 0 && replicaoffset >= masteroffset {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ErrReplicaLagging
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package opredis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+)
+
+// 按实例限制扫描、删除等批量操作每秒处理的key数量，Rate 为0时不限速
+type RateLimiter struct {
+	sync.Mutex
+	Rate int
+	next time.Time
+}
+
+var (
+	limiterLock sync.Mutex
+	limiters    = make(map[string]*RateLimiter)
+)
+
+// 获取实例的限速器，没有的时候按配置文件的 scanrate 创建
+func GetRateLimiter(addr string) *RateLimiter {
+	limiterLock.Lock()
+	defer limiterLock.Unlock()
+	l, ok := limiters[addr]
+	if !ok {
+		l = &RateLimiter{Rate: cfg.Get_Info_Int("scanrate")}
+		limiters[addr] = l
+	}
+	return l
+}
+
+// 单独设置实例的限速
+func SetRateLimit(addr string, rate int) {
+	l := GetRateLimiter(addr)
+	l.Lock()
+	l.Rate = rate
+	l.Unlock()
+}
+
+// 申请处理 n 个key，超出速率时等待，ctx 取消时返回 ctx.Err()
+func (l *RateLimiter) Wait(ctx context.Context, n int) error {
+	l.Lock()
+	if l.Rate <= 0 {
+		l.Unlock()
+		return ctx.Err()
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(n) * time.Second / time.Duration(l.Rate))
+	l.Unlock()
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
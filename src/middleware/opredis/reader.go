@@ -0,0 +1,151 @@
+package opredis
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+)
+
+var ErrUnknownGroup = errors.New("unknown master node")
+
+// 从库延迟超过该秒数时不参与读
+const readerMaxLag = 10
+
+// 平滑加权轮询的状态，按 master 节点ID保存
+type readerWeight struct {
+	weight  int
+	current int
+}
+
+var (
+	readerLock    sync.Mutex
+	readerWeights = make(map[string]map[string]*readerWeight)
+)
+
+// 从库的读权重，配置的 key 为 read_weight:节点ID，没有配置时为1，为0时不参与读
+func readWeight(nodeid string) int {
+	weight, err := strconv.Atoi(mysql.DB.GetOneCfgValue(model.READWEIGHT + ":" + nodeid))
+	if err != nil || weight < 0 {
+		return 1
+	}
+	return weight
+}
+
+// 获取 master 节点下的一个读链接，按权重轮询健康的从库，没有可用的从库时返回 master
+// 返回的是共用的只读链接，用完后调用 ReleaseClient
+func GetReader(groupid string) (*redis.Client, error) {
+	master, ok := mysql.DB.GetClusterNodeInfo(groupid)
+	if !ok {
+		return nil, ErrUnknownGroup
+	}
+	password := mysql.DB.GetClusterPassword(strconv.Itoa(master.CluserId))
+	slaves := make(map[string]mysql.ClusterNode)
+	for _, v := range mysql.DB.GetClusterNodeSlaves(groupid) {
+		slaves[v.NodeId] = v
+	}
+	for _, nodeid := range readerOrder(groupid, slaves) {
+		node := slaves[nodeid]
+		rd, err := readerClient(net.JoinHostPort(node.Ip, node.Port), password, true)
+		if err != nil {
+			continue
+		}
+		if readerHealthy(rd) {
+			return rd, nil
+		}
+		ReleaseClient(rd)
+	}
+	logger.Warn("master ", groupid, " 没有可用的从库，使用master读")
+	return readerClient(net.JoinHostPort(master.Ip, master.Port), password, false)
+}
+
+// 按平滑加权轮询排好顺序，第一个是本次选中的，后面的用于选中的不可用时依次尝试
+func readerOrder(groupid string, slaves map[string]mysql.ClusterNode) []string {
+	readerLock.Lock()
+	defer readerLock.Unlock()
+	weights := readerWeights[groupid]
+	if weights == nil {
+		weights = make(map[string]*readerWeight)
+		readerWeights[groupid] = weights
+	}
+	total := 0
+	for nodeid := range weights {
+		if _, ok := slaves[nodeid]; !ok {
+			delete(weights, nodeid)
+		}
+	}
+	for nodeid := range slaves {
+		w, ok := weights[nodeid]
+		if !ok {
+			w = &readerWeight{}
+			weights[nodeid] = w
+		}
+		w.weight = readWeight(nodeid)
+		if w.weight > 0 && instanceHealthy(nodeid) {
+			total += w.weight
+		}
+	}
+	var order []string
+	if total == 0 {
+		return order
+	}
+	var best string
+	for nodeid, w := range weights {
+		if w.weight == 0 || !instanceHealthy(nodeid) {
+			continue
+		}
+		w.current += w.weight
+		if best == "" || w.current > weights[best].current {
+			best = nodeid
+		}
+	}
+	weights[best].current -= total
+	order = append(order, best)
+	for nodeid, w := range weights {
+		if nodeid != best && w.weight > 0 && instanceHealthy(nodeid) {
+			order = append(order, nodeid)
+		}
+	}
+	return order
+}
+
+// 健康检查记录为不健康的实例跳过，没有检查过的认为健康
+func instanceHealthy(id string) bool {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	healthy, ok := instanceStates[id]
+	return !ok || healthy
+}
+
+// 从库需要和master保持链接并且延迟不超过 readerMaxLag
+func readerHealthy(rd *redis.Client) bool {
+	infoctx, cancel := TimeoutCtx()
+	defer cancel()
+	val, err := rd.Info(infoctx, "replication").Result()
+	if err != nil {
+		return false
+	}
+	info := ParseInfo(val)
+	return info["master_link_status"] == "up" && infoInt(info, "master_last_io_seconds_ago") <= readerMaxLag
+}
+
+// cluster 的从库需要先执行 READONLY 才能读
+func readerClient(addr, password string, replica bool) (*redis.Client, error) {
+	opt, err := RedisOptions(addr, password, 0)
+	if err != nil {
+		return nil, err
+	}
+	timeout := DefaultTimeout()
+	opt.DialTimeout = timeout
+	opt.ReadTimeout = timeout
+	opt.WriteTimeout = timeout
+	if replica {
+		return acquireReplicaClient(opt), nil
+	}
+	return acquireClient(opt, true), nil
+}
@@ -0,0 +1,118 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v9"
+)
+
+var ErrReadOnlyConnection = errors.New("redis connection is read-only, write command rejected")
+
+// 只读链接允许执行的命令，带子命令的用 "命令|子命令" 表示
+var readOnlyCommands = map[string]bool{
+	"ping": true, "info": true, "dbsize": true, "time": true, "lastsave": true, "command": true,
+	"exists": true, "type": true, "ttl": true, "pttl": true, "dump": true, "randomkey": true,
+	"scan": true, "sscan": true, "hscan": true, "zscan": true, "keys": true,
+	"get": true, "mget": true, "strlen": true, "getrange": true, "bitcount": true, "pfcount": true,
+	"llen": true, "lrange": true, "lindex": true,
+	"hget": true, "hmget": true, "hgetall": true, "hlen": true, "hkeys": true, "hvals": true, "hexists": true,
+	"smembers": true, "scard": true, "sismember": true, "srandmember": true,
+	"zrange": true, "zrangebyscore": true, "zrevrange": true, "zcard": true, "zscore": true, "zcount": true,
+	"xlen": true, "xrange": true, "xrevrange": true, "xinfo": true,
+	"slowlog|get": true, "slowlog|len": true, "config|get": true,
+	"object|encoding": true, "object|idletime": true, "object|freq": true, "object|refcount": true,
+	"memory|usage": true, "memory|stats": true, "debug|object": true,
+	"cluster|nodes": true, "cluster|info": true, "cluster|slots": true, "client|list": true,
+	"readonly": true, "script|exists": true, "function|list": true, "function|stats": true,
+}
+
+// 判断命令是否是只读命令
+func IsReadOnlyCommand(args ...string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	name := strings.ToLower(args[0])
+	if readOnlyCommands[name] {
+		return true
+	}
+	if len(args) > 1 {
+		return readOnlyCommands[name+"|"+strings.ToLower(args[1])]
+	}
+	return false
+}
+
+// 只读链接上执行写命令时返回 ErrReadOnlyConnection
+func (c ClientConnect) CheckCommand(args ...string) error {
+	if c.ReadOnly && !IsReadOnlyCommand(args...) {
+		return ErrReadOnlyConnection
+	}
+	return nil
+}
+
+func (c ClientClusterConnect) CheckCommand(args ...string) error {
+	if c.ReadOnly && !IsReadOnlyCommand(args...) {
+		return ErrReadOnlyConnection
+	}
+	return nil
+}
+
+// 只读链接上安装的 hook，所有命令和 pipeline 在发出前检查，不依赖调用方记得 CheckCommand
+type readOnlyHook struct{}
+
+func (readOnlyHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	if !isReadOnlyCmd(cmd) {
+		return ctx, ErrReadOnlyConnection
+	}
+	return ctx, nil
+}
+
+func (readOnlyHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+// pipeline 里有一个写命令就整个拒绝，事务的 multi/exec 不算写命令
+func (readOnlyHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	for _, cmd := range cmds {
+		switch cmd.Name() {
+		case "multi", "exec":
+			continue
+		}
+		if !isReadOnlyCmd(cmd) {
+			return ctx, ErrReadOnlyConnection
+		}
+	}
+	return ctx, nil
+}
+
+func (readOnlyHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+func isReadOnlyCmd(cmd redis.Cmder) bool {
+	var args []string
+	for i, arg := range cmd.Args() {
+		if i > 1 {
+			break
+		}
+		args = append(args, fmt.Sprint(arg))
+	}
+	return IsReadOnlyCommand(args...)
+}
+
+type readOnlyKey struct{}
+
+// 标记 ctx 是只读用户发起的，单独建立的链接也会是只读的
+func WithReadOnly(ctx context.Context, readonly bool) context.Context {
+	if !readonly {
+		return ctx
+	}
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+func IsReadOnlyContext(ctx context.Context) bool {
+	readonly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readonly
+}
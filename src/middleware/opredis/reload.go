@@ -0,0 +1,46 @@
+package opredis
+
+import (
+	"errors"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+var ErrNotTestInstance = errors.New("debug reload only allowed on instances with env=test")
+
+type ReloadResult struct {
+	Before int64 `json:"before"` // reload 前的 dbsize
+	After  int64 `json:"after"`  // reload 后的 dbsize
+	Lost   int64 `json:"lost"`
+}
+
+// 执行 DEBUG RELOAD，保存RDB后清空并重新加载，用于验证RDB是否完整
+// 只能在 env=test 的实例上执行，返回前后的 dbsize
+func ReloadFromDisk(serverip, target string) (ReloadResult, error) {
+	var result ReloadResult
+	if InstanceEnv(target) != "test" {
+		return result, ErrNotTestInstance
+	}
+	if err := RD.CheckCommand("debug", "reload"); err != nil {
+		return result, err
+	}
+	before, err := RD.DBSize(ctx).Result()
+	if err != nil {
+		logger.Error("Redis Dbsize Error: ", err)
+		return result, err
+	}
+	result.Before = before
+	logger.Warn("ip: ", serverip, " 执行 DEBUG RELOAD，dbsize: ", before)
+	if err := RD.Do(ctx, "debug", "reload").Err(); err != nil {
+		logger.Error("ip: ", serverip, " debug reload Error: ", err)
+		return result, err
+	}
+	after, err := RD.DBSize(ctx).Result()
+	if err != nil {
+		logger.Error("Redis Dbsize Error: ", err)
+		return result, err
+	}
+	result.After = after
+	result.Lost = before - after
+	return result, nil
+}
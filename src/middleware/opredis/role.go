@@ -0,0 +1,78 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidRoleFilter = errors.New("role filter should be empty, master or replica")
+
+// 按主从角色过滤批量操作的实例，写操作只对 master 执行，读操作可以只对 replica 执行
+type RoleFilter string
+
+const (
+	ROLEANY     RoleFilter = ""
+	ROLEMASTER  RoleFilter = "master"
+	ROLEREPLICA RoleFilter = "replica"
+)
+
+func ParseRoleFilter(name string) (RoleFilter, error) {
+	switch RoleFilter(name) {
+	case ROLEANY, ROLEMASTER, ROLEREPLICA:
+		return RoleFilter(name), nil
+	case "slave":
+		return ROLEREPLICA, nil
+	}
+	return ROLEANY, ErrInvalidRoleFilter
+}
+
+// role 为 INFO replication 里的 master 或 slave
+func (f RoleFilter) Match(role string) bool {
+	switch f {
+	case ROLEMASTER:
+		return role == "master"
+	case ROLEREPLICA:
+		return role == "slave"
+	}
+	return true
+}
+
+// 实例的角色不符合过滤条件时跳过，不算失败
+type RoleSkip struct {
+	Role   string
+	Filter RoleFilter
+}
+
+func (e *RoleSkip) Error() string {
+	return fmt.Sprintf("skipped: role is %s, want %s", e.Role, e.Filter)
+}
+
+// 通过 INFO replication 获取实例当前的角色
+func TargetRole(ctx context.Context, target FleetTarget) (string, error) {
+	rd, err := newTargetClient(target)
+	if err != nil {
+		return "", err
+	}
+	defer ReleaseClient(rd)
+	val, err := rd.Info(ctx, "replication").Result()
+	if err != nil {
+		return "", MapAuthError(err)
+	}
+	return ParseInfo(val)["role"], nil
+}
+
+// 角色符合时返回nil，不符合时返回 *RoleSkip，ROLEANY 不查询角色
+func checkRole(ctx context.Context, target FleetTarget, filter RoleFilter) error {
+	if filter == ROLEANY {
+		return nil
+	}
+	role, err := TargetRole(ctx, target)
+	if err != nil {
+		return err
+	}
+	if !filter.Match(role) {
+		return &RoleSkip{Role: role, Filter: filter}
+	}
+	return nil
+}
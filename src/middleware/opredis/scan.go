@@ -0,0 +1,60 @@
+package opredis
+
+import (
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+
+	"github.com/go-redis/redis/v9"
+)
+
+type ScanResult struct {
+	Keys   []string `json:"keys"`
+	Cursor uint64   `json:"cursor"` // 下一次扫描的cursor，为0时扫描结束
+}
+
+// 按 pattern 扫描一批key，typefilter 不为空时只返回该类型的key
+// 6.0 以上使用 SCAN TYPE 在服务端过滤，低版本在客户端用 TYPE 过滤
+func ScanKeys(cursor uint64, pattern string, count int64, typefilter string) ([]string, uint64, bool) {
+	if pattern == "" {
+		pattern = "*"
+	}
+	if typefilter == "" {
+		keys, next, err := RD.Scan(ctx, cursor, pattern, count).Result()
+		if err != nil {
+			logger.Error("Redis Scan ", pattern, " Error: ", err)
+			return nil, 0, false
+		}
+		return keys, next, true
+	}
+	if caps, ok := ServerCapabilities(); ok && caps.ScanType {
+		keys, next, err := RD.ScanType(ctx, cursor, pattern, count, typefilter).Result()
+		if err != nil {
+			logger.Error("Redis Scan ", pattern, " Type ", typefilter, " Error: ", err)
+			return nil, 0, false
+		}
+		return keys, next, true
+	}
+	keys, next, err := RD.Scan(ctx, cursor, pattern, count).Result()
+	if err != nil {
+		logger.Error("Redis Scan ", pattern, " Error: ", err)
+		return nil, 0, false
+	}
+	if len(keys) == 0 {
+		return keys, next, true
+	}
+	pipe := RD.Pipeline()
+	for _, keyname := range keys {
+		pipe.Type(ctx, keyname)
+	}
+	cmds, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error("Redis Type Pipeline Error: ", err)
+		return nil, 0, false
+	}
+	var result []string
+	for i, cmd := range cmds {
+		if typecmd, ok := cmd.(*redis.StatusCmd); ok && typecmd.Val() == typefilter {
+			result = append(result, keys[i])
+		}
+	}
+	return result, next, true
+}
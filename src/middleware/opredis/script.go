@@ -0,0 +1,72 @@
+package opredis
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+var (
+	ErrNotBusy        = errors.New("no script or function is running")
+	ErrUnknownKill    = errors.New("unknown kill type")
+	ErrKillNotAllowed = errors.New("script already wrote data, only SHUTDOWN NOSAVE can stop it")
+)
+
+const (
+	KILLSCRIPT   = "script"
+	KILLFUNCTION = "function"
+	KILLUNPAUSE  = "unpause"
+)
+
+type ScriptStatus struct {
+	Busy    bool   `json:"busy"`
+	Message string `json:"message"`
+}
+
+// 脚本或者function执行超过 busy-reply-threshold 后，其他命令会返回 BUSY
+func RunningScripts() (ScriptStatus, bool) {
+	var result ScriptStatus
+	err := RD.Ping(ctx).Err()
+	if err == nil {
+		return result, true
+	}
+	if strings.HasPrefix(err.Error(), "BUSY") {
+		result.Busy = true
+		result.Message = err.Error()
+		return result, true
+	}
+	logger.Error("Redis Ping Error: ", err)
+	return result, false
+}
+
+// 执行 SCRIPT KILL、FUNCTION KILL 或者 CLIENT UNPAUSE
+func KillCommand(killtype string) error {
+	var command []string
+	switch killtype {
+	case KILLSCRIPT:
+		command = []string{"script", "kill"}
+	case KILLFUNCTION:
+		command = []string{"function", "kill"}
+	case KILLUNPAUSE:
+		command = []string{"client", "unpause"}
+	default:
+		return ErrUnknownKill
+	}
+	if err := RD.CheckCommand(command...); err != nil {
+		return err
+	}
+	err := RD.Do(ctx, command[0], command[1]).Err()
+	if err != nil {
+		logger.Error("Redis ", killtype, " kill Error: ", err)
+		switch {
+		case strings.HasPrefix(err.Error(), "NOTBUSY"):
+			return ErrNotBusy
+		case strings.HasPrefix(err.Error(), "UNKILLABLE"):
+			return ErrKillNotAllowed
+		}
+		return err
+	}
+	logger.Warn("Redis ", killtype, " kill 执行成功")
+	return nil
+}
@@ -0,0 +1,71 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+
+	"github.com/go-redis/redis/v9"
+)
+
+// 在 rd 上 SCAN 匹配 pattern 的key，给没有过期时间的key设置 ttl，已经有过期时间的不修改
+// dryrun 时只统计不修改，受实例的扫描限速控制，返回设置（或需要设置）的个数
+// rd 是只读链接时 EXPIRE 会被只读 hook 拒绝
+func SetTTLByPattern(ctx context.Context, rd *redis.Client, serverip, pattern string, ttl time.Duration, dryrun bool) (int, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+	limiter := GetRateLimiter(serverip)
+	fixed := 0
+	var cursor uint64
+	for {
+		keys, next, err := rd.Scan(ctx, cursor, pattern, 1000).Result()
+		if err != nil {
+			logger.Error("Redis Scan ", pattern, " Error: ", err)
+			return fixed, NewOpError(serverip, "setttl", err)
+		}
+		if err := limiter.Wait(ctx, len(keys)); err != nil {
+			return fixed, NewOpError(serverip, "setttl", err)
+		}
+		pipe := rd.Pipeline()
+		cmds := make([]*redis.DurationCmd, 0, len(keys))
+		for _, keyname := range keys {
+			cmds = append(cmds, pipe.TTL(ctx, keyname))
+		}
+		if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+			return fixed, NewOpError(serverip, "setttl", err)
+		}
+		for i, cmd := range cmds {
+			// -1 表示没有过期时间
+			if keyttl, err := cmd.Result(); err != nil || keyttl != -1*time.Nanosecond {
+				continue
+			}
+			if dryrun {
+				fixed++
+				continue
+			}
+			// 检查和设置之间key可能被改过，EXPIRE NX 需要 redis 7，这里按检查结果直接设置
+			ok, err := rd.Expire(ctx, keys[i], ttl).Result()
+			if errors.Is(err, ErrReadOnlyConnection) {
+				return fixed, NewOpError(serverip, "setttl", err)
+			}
+			if err != nil {
+				logger.Error("Redis Expire key: ", keys[i], " Error: ", err)
+				continue
+			}
+			if ok {
+				fixed++
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	if !dryrun {
+		logger.Warn("ip: ", serverip, " 给 ", fixed, " 个没有过期时间的key设置了 ", ttl)
+	}
+	return fixed, nil
+}
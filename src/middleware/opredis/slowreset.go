@@ -0,0 +1,80 @@
+package opredis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+var ErrSlowlogResetTooFrequent = errors.New("slowlog reset interval too short")
+
+// 同一个实例两次清空慢日志的最小间隔
+const slowlogResetInterval = time.Minute
+
+var (
+	slowlogResetLock sync.Mutex
+	slowlogResetLast = make(map[string]time.Time)
+)
+
+// 清空实例的慢日志，返回清空前的条数，slowlog 被改名时使用 redis_slowlog 配置的别名
+// instanceid 为云redis的实例ID或者自建cluster的节点ID，使用单独的链接，只读用户的 ctx 会被拒绝
+func ResetSlowlog(ctx context.Context, instanceid string) (int, error) {
+	var target FleetTarget
+	found := false
+	for _, v := range FleetTargets() {
+		if v.Id == instanceid {
+			target, found = v, true
+			break
+		}
+	}
+	if !found {
+		return 0, ErrFleetTargetNotFound
+	}
+	command := "slowlog"
+	if alias, err := CommandAlias(model.SLOWLOGCOMMAND, target.Addr); err == nil {
+		command = alias
+	}
+	rd, err := newCallerClient(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+	defer ReleaseClient(rd)
+	if err := (ClientConnect{Client: rd, ReadOnly: IsReadOnlyContext(ctx)}).CheckCommand(command, "reset"); err != nil {
+		return 0, err
+	}
+	// 在锁内占住这次清空，失败时恢复上一次的时间，避免并发请求同时通过间隔检查
+	slowlogResetLock.Lock()
+	last, ok := slowlogResetLast[instanceid]
+	if ok && time.Since(last) < slowlogResetInterval {
+		slowlogResetLock.Unlock()
+		return 0, ErrSlowlogResetTooFrequent
+	}
+	slowlogResetLast[instanceid] = time.Now()
+	slowlogResetLock.Unlock()
+	rollback := func() {
+		slowlogResetLock.Lock()
+		if ok {
+			slowlogResetLast[instanceid] = last
+		} else {
+			delete(slowlogResetLast, instanceid)
+		}
+		slowlogResetLock.Unlock()
+	}
+	count, err := rd.Do(ctx, command, "len").Int()
+	if err != nil {
+		rollback()
+		logger.Error("ip: ", target.Addr, " slowlog len Error: ", err)
+		return 0, err
+	}
+	if err := rd.Do(ctx, command, "reset").Err(); err != nil {
+		rollback()
+		logger.Error("ip: ", target.Addr, " slowlog reset Error: ", err)
+		return 0, err
+	}
+	logger.Warn("ip: ", target.Addr, " 实例 ", instanceid, " 清空慢日志 ", count, " 条")
+	return count, nil
+}
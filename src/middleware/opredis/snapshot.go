@@ -0,0 +1,103 @@
+package opredis
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/events"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+var ErrConfigSetFailed = errors.New("config set failed")
+
+// 默认快照的可在线修改的参数
+var SnapshotParams = []string{
+	"maxmemory", "maxmemory-policy", "maxmemory-samples", "timeout", "tcp-keepalive", "hz",
+	"slowlog-log-slower-than", "slowlog-max-len", "latency-monitor-threshold",
+	"appendonly", "appendfsync", "save", "maxclients", "lazyfree-lazy-eviction", "lazyfree-lazy-expire",
+	"client-output-buffer-limit", "notify-keyspace-events",
+}
+
+// 配置快照，用于修改配置后回滚
+type ConfigSnapshot struct {
+	Addr   string            `json:"addr"`
+	Time   time.Time         `json:"time"`
+	Params map[string]string `json:"params"`
+}
+
+// 配置修改的结果，带上修改前的快照
+type ConfigApplyResult struct {
+	Snapshot ConfigSnapshot    `json:"snapshot"`
+	Applied  map[string]string `json:"applied"`
+}
+
+// 保存默认参数和 extra 参数的当前值
+func SnapshotConfig(serverip string, extra ...string) (ConfigSnapshot, bool) {
+	snap := ConfigSnapshot{Addr: serverip, Time: time.Now(), Params: make(map[string]string)}
+	for _, name := range append(append([]string{}, SnapshotParams...), extra...) {
+		result, ok := GetConfig(name)
+		if !ok {
+			return snap, false
+		}
+		// 老版本不支持的参数不会返回
+		if value, ok := result[name]; ok {
+			snap.Params[name] = value
+		}
+	}
+	return snap, true
+}
+
+// 把快照里的参数设置回去
+func RestoreConfig(snap ConfigSnapshot) error {
+	return setConfig(snap.Params)
+}
+
+// 先保存快照再修改配置，失败的时候自动回滚已经修改的参数
+func ApplyConfig(serverip string, params map[string]string) (ConfigApplyResult, error) {
+	var result ConfigApplyResult
+	var names []string
+	for k := range params {
+		names = append(names, k)
+	}
+	snap, ok := SnapshotConfig(serverip, names...)
+	if !ok {
+		return result, ErrConfigSetFailed
+	}
+	result.Snapshot = snap
+	if err := setConfig(params); err != nil {
+		if rerr := RestoreConfig(snap); rerr != nil {
+			logger.Error("ip: "+serverip+" 配置回滚失败：", rerr)
+		}
+		return result, err
+	}
+	result.Applied = params
+	logger.Info("ip: "+serverip+" 修改配置成功：", params)
+	// 参数值可能有密码，只带参数名
+	events.Publish(events.CONFIGAPPLIED, serverip, map[string]string{"params": strings.Join(names, ",")})
+	return result, nil
+}
+
+func setConfig(params map[string]string) error {
+	if err := RD.CheckCommand("config", "set"); err != nil {
+		return err
+	}
+	var names []string
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var failed []string
+	for _, name := range names {
+		if err := RD.ConfigSet(ctx, name, params[name]).Err(); err != nil {
+			logger.Error("Redis Config Set ", name, " Error: ", err)
+			failed = append(failed, name+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", ErrConfigSetFailed, strings.Join(failed, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package opredis
+
+import (
+	"context"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+)
+
+// 没有配置 rediscfg.optimeout 时的redis操作超时时间
+const fallbackTimeout = 3 * time.Second
+
+// redis操作的默认超时时间，来自配置文件的 rediscfg.optimeout（毫秒），对之后新建的链接生效
+func DefaultTimeout() time.Duration {
+	if optimeout := cfg.Get_Info_Int("optimeout"); optimeout > 0 {
+		return time.Duration(optimeout) * time.Millisecond
+	}
+	return fallbackTimeout
+}
+
+// 带默认超时时间的context
+func TimeoutCtx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, DefaultTimeout())
+}
@@ -0,0 +1,93 @@
+package opredis
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+
+	"github.com/go-redis/redis/v9"
+)
+
+// 默认的TTL分桶：<1m、1m-1h、1h-1d、>1d
+var DefaultTTLBuckets = []time.Duration{time.Minute, time.Hour, 24 * time.Hour}
+
+// 最多统计的key个数，避免在大实例上扫描太久
+const ttlHistogramLimit = 100000
+
+// SCAN 匹配 pattern 的key，按 TTL 落到 buckets 划分的区间里计数，没有过期时间的计入 none
+// 扫描和 TTL 都受实例的扫描限速控制，ctx 取消时返回已经统计的结果和错误
+func TTLHistogram(ctx context.Context, serverip, pattern string, buckets []time.Duration) (map[string]int, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultTTLBuckets
+	}
+	buckets = append([]time.Duration(nil), buckets...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	labels := ttlBucketLabels(buckets)
+	result := make(map[string]int, len(labels)+1)
+	for _, label := range labels {
+		result[label] = 0
+	}
+	result["none"] = 0
+	limiter := GetRateLimiter(serverip)
+	var cursor uint64
+	total := 0
+	for {
+		keys, next, err := RD.Scan(ctx, cursor, pattern, 1000).Result()
+		if err != nil {
+			logger.Error("Redis Scan ", pattern, " Error: ", err)
+			return result, NewOpError(serverip, "ttlhistogram", err)
+		}
+		if err := limiter.Wait(ctx, len(keys)); err != nil {
+			return result, NewOpError(serverip, "ttlhistogram", err)
+		}
+		pipe := RD.Pipeline()
+		cmds := make([]*redis.DurationCmd, 0, len(keys))
+		for _, keyname := range keys {
+			cmds = append(cmds, pipe.PTTL(ctx, keyname))
+		}
+		if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+			return result, NewOpError(serverip, "ttlhistogram", err)
+		}
+		for _, cmd := range cmds {
+			ttl, err := cmd.Result()
+			// -2 表示key已经不存在
+			if err != nil || ttl == -2*time.Nanosecond {
+				continue
+			}
+			total++
+			if ttl < 0 {
+				result["none"]++
+				continue
+			}
+			result[labels[ttlBucket(buckets, ttl)]]++
+		}
+		cursor = next
+		if cursor == 0 || total >= ttlHistogramLimit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// 第一个大于 ttl 的分界点所在的区间，都不大于时是最后一个区间
+func ttlBucket(buckets []time.Duration, ttl time.Duration) int {
+	return sort.Search(len(buckets), func(i int) bool { return ttl < buckets[i] })
+}
+
+// n 个分界点对应 n+1 个区间，例如 <1m0s、1m0s-1h0m0s、>1h0m0s
+func ttlBucketLabels(buckets []time.Duration) []string {
+	labels := make([]string, 0, len(buckets)+1)
+	for i, b := range buckets {
+		if i == 0 {
+			labels = append(labels, "<"+b.String())
+			continue
+		}
+		labels = append(labels, buckets[i-1].String()+"-"+b.String())
+	}
+	return append(labels, ">"+buckets[len(buckets)-1].String())
+}
@@ -0,0 +1,69 @@
+package opredis
+
+import (
+	"math"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+type TypeHistogramResult struct {
+	DbSize        int64            `json:"dbsize"`
+	Sampled       int              `json:"sampled"`         // 实际采样的key个数
+	Counts        map[string]int   `json:"counts"`          // 采样里各类型的key个数
+	Estimated     map[string]int64 `json:"estimated"`       // 按 dbsize 放大后的估算值
+	MarginOfError float64          `json:"margin_of_error"` // 95%置信度下每个类型占比的最大误差
+}
+
+// 通过 SCAN 采样统计key的类型分布，再按 DBSIZE 放大估算总数
+// 每个类型占比的误差按最坏情况(p=0.5)计算：1.96*sqrt(0.25/n)*sqrt((N-n)/(N-1))
+// 采样1000个key时误差约为±3%，采样10000个时约为±1%；采样覆盖全部key时误差为0
+// SCAN 的顺序跟key的hash有关，近似随机，但同一个实例每次采样到的key基本相同
+func TypeHistogram(samplesize int) (TypeHistogramResult, bool) {
+	result := TypeHistogramResult{
+		Counts:    make(map[string]int),
+		Estimated: make(map[string]int64),
+	}
+	dbsize, err := RD.DBSize(ctx).Result()
+	if err != nil {
+		logger.Error("Redis Dbsize Error: ", err)
+		return result, false
+	}
+	result.DbSize = dbsize
+	if dbsize == 0 || samplesize <= 0 {
+		return result, true
+	}
+	var cursor uint64
+	for result.Sampled < samplesize {
+		keylist, next, scanok := GetScanKey(cursor, 1000)
+		if !scanok {
+			return result, false
+		}
+		for _, keyname := range keylist {
+			keytype, ok := TypeKey(keyname)
+			if !ok || keytype == "none" {
+				continue
+			}
+			result.Counts[keytype]++
+			result.Sampled++
+			if result.Sampled >= samplesize {
+				break
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	if result.Sampled == 0 {
+		return result, true
+	}
+	for keytype, count := range result.Counts {
+		result.Estimated[keytype] = int64(math.Round(float64(count) * float64(dbsize) / float64(result.Sampled)))
+	}
+	n := float64(result.Sampled)
+	total := float64(dbsize)
+	if n < total && total > 1 {
+		result.MarginOfError = 1.96 * math.Sqrt(0.25/n) * math.Sqrt((total-n)/(total-1))
+	}
+	return result, true
+}
@@ -0,0 +1,85 @@
+package opredis
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+// 各功能开始支持的版本
+const (
+	unlinkVersion   = "4.0.0"
+	memoryVersion   = "4.0.0"
+	scanTypeVersion = "6.0.0"
+	copyVersion     = "6.2.0"
+	functionVersion = "7.0.0"
+)
+
+var (
+	versionLock  sync.Mutex
+	versionCache = make(map[string]string)
+)
+
+// 获取当前链接的 redis_version，按地址缓存，重新建立链接时清除
+func ServerVersion() (string, bool) {
+	addr := RD.Options().Addr
+	versionLock.Lock()
+	version, ok := versionCache[addr]
+	versionLock.Unlock()
+	if ok {
+		return version, true
+	}
+	info, ok := GetInfo("server")
+	if !ok {
+		return "", false
+	}
+	version = info["redis_version"]
+	versionLock.Lock()
+	versionCache[addr] = version
+	versionLock.Unlock()
+	return version, true
+}
+
+func clearVersion(addr string) {
+	versionLock.Lock()
+	delete(versionCache, addr)
+	versionLock.Unlock()
+}
+
+// 当前链接支持的功能
+func ServerCapabilities() (model.Capabilities, bool) {
+	version, ok := ServerVersion()
+	if !ok {
+		return model.Capabilities{}, false
+	}
+	return VersionCapabilities(version), true
+}
+
+func VersionCapabilities(version string) model.Capabilities {
+	return model.Capabilities{
+		Unlink:      VersionAtLeast(version, unlinkVersion),
+		MemoryStats: VersionAtLeast(version, memoryVersion),
+		ScanType:    VersionAtLeast(version, scanTypeVersion),
+		Copy:        VersionAtLeast(version, copyVersion),
+		Function:    VersionAtLeast(version, functionVersion),
+	}
+}
+
+// 比较版本号，version >= min 时返回 true，解析不了的部分按0处理
+func VersionAtLeast(version, min string) bool {
+	v := strings.Split(version, ".")
+	m := strings.Split(min, ".")
+	for i := 0; i < len(m); i++ {
+		var a, b int
+		if i < len(v) {
+			a, _ = strconv.Atoi(v[i])
+		}
+		b, _ = strconv.Atoi(m[i])
+		if a != b {
+			return a > b
+		}
+	}
+	return true
+}
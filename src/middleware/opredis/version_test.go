@@ -0,0 +1,89 @@
+package opredis
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/go-redis/redis/v9"
+)
+
+func TestVersionAtLeast(t *testing.T) {
+	cases := []struct {
+		version, min string
+		want         bool
+	}{
+		{"6.0.0", "6.0.0", true},
+		{"6.2.6", "6.0.0", true},
+		{"5.0.14", "6.0.0", false},
+		{"6.0.10", "6.0.9", true},
+		{"10.0.0", "6.2.0", true},
+		{"6.2", "6.2.0", true},
+		{"6", "6.2.0", false},
+		{"7.0.0-rc1", "7.0.0", true},
+		{"", "4.0.0", false},
+	}
+	for _, c := range cases {
+		if got := VersionAtLeast(c.version, c.min); got != c.want {
+			t.Errorf("VersionAtLeast(%q, %q) = %v, want %v", c.version, c.min, got, c.want)
+		}
+	}
+}
+
+// 把全局链接指向 fake server，测试结束后恢复
+func useFakeServer(t *testing.T, server *fakeServer) {
+	previous := RD
+	RD = ClientConnect{Client: redis.NewClient(&redis.Options{Addr: server.Addr()})}
+	clearVersion(server.Addr())
+	t.Cleanup(func() {
+		RD.Close()
+		RD = previous
+		clearVersion(server.Addr())
+	})
+}
+
+var scanTypes = map[string]string{"a": "string", "b": "hash", "c": "string"}
+
+func TestScanKeysServerSideType(t *testing.T) {
+	server := newFakeServer(t, "6.2.6", scanTypes)
+	useFakeServer(t, server)
+
+	keys, next, ok := ScanKeys(0, "*", 100, "string")
+	if !ok || next != 0 {
+		t.Fatalf("ScanKeys ok = %v, next = %d", ok, next)
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "c" {
+		t.Errorf("keys = %v, want [a c]", keys)
+	}
+	scans := server.Received("scan")
+	if len(scans) != 1 || len(scans[0]) != 8 || scans[0][6] != "type" {
+		t.Errorf("want one SCAN with TYPE, got %v", scans)
+	}
+	if types := server.Received("type"); len(types) != 0 {
+		t.Errorf("server side filter should not send TYPE, got %v", types)
+	}
+}
+
+func TestScanKeysTypeFallback(t *testing.T) {
+	server := newFakeServer(t, "5.0.7", scanTypes)
+	useFakeServer(t, server)
+
+	keys, _, ok := ScanKeys(0, "*", 100, "string")
+	if !ok {
+		t.Fatal("ScanKeys failed")
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "c" {
+		t.Errorf("keys = %v, want [a c]", keys)
+	}
+	for _, v := range server.Received("scan") {
+		for _, arg := range v {
+			if arg == "type" {
+				t.Errorf("SCAN TYPE sent to 5.0 server: %v", v)
+			}
+		}
+	}
+	if types := server.Received("type"); len(types) != len(scanTypes) {
+		t.Errorf("want one TYPE per key, got %v", types)
+	}
+}
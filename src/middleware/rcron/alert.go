@@ -0,0 +1,18 @@
+package rcron
+
+import (
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/alert"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+// 检查实例的内存使用率和主从延迟，阈值为0时不告警
+func checkThresholds(summary model.InstanceSummary) {
+	if !summary.Reachable {
+		return
+	}
+	memory := float64(cfg.Get_Info_Int("alertmemorypercent"))
+	alert.Check("memory_percent", summary.Id, memory > 0 && summary.MemoryPercent > memory, summary.MemoryPercent, memory, summary.Addr+" 内存使用率超过阈值")
+	lag := float64(cfg.Get_Info_Int("alertlagsec"))
+	alert.Check("repl_lag", summary.Id, lag > 0 && float64(summary.ReplLag) > lag, float64(summary.ReplLag), lag, summary.Addr+" 主从延迟超过阈值")
+}
@@ -0,0 +1,20 @@
+package rcron
+
+import (
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/util"
+)
+
+// 执行所有备份策略
+func Backup() {
+	policies, ok := util.BackupPolicies()
+	if !ok {
+		return
+	}
+	for _, v := range policies {
+		result := util.RunBackup(v)
+		if result.Success {
+			logger.Info("backup policy ", result.Policy, " type: ", result.Type, " target: ", result.Target, " success, task id: ", result.TaskId)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
 	"github.com/iguidao/redis-manager/src/middleware/txcloud"
 	"github.com/iguidao/redis-manager/src/middleware/util"
 )
@@ -32,7 +33,8 @@ func CloudRefresh() {
 				if ok {
 					err := json.Unmarshal([]byte(list), &rlist)
 					if err == nil {
-						go util.TxWriteRedis(v, rlist)
+						cloud := v
+						tools.SafeGo("txwriteredis", func() { util.TxWriteRedis(cloud, rlist) })
 						logger.Info("定时任务：开始更新腾讯云redis数据")
 					} else {
 						logger.Error("定时任务：json解析云redis数据失败", err)
@@ -0,0 +1,31 @@
+package rcron
+
+import (
+	"context"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/alert"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+)
+
+// 并发采样所有实例的过期和驱逐key个数，已经下线的实例的采样同时清理掉
+func EvictionSample() {
+	targets := opredis.FleetTargets()
+	opredis.PruneEvictionSamples(targets)
+	threshold := float64(cfg.Get_Info_Int("alertevictedpersec"))
+	group := opredis.GroupExec(context.Background(), targets, 2*opredis.DefaultTimeout(), func(ctx context.Context, target opredis.FleetTarget) (interface{}, error) {
+		return nil, opredis.SampleEviction(ctx, target)
+	})
+	for _, v := range targets {
+		if _, failed := group.Errors[v.Id]; failed {
+			continue
+		}
+		if _, evicted, err := opredis.EvictionRate(v.Id); err == nil {
+			alert.Check("evicted_keys", v.Id, threshold > 0 && evicted > threshold, evicted, threshold, v.Addr+" 每秒驱逐的key个数超过阈值")
+		}
+	}
+	if len(group.Errors) > 0 {
+		logger.Warn("定时任务：驱逐采样失败的实例个数：", len(group.Errors))
+	}
+}
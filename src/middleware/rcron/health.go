@@ -0,0 +1,19 @@
+package rcron
+
+import (
+	"context"
+
+	"github.com/iguidao/redis-manager/src/middleware/alert"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+)
+
+// 检查所有实例的健康状态，已经下线的实例的告警同时清理掉
+func InstanceHealth() {
+	targets := opredis.FleetTargets()
+	instances := make([]string, 0, len(targets))
+	for _, v := range targets {
+		instances = append(instances, v.Id)
+	}
+	alert.Prune(instances)
+	opredis.CheckInstanceHealth(context.Background(), targets)
+}
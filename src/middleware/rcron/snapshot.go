@@ -0,0 +1,26 @@
+package rcron
+
+import (
+	"context"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+	"github.com/iguidao/redis-manager/src/middleware/snapshot"
+)
+
+// 保存所有实例的指标快照，用于查看趋势
+func InfoSnapshot() {
+	fleet := opredis.FleetSummary(context.Background(), opredis.FleetTargets())
+	now := time.Now()
+	var snapshots []snapshot.Snapshot
+	for _, v := range fleet.Instances {
+		if snap, ok := snapshot.FromSummary(v, now); ok {
+			snapshots = append(snapshots, snap)
+		}
+		checkThresholds(v)
+	}
+	if err := snapshot.DefaultStore().Record(snapshots); err != nil {
+		logger.Error("Record info snapshot error: ", err)
+	}
+}
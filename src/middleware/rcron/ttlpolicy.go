@@ -0,0 +1,19 @@
+package rcron
+
+import (
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/util"
+)
+
+// 执行TTL检查策略
+func TTLPolicy() {
+	for _, v := range util.EnforceTTLPolicies() {
+		if v.Error != "" {
+			logger.Warn("定时任务：TTL检查失败 instance: ", v.Instance, " error: ", v.Error)
+			continue
+		}
+		if v.NoTtl > 0 {
+			logger.Info("ttl policy instance: ", v.Instance, " no ttl: ", v.NoTtl, "/", v.Sampled, " estimated: ", v.Estimated, " dry run: ", v.DryRun, " fixed: ", v.Fixed)
+		}
+	}
+}
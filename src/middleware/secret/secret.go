@@ -0,0 +1,120 @@
+package secret
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/stderr"
+)
+
+// 以这个前缀开头的配置值从 Vault 读取，例如 vault:secret/data/redis#secretkey
+const VaultPrefix = "vault:"
+
+var ErrNoResolver = errors.New("secret reference found but no resolver configured, set VAULT_ADDR and VAULT_TOKEN")
+
+// 把配置里的引用解析成实际的值
+type SecretResolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// 同一个引用解析失败时，这个时间内只上报一次
+const errorInterval = time.Minute
+
+var (
+	lock     sync.Mutex
+	resolver SecretResolver
+
+	errorLock    sync.Mutex
+	errorHandler func(ref string, err error)
+	lastError    = make(map[string]time.Time)
+)
+
+// 替换全局的 resolver
+func SetResolver(r SecretResolver) {
+	lock.Lock()
+	resolver = r
+	lock.Unlock()
+}
+
+// 注册解析失败时的处理，secret 不能依赖 logger，由 main 注册为写错误日志
+// 没有注册时输出到 stderr
+func SetErrorHandler(fn func(ref string, err error)) {
+	errorLock.Lock()
+	errorHandler = fn
+	errorLock.Unlock()
+}
+
+// 按引用限频上报解析失败，配置每次读取都会解析，Vault 不可用时避免刷屏
+func reportError(ref string, err error) {
+	errorLock.Lock()
+	if time.Since(lastError[ref]) < errorInterval {
+		errorLock.Unlock()
+		return
+	}
+	lastError[ref] = time.Now()
+	fn := errorHandler
+	errorLock.Unlock()
+	if fn == nil {
+		stderr.Printf("resolve secret %s error: %v\n", ref, err)
+		return
+	}
+	fn(ref, err)
+}
+
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, VaultPrefix)
+}
+
+// 不是引用的值原样返回，是引用时用到的时候才去 Vault 读取
+// 没有调用 SetResolver 时按 VAULT_ADDR、VAULT_TOKEN 环境变量创建
+func Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	lock.Lock()
+	if resolver == nil && os.Getenv("VAULT_ADDR") != "" {
+		resolver = NewVaultResolver(os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN"), DefaultCacheTTL)
+	}
+	r := resolver
+	lock.Unlock()
+	if r == nil {
+		reportError(value, ErrNoResolver)
+		return "", ErrNoResolver
+	}
+	result, err := r.Resolve(value)
+	if err != nil {
+		reportError(value, err)
+	}
+	return result, err
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// 按 TTL 缓存解析结果，避免每次读取配置都请求 Vault
+type cache struct {
+	lock  sync.Mutex
+	ttl   time.Duration
+	items map[string]cachedSecret
+}
+
+func (c *cache) get(ref string) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	item, ok := c.items[ref]
+	if !ok || time.Now().After(item.expires) {
+		return "", false
+	}
+	return item.value, true
+}
+
+func (c *cache) set(ref, value string) {
+	c.lock.Lock()
+	c.items[ref] = cachedSecret{value: value, expires: time.Now().Add(c.ttl)}
+	c.lock.Unlock()
+}
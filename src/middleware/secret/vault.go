@@ -0,0 +1,86 @@
+package secret
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRef = errors.New("secret reference should be vault:path#field")
+
+// 解析结果的默认缓存时间
+const DefaultCacheTTL = 5 * time.Minute
+
+// 通过 Vault 的 HTTP API 读取 KV 引擎里的值，同时支持 kv v1 和 v2
+type VaultResolver struct {
+	Addr   string
+	Token  string
+	Client *http.Client
+
+	cache *cache
+}
+
+func NewVaultResolver(addr, token string, ttl time.Duration) *VaultResolver {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &VaultResolver{
+		Addr:   strings.TrimRight(addr, "/"),
+		Token:  token,
+		Client: &http.Client{Timeout: 10 * time.Second},
+		cache:  &cache{ttl: ttl, items: make(map[string]cachedSecret)},
+	}
+}
+
+// ref 格式为 vault:secret/data/redis#secretkey，# 后面是 secret 里的字段名
+func (v *VaultResolver) Resolve(ref string) (string, error) {
+	if value, ok := v.cache.get(ref); ok {
+		return value, nil
+	}
+	path, field, ok := splitRef(ref)
+	if !ok {
+		return "", ErrInvalidRef
+	}
+	req, err := http.NewRequest(http.MethodGet, v.Addr+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault read %s returned %s", path, resp.Status)
+	}
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	// kv v2 的值在 data.data 里，v1 直接在 data 里
+	data := body.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		data = inner
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %s", path, field)
+	}
+	v.cache.set(ref, value)
+	return value, nil
+}
+
+func splitRef(ref string) (string, string, bool) {
+	ref = strings.TrimPrefix(ref, VaultPrefix)
+	i := strings.LastIndex(ref, "#")
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", false
+	}
+	return strings.Trim(ref[:i], "/"), ref[i+1:], true
+}
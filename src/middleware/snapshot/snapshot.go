@@ -0,0 +1,222 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+)
+
+var ErrUnknownMetric = errors.New("unknown snapshot metric")
+
+// 可以查询趋势的指标，对应 InstanceSummary 的字段
+var Metrics = []string{"used_memory", "memory_percent", "ops_per_sec", "hit_ratio", "connected_clients", "keys", "repl_lag"}
+
+// 某个时间点一个实例的指标
+type Snapshot struct {
+	Time     time.Time          `json:"time"`
+	Instance string             `json:"instance"` // 实例ID，自建cluster为节点ID
+	Addr     string             `json:"addr"`
+	Metrics  map[string]float64 `json:"metrics"`
+}
+
+type DataPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// 定期保存的实例指标，按大小轮转，超过保留天数的历史文件删除，不依赖外部时序库
+type SnapshotStore struct {
+	Path      string
+	MaxSize   int64
+	Backups   int
+	Retention time.Duration
+
+	lock sync.Mutex
+}
+
+var (
+	defaultStore *SnapshotStore
+	defaultOnce  sync.Once
+)
+
+// 按配置文件创建的全局 store，默认 ./logs/snapshot.log、单个文件50MB、保留7天
+func DefaultStore() *SnapshotStore {
+	defaultOnce.Do(func() {
+		defaultStore = &SnapshotStore{
+			Path:      "./logs/snapshot.log",
+			MaxSize:   50 * 1024 * 1024,
+			Backups:   10,
+			Retention: 7 * 24 * time.Hour,
+		}
+		if path := cfg.Get_Info_String("snapshotpath"); path != "" {
+			defaultStore.Path = path
+		}
+		if size := cfg.Get_Info_Int("snapshotmaxmb"); size > 0 {
+			defaultStore.MaxSize = int64(size) * 1024 * 1024
+		}
+		if n := cfg.Get_Info_Int("snapshotbackups"); n > 0 {
+			defaultStore.Backups = n
+		}
+		if days := cfg.Get_Info_Int("snapshotretentiondays"); days > 0 {
+			defaultStore.Retention = time.Duration(days) * 24 * time.Hour
+		}
+	})
+	return defaultStore
+}
+
+// 不可达的实例没有指标，不保存
+func FromSummary(summary model.InstanceSummary, now time.Time) (Snapshot, bool) {
+	if !summary.Reachable {
+		return Snapshot{}, false
+	}
+	return Snapshot{
+		Time:     now,
+		Instance: summary.Id,
+		Addr:     summary.Addr,
+		Metrics: map[string]float64{
+			"used_memory":       float64(summary.UsedMemory),
+			"memory_percent":    summary.MemoryPercent,
+			"ops_per_sec":       float64(summary.OpsPerSec),
+			"hit_ratio":         summary.HitRatio,
+			"connected_clients": float64(summary.Clients),
+			"keys":              float64(summary.Keys),
+			"repl_lag":          float64(summary.ReplLag),
+		},
+	}, true
+}
+
+// 追加一批快照，文件超过大小后轮转为 snapshot.log.1、snapshot.log.2 ...
+func (s *SnapshotStore) Record(snapshots []Snapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	var data []byte
+	for _, snap := range snapshots {
+		line, err := json.Marshal(snap)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.Path); err == nil && info.Size()+int64(len(data)) > s.MaxSize {
+		s.rotate()
+	}
+	s.prune()
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return err
+}
+
+func (s *SnapshotStore) rotate() {
+	os.Remove(s.Path + "." + strconv.Itoa(s.Backups))
+	for i := s.Backups - 1; i >= 1; i-- {
+		os.Rename(s.Path+"."+strconv.Itoa(i), s.Path+"."+strconv.Itoa(i+1))
+	}
+	if err := os.Rename(s.Path, s.Path+".1"); err != nil {
+		logger.Error("Snapshot rotate error: ", err)
+	}
+}
+
+// 历史文件的最后修改时间超过保留时间时删除，里面的数据都已经过期
+func (s *SnapshotStore) prune() {
+	deadline := time.Now().Add(-s.Retention)
+	for i := 1; i <= s.Backups; i++ {
+		file := s.Path + "." + strconv.Itoa(i)
+		if info, err := os.Stat(file); err == nil && info.ModTime().Before(deadline) {
+			if err := os.Remove(file); err != nil {
+				logger.Error("Snapshot prune error: ", err)
+			}
+		}
+	}
+}
+
+// 查询实例最近 window 时间内某个指标的变化，按时间从旧到新返回
+// instance 可以是实例ID或地址
+func (s *SnapshotStore) Trend(instance, metric string, window time.Duration) ([]DataPoint, error) {
+	if !validMetric(metric) {
+		return nil, ErrUnknownMetric
+	}
+	if window <= 0 || window > s.Retention {
+		window = s.Retention
+	}
+	since := time.Now().Add(-window)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var files []string
+	for i := s.Backups; i >= 1; i-- {
+		files = append(files, s.Path+"."+strconv.Itoa(i))
+	}
+	files = append(files, s.Path)
+	var result []DataPoint
+	for _, file := range files {
+		points, err := readTrend(file, instance, metric, since)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, points...)
+	}
+	return result, nil
+}
+
+func validMetric(metric string) bool {
+	for _, v := range Metrics {
+		if v == metric {
+			return true
+		}
+	}
+	return false
+}
+
+func readTrend(file, instance, metric string, since time.Time) ([]DataPoint, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var result []DataPoint
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var snap Snapshot
+		// 进程退出时可能写了半行，跳过
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			continue
+		}
+		if snap.Time.Before(since) || (snap.Instance != instance && snap.Addr != instance) {
+			continue
+		}
+		value, ok := snap.Metrics[metric]
+		if !ok {
+			continue
+		}
+		result = append(result, DataPoint{Timestamp: snap.Time.Unix(), Value: value})
+	}
+	return result, scanner.Err()
+}
+
+// 使用全局 store 查询趋势
+func Trend(instance, metric string, window time.Duration) ([]DataPoint, error) {
+	return DefaultStore().Trend(instance, metric, window)
+}
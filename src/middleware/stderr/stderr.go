@@ -0,0 +1,16 @@
+package stderr
+
+import (
+	"fmt"
+	"os"
+)
+
+// 不能依赖 logger 的地方（secret、goroutine 和 logger 自己的后台发送）出错时统一输出到 stderr，
+// 不混进 stdout 的正常输出，这个包不依赖其他包
+func Println(a ...interface{}) {
+	fmt.Fprintln(os.Stderr, a...)
+}
+
+func Printf(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, a...)
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrMaintenanceWindow = errors.New("invalid maintenance window")
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// 一天中的时间区间，单位分钟，end 小于 start 时表示跨天
+type minuteRange struct {
+	start int
+	end   int
+}
+
+// 维护窗口，每个星期几可以配置多个时间区间
+type MaintenanceWindow struct {
+	location *time.Location
+	ranges   map[time.Weekday][]minuteRange
+}
+
+// 解析维护窗口配置，多段之间用 ; 分隔，每段是 "星期 时间区间"
+// 例如 "mon-fri 02:00-04:00,22:00-23:30;sat,sun 00:00-06:00"，星期可以用 * 表示每天
+// 跨天的区间例如 23:00-02:00 算在开始的那一天
+func ParseMaintenanceWindow(spec, timezone string) (*MaintenanceWindow, error) {
+	location := time.Local
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: timezone %q: %v", ErrMaintenanceWindow, timezone, err)
+		}
+		location = loc
+	}
+	window := &MaintenanceWindow{location: location, ranges: make(map[time.Weekday][]minuteRange)}
+	for _, part := range strings.Split(spec, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: %q", ErrMaintenanceWindow, part)
+		}
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range strings.Split(fields[1], ",") {
+			mr, err := parseMinuteRange(r)
+			if err != nil {
+				return nil, err
+			}
+			for _, day := range days {
+				window.ranges[day] = append(window.ranges[day], mr)
+			}
+		}
+	}
+	return window, nil
+}
+
+// 判断时间是否在维护窗口内，没有配置任何区间时总是返回 true
+func (w *MaintenanceWindow) InWindow(t time.Time) bool {
+	if w == nil || len(w.ranges) == 0 {
+		return true
+	}
+	t = t.In(w.location)
+	minute := t.Hour()*60 + t.Minute()
+	for _, r := range w.ranges[t.Weekday()] {
+		if r.start <= r.end && minute >= r.start && minute < r.end {
+			return true
+		}
+		if r.start > r.end && minute >= r.start {
+			return true
+		}
+	}
+	// 前一天开始的跨天区间
+	for _, r := range w.ranges[(t.Weekday()+6)%7] {
+		if r.start > r.end && minute < r.end {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWeekdays(spec string) ([]time.Weekday, error) {
+	if spec == "*" {
+		return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}, nil
+	}
+	var result []time.Weekday
+	for _, v := range strings.Split(strings.ToLower(spec), ",") {
+		bounds := strings.SplitN(v, "-", 2)
+		start, ok := weekdays[bounds[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: weekday %q", ErrMaintenanceWindow, v)
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, ok = weekdays[bounds[1]]; !ok {
+				return nil, fmt.Errorf("%w: weekday %q", ErrMaintenanceWindow, v)
+			}
+		}
+		for day := start; ; day = (day + 1) % 7 {
+			result = append(result, day)
+			if day == end {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func parseMinuteRange(spec string) (minuteRange, error) {
+	bounds := strings.SplitN(spec, "-", 2)
+	if len(bounds) != 2 {
+		return minuteRange{}, fmt.Errorf("%w: time range %q", ErrMaintenanceWindow, spec)
+	}
+	start, err := time.Parse("15:04", bounds[0])
+	if err != nil {
+		return minuteRange{}, fmt.Errorf("%w: time range %q", ErrMaintenanceWindow, spec)
+	}
+	end, err := time.Parse("15:04", bounds[1])
+	if err != nil {
+		return minuteRange{}, fmt.Errorf("%w: time range %q", ErrMaintenanceWindow, spec)
+	}
+	return minuteRange{start: start.Hour()*60 + start.Minute(), end: end.Hour()*60 + end.Minute()}, nil
+}
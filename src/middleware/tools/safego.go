@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/goroutine"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/metrics"
+)
+
+const (
+	SafeGoMinBackoff = goroutine.MinBackoff
+	SafeGoMaxBackoff = goroutine.MaxBackoff
+)
+
+// panic 写错误日志并计数，logger 包里的 goroutine 也会使用
+func init() {
+	goroutine.SetPanicHandler(func(name string, err interface{}, stack []byte) {
+		metrics.Incr("redis_manager_goroutine_panic_total", map[string]string{"name": name})
+		logger.Error("goroutine ", name, " panic: ", err, "\n", string(stack))
+	})
+}
+
+// 启动一个带panic恢复的goroutine
+func SafeGo(name string, fn func()) {
+	goroutine.Go(name, fn)
+}
+
+// 启动一个带panic恢复的goroutine，panic后按退避时间重启，正常退出则不再重启
+func SafeGoRestart(name string, fn func()) {
+	maxbackoff := time.Duration(cfg.Get_Info_Int("safegomaxbackoff")) * time.Second
+	goroutine.GoRestart(name, fn, maxbackoff, func(backoff time.Duration) {
+		logger.Warn("goroutine ", name, " restart after ", backoff)
+	})
+}
+
+// 执行fn并恢复panic，正常结束返回true
+func SafeRun(name string, fn func()) bool {
+	return goroutine.Run(name, fn)
+}
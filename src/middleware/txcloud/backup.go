@@ -0,0 +1,53 @@
+package txcloud
+
+import (
+	"errors"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tredis "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/redis/v20180412"
+)
+
+// 接口调用成功但是返回里缺少需要的字段
+var ErrEmptyResponse = errors.New("tencent api response missing field")
+
+// 发起一次手动备份，返回云上的任务ID，调用前需要先 TxRedisContent
+func TxManualBackup(instanceid, remark string) (int64, error) {
+	request := tredis.NewManualBackupInstanceRequest()
+	request.InstanceId = common.StringPtr(instanceid)
+	request.Remark = common.StringPtr(remark)
+	var response *tredis.ManualBackupInstanceResponse
+	err := TxClient.Do("ManualBackupInstance", func() (err error) {
+		response, err = TxRedisApi.ManualBackupInstance(request)
+		return err
+	})
+	if err != nil {
+		logger.Error("tx cloud redis ", instanceid, " manual backup error: ", err)
+		return 0, err
+	}
+	if response.Response == nil || response.Response.TaskId == nil {
+		logger.Error("tx cloud redis ", instanceid, " manual backup error: ", ErrEmptyResponse)
+		return 0, ErrEmptyResponse
+	}
+	return *response.Response.TaskId, nil
+}
+
+// 查询云上任务的状态，例如备份、切换任务
+func TxTaskStatus(taskid int64) (string, error) {
+	request := tredis.NewDescribeTaskInfoRequest()
+	request.TaskId = common.Uint64Ptr(uint64(taskid))
+	var response *tredis.DescribeTaskInfoResponse
+	err := TxClient.Do("DescribeTaskInfo", func() (err error) {
+		response, err = TxRedisApi.DescribeTaskInfo(request)
+		return err
+	})
+	if err != nil {
+		logger.Error("tx cloud redis task ", taskid, " status error: ", err)
+		return "", err
+	}
+	if response.Response == nil || response.Response.Status == nil {
+		logger.Error("tx cloud redis task ", taskid, " status error: ", ErrEmptyResponse)
+		return "", ErrEmptyResponse
+	}
+	return *response.Response.Status, nil
+}
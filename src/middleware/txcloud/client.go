@@ -0,0 +1,182 @@
+package txcloud
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	sdkerrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+)
+
+var ErrTencentTimeout = errors.New("tencent api call exceeds overall timeout")
+
+// 配置文件里没有配置时的默认值
+const (
+	DefaultTxTimeout    = 60 * time.Second
+	DefaultTxReqTimeout = 10 * time.Second
+	DefaultTxRetry      = 3
+	DefaultTxBackoff    = 500 * time.Millisecond
+	TxMaxBackoff        = 30 * time.Second
+	httpStatusErrorCode = "ClientError.HttpStatusCodeError"
+)
+
+// 腾讯云接口的统一调用入口，单次请求超时，5xx和限频时按 Retry-After 或指数退避重试
+type TencentClient struct {
+	Timeout        time.Duration // 包括重试在内的总超时
+	RequestTimeout time.Duration // 单次请求的超时
+	Retry          int           // 最多重试次数
+	MinBackoff     time.Duration // 第一次重试前的等待时间，之后翻倍
+
+	// 保护上面的配置，LoadConfig 和 Do 可能在不同的 goroutine 里同时调用
+	lock sync.RWMutex
+	// 所有 sdk client 共用一个 transport，同一时间只允许一次请求，保证读到的状态码属于本次请求
+	calllock  sync.Mutex
+	transport *retryAfterTransport
+}
+
+var TxClient = NewTencentClient()
+
+func NewTencentClient() *TencentClient {
+	return &TencentClient{
+		Timeout:        DefaultTxTimeout,
+		RequestTimeout: DefaultTxReqTimeout,
+		Retry:          DefaultTxRetry,
+		MinBackoff:     DefaultTxBackoff,
+		transport:      &retryAfterTransport{base: http.DefaultTransport},
+	}
+}
+
+// 从配置文件重新读取超时和重试次数，没配置的保持默认值
+func (c *TencentClient) LoadConfig() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if v := cfg.Get_Info_Int("txtimeout"); v > 0 {
+		c.Timeout = time.Duration(v) * time.Second
+	}
+	if v := cfg.Get_Info_Int("txreqtimeout"); v > 0 {
+		c.RequestTimeout = time.Duration(v) * time.Second
+	}
+	if v := cfg.Get_Info_Int("txretry"); v > 0 {
+		c.Retry = v
+	}
+	if v := cfg.Get_Info_Int("txbackoffms"); v > 0 {
+		c.MinBackoff = time.Duration(v) * time.Millisecond
+	}
+}
+
+// 单次请求的超时，创建 sdk client 时使用
+func (c *TencentClient) requestTimeout() time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.RequestTimeout
+}
+
+// 执行一次云接口调用，call 里调用 sdk 并返回错误
+func (c *TencentClient) Do(action string, call func() error) error {
+	c.lock.RLock()
+	timeout, maxretry, backoff := c.Timeout, c.Retry, c.MinBackoff
+	c.lock.RUnlock()
+	deadline := time.Now().Add(timeout)
+	for retry := 0; ; retry++ {
+		status, retryafter, err := c.attempt(call)
+		if err == nil {
+			return nil
+		}
+		if retry >= maxretry || !isRetryable(err, status) {
+			return err
+		}
+		wait := backoff
+		if retryafter > 0 {
+			wait = retryafter
+		}
+		if wait > TxMaxBackoff {
+			wait = TxMaxBackoff
+		}
+		if time.Now().Add(wait).After(deadline) {
+			logger.Error("tx cloud ", action, " retry exceeds timeout: ", err)
+			return ErrTencentTimeout
+		}
+		logger.Warn("tx cloud ", action, " retry ", retry+1, " after ", wait, ": ", err)
+		time.Sleep(wait)
+		backoff *= 2
+	}
+}
+
+// 请求和读取回包状态在同一把锁内完成，等待重试时不持有锁
+func (c *TencentClient) attempt(call func() error) (int, time.Duration, error) {
+	c.calllock.Lock()
+	defer c.calllock.Unlock()
+	c.transport.reset()
+	err := call()
+	status, retryafter := c.transport.last()
+	return status, retryafter, err
+}
+
+// 限频和服务端错误可以重试，参数错误、鉴权失败等直接返回
+func isRetryable(err error, status int) bool {
+	var sdkerr *sdkerrors.TencentCloudSDKError
+	if !errors.As(err, &sdkerr) {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(sdkerr.Code, limitExceedCode):
+		return true
+	case strings.HasPrefix(sdkerr.Code, "InternalError"):
+		return true
+	case sdkerr.Code == httpStatusErrorCode:
+		return status >= 500 || status == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// 记录最近一次回包的状态码和 Retry-After，sdk 的错误里拿不到header
+type retryAfterTransport struct {
+	base       http.RoundTripper
+	lock       sync.Mutex
+	status     int
+	retryafter time.Duration
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	t.lock.Lock()
+	t.status = resp.StatusCode
+	t.retryafter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	t.lock.Unlock()
+	return resp, nil
+}
+
+func (t *retryAfterTransport) reset() {
+	t.lock.Lock()
+	t.status = 0
+	t.retryafter = 0
+	t.lock.Unlock()
+}
+
+func (t *retryAfterTransport) last() (int, time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.status, t.retryafter
+}
+
+// Retry-After 可以是秒数，也可以是http时间
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
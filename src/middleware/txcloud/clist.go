@@ -1,19 +1,45 @@
 package txcloud
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
 	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
 	tredis "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/redis/v20180412"
 )
 
+// 翻页获取所有实例，合并成一个 DescribeInstances 的回包
 func TxListRedis() (string, bool) {
-	// 实例化一个请求对象,每个接口都会对应一个request对象
-	request := tredis.NewDescribeInstancesRequest()
-	// 返回的resp是一个DescribeInstancesResponse的实例，与请求对象对应
-	response, err := TxRedisApi.DescribeInstances(request)
+	var rlist model.TxL
+	items, err := Paginate(func(offset, limit int) ([]interface{}, int, error) {
+		// 实例化一个请求对象,每个接口都会对应一个request对象
+		request := tredis.NewDescribeInstancesRequest()
+		request.Offset = common.Uint64Ptr(uint64(offset))
+		request.Limit = common.Uint64Ptr(uint64(limit))
+		// 返回的resp是一个DescribeInstancesResponse的实例，与请求对象对应
+		var response *tredis.DescribeInstancesResponse
+		err := TxClient.Do("DescribeInstances", func() (err error) {
+			response, err = TxRedisApi.DescribeInstances(request)
+			return err
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		var page model.TxL
+		if err := json.Unmarshal([]byte(response.ToJsonString()), &page); err != nil {
+			return nil, 0, err
+		}
+		rlist.Response.RequestId = page.Response.RequestId
+		var result []interface{}
+		for _, v := range page.Response.InstanceSet {
+			result = append(result, v)
+		}
+		return result, page.Response.TotalCount, nil
+	})
 	if _, ok := err.(*errors.TencentCloudSDKError); ok {
 		logger.Error("An Redis API error has returned: ", err)
 		return "", false
@@ -22,15 +48,28 @@ func TxListRedis() (string, bool) {
 		logger.Error("List Tx Cloud Redis Error: ", err)
 		return "", false
 	}
+	for _, v := range items {
+		rlist.Response.InstanceSet = append(rlist.Response.InstanceSet, v.(model.TxLResponseInstanceSet))
+	}
+	rlist.Response.TotalCount = len(rlist.Response.InstanceSet)
+	result, err := json.Marshal(rlist)
+	if err != nil {
+		logger.Error("List Tx Cloud Redis Error: ", err)
+		return "", false
+	}
 	// 输出json格式的字符串回包
-	return response.ToJsonString(), true
+	return string(result), true
 }
 
 func TxListRegion() (string, bool) {
 	// 实例化一个请求对象,每个接口都会对应一个request对象
 	request := cvm.NewDescribeRegionsRequest()
 	// 返回的resp是一个DescribeRegionsResponse的实例，与请求对象对应
-	response, err := TxCvmApi.DescribeRegions(request)
+	var response *cvm.DescribeRegionsResponse
+	err := TxClient.Do("DescribeRegions", func() (err error) {
+		response, err = TxCvmApi.DescribeRegions(request)
+		return err
+	})
 	if _, ok := err.(*errors.TencentCloudSDKError); ok {
 		fmt.Printf("An Region API error has returned: %s", err)
 		return "", false
@@ -42,3 +81,13 @@ func TxListRegion() (string, bool) {
 	// 输出json格式的字符串回包
 	return response.ToJsonString(), true
 }
+
+// 只取一个实例，用来确认密钥可用
+func TxCheckCredential() error {
+	request := tredis.NewDescribeInstancesRequest()
+	request.Limit = common.Uint64Ptr(1)
+	return TxClient.Do("DescribeInstances", func() error {
+		_, err := TxRedisApi.DescribeInstances(request)
+		return err
+	})
+}
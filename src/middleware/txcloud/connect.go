@@ -1,6 +1,8 @@
 package txcloud
 
 import (
+	"time"
+
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
@@ -16,6 +18,7 @@ var TxRedisApi *tredis.Client
 
 func TxRedisContent(region string) bool {
 	var err error
+	TxClient.LoadConfig()
 	credential := common.NewCredential(
 		mysql.DB.GetOneCfgValue(model.TXSECRETID),
 		mysql.DB.GetOneCfgValue(model.TXSECRETKEY),
@@ -23,12 +26,14 @@ func TxRedisContent(region string) bool {
 	// 实例化一个client选项，可选的，没有特殊需求可以跳过
 	cpf := profile.NewClientProfile()
 	cpf.HttpProfile.Endpoint = mysql.DB.GetOneCfgValue(model.TXAPIURL)
+	cpf.HttpProfile.ReqTimeout = int(TxClient.requestTimeout() / time.Second)
 	// 实例化要请求产品的client对象,clientProfile是可选的
 	TxRedisApi, err = tredis.NewClient(credential, region, cpf)
 	if err != nil {
 		logger.Error("conenct tx cloud redis error: ", err)
 		return false
 	}
+	TxRedisApi.WithHttpTransport(TxClient.transport)
 	return true
 }
 
@@ -37,6 +42,7 @@ var TxCvmApi *cvm.Client
 
 func TxCvmContent() bool {
 	var err error
+	TxClient.LoadConfig()
 	credential := common.NewCredential(
 		mysql.DB.GetOneCfgValue(model.TXSECRETID),
 		mysql.DB.GetOneCfgValue(model.TXSECRETKEY),
@@ -44,12 +50,14 @@ func TxCvmContent() bool {
 	// 实例化一个client选项，可选的，没有特殊需求可以跳过
 	cpf := profile.NewClientProfile()
 	cpf.HttpProfile.Endpoint = "cvm.tencentcloudapi.com"
+	cpf.HttpProfile.ReqTimeout = int(TxClient.requestTimeout() / time.Second)
 	// 实例化要请求产品的client对象,clientProfile是可选的
 	TxCvmApi, err = cvm.NewClient(credential, "", cpf)
 	if err != nil {
 		logger.Error("conenct tx cloud region error: ", err)
 		return false
 	}
+	TxCvmApi.WithHttpTransport(TxClient.transport)
 	return true
 }
 
@@ -58,6 +66,7 @@ var TxDbrainApi *dbbrain.Client
 
 func TxDbrainContent(region string) bool {
 	var err error
+	TxClient.LoadConfig()
 	credential := common.NewCredential(
 		mysql.DB.GetOneCfgValue(model.TXSECRETID),
 		mysql.DB.GetOneCfgValue(model.TXSECRETKEY),
@@ -65,11 +74,13 @@ func TxDbrainContent(region string) bool {
 	// 实例化一个client选项，可选的，没有特殊需求可以跳过
 	cpf := profile.NewClientProfile()
 	cpf.HttpProfile.Endpoint = "dbbrain.tencentcloudapi.com"
+	cpf.HttpProfile.ReqTimeout = int(TxClient.requestTimeout() / time.Second)
 	// 实例化要请求产品的client对象,clientProfile是可选的
 	TxDbrainApi, err = dbbrain.NewClient(credential, region, cpf)
 	if err != nil {
 		logger.Error("conenct tx cloud region error: ", err)
 		return false
 	}
+	TxDbrainApi.WithHttpTransport(TxClient.transport)
 	return true
 }
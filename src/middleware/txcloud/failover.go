@@ -0,0 +1,27 @@
+package txcloud
+
+import (
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tredis "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/redis/v20180412"
+)
+
+// 把副本提升为主节点，返回云上的任务ID，调用前需要先 TxRedisContent
+func TxChangeReplicaToMaster(instanceid string) (int64, error) {
+	request := tredis.NewChangeReplicaToMasterRequest()
+	request.InstanceId = common.StringPtr(instanceid)
+	var response *tredis.ChangeReplicaToMasterResponse
+	err := TxClient.Do("ChangeReplicaToMaster", func() (err error) {
+		response, err = TxRedisApi.ChangeReplicaToMaster(request)
+		return err
+	})
+	if err != nil {
+		logger.Error("tx cloud redis ", instanceid, " change replica to master error: ", err)
+		return 0, err
+	}
+	if response.Response == nil || response.Response.TaskId == nil {
+		logger.Error("tx cloud redis ", instanceid, " change replica to master error: ", ErrEmptyResponse)
+		return 0, ErrEmptyResponse
+	}
+	return *response.Response.TaskId, nil
+}
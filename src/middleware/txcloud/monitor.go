@@ -0,0 +1,104 @@
+package txcloud
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+)
+
+var ErrUnknownMetric = errors.New("unknown cloud monitor metric")
+
+// 云监控 redis 内存版的命名空间和维度
+const (
+	TxMonitorNamespace = "QCE/REDIS_MEM"
+	TxMonitorDimension = "instanceid"
+)
+
+// 对外的指标名和云监控指标名的对应关系
+var TxMonitorMetrics = map[string]string{
+	"cpu":         "CpuUtil",
+	"memory":      "MemUtil",
+	"connections": "Connections",
+	"qps":         "Commands",
+}
+
+type DataPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// 根据查询时间长度选择统计粒度，云监控只支持固定的几个粒度
+func monitorGranularity(period time.Duration) int {
+	switch {
+	case period <= time.Hour:
+		return 60
+	case period <= 24*time.Hour:
+		return 300
+	}
+	return 3600
+}
+
+// 从云监控获取实例最近 period 时间内的指标，实例连不上的时候也可以看到云上的数据
+func TxCloudMetrics(region, instanceid, metric string, period time.Duration) ([]DataPoint, error) {
+	metricname, ok := TxMonitorMetrics[metric]
+	if !ok {
+		return nil, ErrUnknownMetric
+	}
+	TxClient.LoadConfig()
+	credential := common.NewCredential(
+		mysql.DB.GetOneCfgValue(model.TXSECRETID),
+		mysql.DB.GetOneCfgValue(model.TXSECRETKEY),
+	)
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Endpoint = "monitor.tencentcloudapi.com"
+	cpf.HttpProfile.ReqTimeout = int(TxClient.requestTimeout() / time.Second)
+	client := common.NewCommonClient(credential, region, cpf)
+	client.WithHttpTransport(TxClient.transport)
+
+	now := time.Now()
+	request := tchttp.NewCommonRequest("monitor", "2018-07-24", "GetMonitorData")
+	err := request.SetActionParameters(map[string]interface{}{
+		"Namespace":  TxMonitorNamespace,
+		"MetricName": metricname,
+		"Period":     monitorGranularity(period),
+		"StartTime":  now.Add(-period).Format(time.RFC3339),
+		"EndTime":    now.Format(time.RFC3339),
+		"Instances": []map[string]interface{}{
+			{"Dimensions": []map[string]string{{"Name": TxMonitorDimension, "Value": instanceid}}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var response *tchttp.CommonResponse
+	err = TxClient.Do("GetMonitorData", func() error {
+		response = tchttp.NewCommonResponse()
+		return client.Send(request, response)
+	})
+	if err != nil {
+		logger.Error("tx cloud monitor ", instanceid, " ", metricname, " error: ", err)
+		return nil, err
+	}
+	var monitor model.TxMonitorData
+	if err := json.Unmarshal(response.GetBody(), &monitor); err != nil {
+		logger.Error("json files tx monitor error: ", err)
+		return nil, err
+	}
+	var result []DataPoint
+	for _, v := range monitor.Response.DataPoints {
+		for i := range v.Timestamps {
+			if i >= len(v.Values) {
+				break
+			}
+			result = append(result, DataPoint{Timestamp: v.Timestamps[i], Value: v.Values[i]})
+		}
+	}
+	return result, nil
+}
@@ -1,11 +1,10 @@
 package txcloud
 
 import (
-	"fmt"
 	"time"
 
+	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
-	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
 	dbbrain "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/dbbrain/v20210527"
 	tredis "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/redis/v20180412"
 )
@@ -19,13 +18,14 @@ func TxHostKey(instanceid string) (string, bool) {
 	request.SpanType = common.Int64Ptr(1)
 
 	// 返回的resp是一个DescribeInstanceMonitorHotKeyResponse的实例，与请求对象对应
-	response, err := TxRedisApi.DescribeInstanceMonitorHotKey(request)
-	if _, ok := err.(*errors.TencentCloudSDKError); ok {
-		fmt.Printf("An API error has returned: %s", err)
-		return "", false
-	}
+	var response *tredis.DescribeInstanceMonitorHotKeyResponse
+	err := TxClient.Do("DescribeInstanceMonitorHotKey", func() (err error) {
+		response, err = TxRedisApi.DescribeInstanceMonitorHotKey(request)
+		return err
+	})
 	if err != nil {
-		panic(err)
+		logger.Error("tx cloud hot key ", instanceid, " error: ", err)
+		return "", false
 	}
 	// 输出json格式的字符串回包
 	return response.ToJsonString(), true
@@ -40,13 +40,14 @@ func TxProxySlowKey(instanceid, starttime, endtime string) (string, bool) {
 	request.EndTime = common.StringPtr(endtime)
 
 	// 返回的resp是一个DescribeProxySlowLogResponse的实例，与请求对象对应
-	response, err := TxRedisApi.DescribeProxySlowLog(request)
-	if _, ok := err.(*errors.TencentCloudSDKError); ok {
-		fmt.Printf("An API error has returned: %s", err)
-		return "", false
-	}
+	var response *tredis.DescribeProxySlowLogResponse
+	err := TxClient.Do("DescribeProxySlowLog", func() (err error) {
+		response, err = TxRedisApi.DescribeProxySlowLog(request)
+		return err
+	})
 	if err != nil {
-		panic(err)
+		logger.Error("tx cloud proxy slow log ", instanceid, " error: ", err)
+		return "", false
 	}
 	// 输出json格式的字符串回包
 	return response.ToJsonString(), true
@@ -61,13 +62,14 @@ func TxRedisSlowKey(instanceid, starttime, endtime string) (string, bool) {
 	request.EndTime = common.StringPtr(endtime)
 
 	// 返回的resp是一个DescribeSlowLogResponse的实例，与请求对象对应
-	response, err := TxRedisApi.DescribeSlowLog(request)
-	if _, ok := err.(*errors.TencentCloudSDKError); ok {
-		fmt.Printf("An API error has returned: %s", err)
-		return "", false
-	}
+	var response *tredis.DescribeSlowLogResponse
+	err := TxClient.Do("DescribeSlowLog", func() (err error) {
+		response, err = TxRedisApi.DescribeSlowLog(request)
+		return err
+	})
 	if err != nil {
-		panic(err)
+		logger.Error("tx cloud slow log ", instanceid, " error: ", err)
+		return "", false
 	}
 	// 输出json格式的字符串回包
 	return response.ToJsonString(), true
@@ -83,13 +85,14 @@ func TxBigKey(instanceid string) (string, bool) {
 	request.Product = common.StringPtr("redis")
 
 	// 返回的resp是一个DescribeRedisTopBigKeysResponse的实例，与请求对象对应
-	response, err := TxDbrainApi.DescribeRedisTopBigKeys(request)
-	if _, ok := err.(*errors.TencentCloudSDKError); ok {
-		fmt.Printf("An API error has returned: %s", err)
-		return "", false
-	}
+	var response *dbbrain.DescribeRedisTopBigKeysResponse
+	err := TxClient.Do("DescribeRedisTopBigKeys", func() (err error) {
+		response, err = TxDbrainApi.DescribeRedisTopBigKeys(request)
+		return err
+	})
 	if err != nil {
-		panic(err)
+		logger.Error("tx cloud big key ", instanceid, " error: ", err)
+		return "", false
 	}
 	// 输出json格式的字符串回包	return response.ToJsonString(), true
 	return response.ToJsonString(), true
@@ -0,0 +1,32 @@
+package txcloud
+
+import (
+	"errors"
+)
+
+var ErrTooManyPages = errors.New("tencent api pagination exceeds max pages")
+
+const (
+	PageLimit       = 100 // 每页个数
+	PageMax         = 100 // 最多翻页次数，防止死循环
+	limitExceedCode = "RequestLimitExceeded"
+)
+
+// 翻页获取所有数据，fetch 返回本页的数据和总数
+// 被限频时的重试由 fetch 里的 TxClient 处理
+func Paginate(fetch func(offset, limit int) ([]interface{}, int, error)) ([]interface{}, error) {
+	var result []interface{}
+	offset := 0
+	for page := 0; page < PageMax; page++ {
+		items, total, err := fetch(offset, PageLimit)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, items...)
+		offset += len(items)
+		if len(items) == 0 || offset >= total {
+			return result, nil
+		}
+	}
+	return result, ErrTooManyPages
+}
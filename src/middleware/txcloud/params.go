@@ -0,0 +1,26 @@
+package txcloud
+
+import (
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tredis "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/redis/v20180412"
+)
+
+// 获取实例当前的参数配置
+func TxInstanceParams(instanceid string) (string, bool) {
+	request := tredis.NewDescribeInstanceParamsRequest()
+
+	request.InstanceId = common.StringPtr(instanceid)
+
+	var response *tredis.DescribeInstanceParamsResponse
+	err := TxClient.Do("DescribeInstanceParams", func() (err error) {
+		response, err = TxRedisApi.DescribeInstanceParams(request)
+		return err
+	})
+	if err != nil {
+		logger.Error("tx describe instance params error: ", err)
+		return "", false
+	}
+	// 输出json格式的字符串回包
+	return response.ToJsonString(), true
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/events"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+	"github.com/iguidao/redis-manager/src/middleware/txcloud"
+)
+
+// local-bgsave 等待保存完成的最长时间
+const bgsaveWaitTimeout = 10 * time.Minute
+
+var (
+	ErrUnknownBackupType    = errors.New("unknown backup type")
+	ErrBackupNoTarget       = errors.New("backup policy target is empty")
+	ErrBackupNoRegion       = errors.New("cloud-managed backup policy region is empty")
+	ErrBackupNoCredential   = errors.New("cloud-managed backup needs tx_secretid and tx_secretkey")
+	ErrBackupTargetNotFound = errors.New("backup target not found")
+)
+
+// 读取配置的备份策略
+func BackupPolicies() ([]model.BackupPolicy, bool) {
+	var policies []model.BackupPolicy
+	value := mysql.DB.GetOneCfgValue(model.BACKUPPOLICY)
+	if value == "" {
+		return policies, true
+	}
+	if err := json.Unmarshal([]byte(value), &policies); err != nil {
+		logger.Error("backup policy unmarshal error: ", err)
+		return nil, false
+	}
+	return policies, true
+}
+
+// 检查策略，cloud-managed 需要配置了腾讯云的密钥
+func ValidateBackupPolicy(policy model.BackupPolicy) error {
+	if policy.Target == "" {
+		return ErrBackupNoTarget
+	}
+	switch policy.Type {
+	case model.BACKUPLOCAL:
+		return nil
+	case model.BACKUPCLOUD:
+		if policy.Region == "" {
+			return ErrBackupNoRegion
+		}
+		if mysql.DB.GetOneCfgValue(model.TXSECRETID) == "" || mysql.DB.GetOneCfgValue(model.TXSECRETKEY) == "" {
+			return ErrBackupNoCredential
+		}
+		return nil
+	}
+	return ErrUnknownBackupType
+}
+
+// 按策略类型执行 bgsave 或者云上的手动备份
+func RunBackup(policy model.BackupPolicy) model.BackupResult {
+	result := model.BackupResult{Policy: policy.Name, Type: policy.Type, Target: policy.Target, Start: time.Now()}
+	events.Publish(events.BACKUPSTARTED, policy.Target, map[string]string{"policy": policy.Name, "type": policy.Type})
+	defer func() {
+		events.Publish(events.BACKUPFINISHED, policy.Target, map[string]string{"policy": policy.Name, "type": policy.Type, "success": strconv.FormatBool(result.Success), "error": result.Error})
+	}()
+	err := ValidateBackupPolicy(policy)
+	if err == nil {
+		switch policy.Type {
+		case model.BACKUPLOCAL:
+			result.Rdb, err = localBackup(policy.Target)
+		case model.BACKUPCLOUD:
+			result.TaskId, err = cloudBackup(policy)
+		}
+	}
+	result.Duration = time.Since(result.Start).Seconds()
+	err = opredis.NewOpError(policy.Target, "backup", err)
+	if err != nil {
+		logger.Error("backup policy ", policy.Name, " target: ", policy.Target, " error: ", err)
+		result.Error = err.Error()
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// 等待 bgsave 完成，返回rdb文件的信息
+func localBackup(target string) (*model.BgsaveResult, error) {
+	for _, v := range opredis.FleetTargets() {
+		if v.Id != target && v.Addr != target {
+			continue
+		}
+		rdb, err := opredis.BgsaveAndWait(context.Background(), v, bgsaveWaitTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return &rdb, nil
+	}
+	return nil, ErrBackupTargetNotFound
+}
+
+func cloudBackup(policy model.BackupPolicy) (int64, error) {
+	if !txcloud.TxRedisContent(policy.Region) {
+		return 0, ErrBackupNoCredential
+	}
+	return txcloud.TxManualBackup(policy.Target, "redis-manager "+policy.Name)
+}
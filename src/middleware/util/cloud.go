@@ -1,6 +1,7 @@
 package util
 
 import (
+	"github.com/iguidao/redis-manager/src/middleware/events"
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
@@ -12,6 +13,7 @@ func TxWriteRedis(cloud string, rlist model.TxL) {
 			id, ok := mysql.DB.AddTxCloudRedis(cloud, v)
 			if ok {
 				logger.Info("write ", cloud, " redis to mysql ok: ", id, "instanceid: ", v.InstanceId)
+				events.Publish(events.INSTANCEREGISTERED, v.InstanceId, map[string]string{"type": cloud, "name": v.InstanceName})
 			} else {
 				logger.Error("write ", cloud, " redis to mysql false: ", id, "instanceid: ", v.InstanceId)
 			}
@@ -32,6 +34,7 @@ func AliWriteRedis(cloud string, rlist model.AliRedis) {
 			id, ok := mysql.DB.AddAliCloudRedis(cloud, v)
 			if ok {
 				logger.Info("write ", cloud, " redis to mysql ok: ", id, "instanceid: ", v.InstanceId)
+				events.Publish(events.INSTANCEREGISTERED, v.InstanceId, map[string]string{"type": cloud, "name": v.InstanceName})
 			} else {
 				logger.Error("write ", cloud, " redis to mysql false: ", id, "instanceid: ", v.InstanceId)
 			}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/txcloud"
+)
+
+// 默认不导出的参数，运行时会变化或者跟实例绑定
+var DefaultConfigExclude = []string{"requirepass", "masterauth", "bind", "port", "dir", "dbfilename", "appendfilename", "pidfile", "logfile", "unixsocket", "cluster-config-file"}
+
+// 导出时排除的参数，配置了 config_exclude 时使用配置的列表
+func ConfigExclude() map[string]bool {
+	exclude := make(map[string]bool)
+	list := DefaultConfigExclude
+	if value := mysql.DB.GetOneCfgValue(model.CONFIGEXCLUDE); value != "" {
+		list = strings.Split(value, ",")
+	}
+	for _, v := range list {
+		exclude[strings.TrimSpace(v)] = true
+	}
+	return exclude
+}
+
+// 导出腾讯云实例当前的参数，作为配置管理的基线
+func ExportDesiredConfig(region, instanceid string) (map[string]string, bool) {
+	if !txcloud.TxRedisContent(region) {
+		return nil, false
+	}
+	txresult, ok := txcloud.TxInstanceParams(instanceid)
+	if !ok {
+		return nil, false
+	}
+	var params model.TxParams
+	if err := json.Unmarshal([]byte(txresult), &params); err != nil {
+		logger.Error("json files tx params error: ", err)
+		return nil, false
+	}
+	exclude := ConfigExclude()
+	result := make(map[string]string)
+	for _, list := range [][]model.TxParam{
+		params.Response.InstanceEnumParam,
+		params.Response.InstanceIntegerParam,
+		params.Response.InstanceTextParam,
+		params.Response.InstanceMultiParam,
+	} {
+		for _, v := range list {
+			if exclude[v.ParamName] {
+				continue
+			}
+			result[v.ParamName] = v.CurrentValue
+		}
+	}
+	return result, true
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+)
+
+// 实际生效的配置，密钥类的值都已经隐藏，可以直接贴到issue里
+type EffectiveConfig struct {
+	Time       time.Time              `json:"time"`
+	ConfigFile string                 `json:"config_file"`
+	File       map[string]interface{} `json:"file"`    // 配置文件
+	Runtime    map[string]string      `json:"runtime"` // 保存在mysql里的配置
+	Logger     map[string]interface{} `json:"logger"`  // 日志的实际配置，包括运行中修改的级别
+	Providers  map[string]bool        `json:"providers"`
+	Instances  []EffectiveInstance    `json:"instances"`
+}
+
+type EffectiveInstance struct {
+	Type     string `json:"type"`
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Addr     string `json:"addr"`
+	Password string `json:"password"`
+}
+
+// 输出当前生效的配置，密钥类配置和实例密码用 model.Redacted 代替
+func DumpEffectiveConfig(w io.Writer) error {
+	result := EffectiveConfig{
+		Time:       time.Now(),
+		ConfigFile: cfg.ConfigFile(),
+		File:       redactSettings(cfg.AllSettings()),
+		Runtime:    make(map[string]string),
+		Logger: map[string]interface{}{
+			"level":  logger.Level(),
+			"format": cfg.Get_Info_String("logformat"),
+			"fields": cfg.Get_Info_Map("logfields"),
+		},
+	}
+	for _, v := range mysql.DB.GetAllCfg() {
+		result.Runtime[v.Key] = redactValue(v.Key, v.Value)
+	}
+	result.Providers = map[string]bool{
+		"txredis":  result.Runtime[model.TXSECRETID] != "",
+		"aliredis": result.Runtime[model.ALIACCESSKEYID] != "",
+		"codis":    mysql.DB.GetCodisNumber() > 0,
+		"cluster":  mysql.DB.GetClusterNumber() > 0,
+	}
+	for _, v := range opredis.FleetTargets() {
+		result.Instances = append(result.Instances, EffectiveInstance{
+			Type:     v.Type,
+			Id:       v.Id,
+			Name:     v.Name,
+			Addr:     v.Addr,
+			Password: redactValue("password", v.Password),
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// 递归隐藏配置文件里的密钥
+func redactSettings(settings map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(settings))
+	for k, v := range settings {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			result[k] = redactSettings(value)
+		case string:
+			result[k] = redactValue(k, value)
+		default:
+			if model.IsSecretKey(k) {
+				result[k] = model.Redacted
+			} else {
+				result[k] = v
+			}
+		}
+	}
+	return result
+}
+
+// 为空的值保持为空，方便看出有没有配置
+func redactValue(key, value string) string {
+	if value == "" || !model.IsSecretKey(key) {
+		return value
+	}
+	return model.Redacted
+}
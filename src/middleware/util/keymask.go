@@ -0,0 +1,115 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+)
+
+const keyMaskReplace = "***"
+
+// key 脱敏，匹配到正则的部分替换成 ***，只处理返回给用户的结果
+type KeyMasker struct {
+	patterns []*regexp.Regexp
+}
+
+// 读取实例的脱敏正则，实例没有配置时使用全局配置
+func LoadKeyMask(target string) KeyMasker {
+	var masker KeyMasker
+	value := ""
+	if target != "" {
+		value = mysql.DB.GetOneCfgValue(model.KEYMASK + ":" + target)
+	}
+	if value == "" {
+		value = mysql.DB.GetOneCfgValue(model.KEYMASK)
+	}
+	for _, v := range strings.Split(value, ";") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		re, err := regexp.Compile(v)
+		if err != nil {
+			logger.Error("key mask regexp ", v, " error: ", err)
+			continue
+		}
+		masker.patterns = append(masker.patterns, re)
+	}
+	return masker
+}
+
+func (m KeyMasker) Mask(keyname string) string {
+	for _, re := range m.patterns {
+		keyname = re.ReplaceAllString(keyname, keyMaskReplace)
+	}
+	return keyname
+}
+
+// 对返回结果里的key脱敏，不认识的结果原样返回
+func (m KeyMasker) MaskResult(result interface{}) interface{} {
+	if len(m.patterns) == 0 {
+		return result
+	}
+	switch v := result.(type) {
+	case opredis.ColdKeyResult:
+		keys := make([]opredis.ColdKey, len(v.Keys))
+		for i, key := range v.Keys {
+			key.Key = m.Mask(key.Key)
+			keys[i] = key
+		}
+		v.Keys = keys
+		return v
+	case opredis.ScanResult:
+		keys := make([]string, len(v.Keys))
+		for i, key := range v.Keys {
+			keys[i] = m.Mask(key)
+		}
+		v.Keys = keys
+		return v
+	case opredis.NoTtlResult:
+		keys := make([]string, len(v.Keys))
+		for i, key := range v.Keys {
+			keys[i] = m.Mask(key)
+		}
+		v.Keys = keys
+		return v
+	case opredis.KeyDetail:
+		v.Key = m.Mask(v.Key)
+		return v
+	case opredis.CollectionSampleResult:
+		v.Key = m.Mask(v.Key)
+		return v
+	case opredis.CardinalityResult:
+		v.Key = m.Mask(v.Key)
+		return v
+	case []opredis.CardinalityResult:
+		list := make([]opredis.CardinalityResult, len(v))
+		for i, item := range v {
+			item.Key = m.Mask(item.Key)
+			list[i] = item
+		}
+		return list
+	case []opredis.HotKeyStat:
+		list := make([]opredis.HotKeyStat, len(v))
+		for i, item := range v {
+			item.Key = m.Mask(item.Key)
+			list[i] = item
+		}
+		return list
+	case map[string][]string:
+		groups := make(map[string][]string, len(v))
+		for hash, keys := range v {
+			masked := make([]string, len(keys))
+			for i, key := range keys {
+				masked[i] = m.Mask(key)
+			}
+			groups[hash] = masked
+		}
+		return groups
+	}
+	return result
+}
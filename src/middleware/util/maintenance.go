@@ -0,0 +1,28 @@
+package util
+
+import (
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
+)
+
+// 当前是否在维护窗口内，不在的时候记录日志，配置错误时不拦截
+func InMaintenanceWindow(opname string) bool {
+	spec := mysql.DB.GetOneCfgValue(model.MAINTENANCEWINDOW)
+	if spec == "" {
+		return true
+	}
+	window, err := tools.ParseMaintenanceWindow(spec, mysql.DB.GetOneCfgValue(model.MAINTENANCETZ))
+	if err != nil {
+		logger.Error("维护窗口配置错误: ", err)
+		return true
+	}
+	if window.InWindow(time.Now()) {
+		return true
+	}
+	logger.Warn("不在维护窗口内，推迟执行: ", opname, " 维护窗口: ", spec)
+	return false
+}
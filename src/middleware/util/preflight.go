@@ -0,0 +1,82 @@
+package util
+
+import (
+	"context"
+	"errors"
+
+	"github.com/iguidao/redis-manager/src/cfg"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+	"github.com/iguidao/redis-manager/src/middleware/txcloud"
+)
+
+// 检查管理用的redis、所有实例和腾讯云密钥是否可用
+func Preflight(ctx context.Context) model.PreflightReport {
+	var report model.PreflightReport
+	report.Checks = append(report.Checks, redisCheck(ctx, "manager_redis", opredis.FleetTarget{
+		Id:       "manager",
+		Addr:     cfg.Get_Info_String("REDIS"),
+		Password: cfg.Get_Info_String("redispw"),
+	}))
+	for _, v := range opredis.FleetTargets() {
+		report.Checks = append(report.Checks, redisCheck(ctx, "redis", v))
+	}
+	report.Checks = append(report.Checks, txCloudChecks()...)
+	report.Passed = true
+	for _, v := range report.Checks {
+		if !v.Passed {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+func redisCheck(ctx context.Context, name string, target opredis.FleetTarget) model.PreflightCheck {
+	check := model.PreflightCheck{Name: name, Target: target.Id + " " + target.Addr, Passed: true}
+	err := opredis.ProbeTarget(ctx, target)
+	if err == nil {
+		return check
+	}
+	check.Passed = false
+	check.Error = err.Error()
+	switch {
+	case errors.Is(err, opredis.ErrInvalidEndpoint):
+		check.Hint = "地址格式错误，使用 host:port、[ipv6]:port 或 unix:///path"
+	case errors.Is(err, opredis.ErrAuthRequired):
+		check.Hint = "实例需要密码，请配置密码"
+	case errors.Is(err, opredis.ErrAuthFailed):
+		check.Hint = "密码错误或者ACL没有权限，请检查密码和ACL"
+	default:
+		check.Hint = "网络不通或实例没有启动，请检查地址、端口和安全组"
+	}
+	return check
+}
+
+// 每个有腾讯云实例的地域调用一次 DescribeInstances
+func txCloudChecks() []model.PreflightCheck {
+	var result []model.PreflightCheck
+	if mysql.DB.GetOneCfgValue(model.TXSECRETID) == "" {
+		return result
+	}
+	regions := make(map[string]bool)
+	for _, v := range mysql.DB.GetCloudRegion() {
+		if v.Cloud == "txredis" {
+			regions[v.Region] = true
+		}
+	}
+	for region := range regions {
+		check := model.PreflightCheck{Name: "txcloud", Target: region, Passed: true}
+		if !txcloud.TxRedisContent(region) {
+			check.Passed = false
+			check.Error = "connect tx cloud redis api failed"
+			check.Hint = "请检查 " + model.TXAPIURL + " 配置"
+		} else if err := txcloud.TxCheckCredential(); err != nil {
+			check.Passed = false
+			check.Error = err.Error()
+			check.Hint = "请检查 " + model.TXSECRETID + "、" + model.TXSECRETKEY + " 和账号的 QcloudRedisFullAccess 权限"
+		}
+		result = append(result, check)
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"context"
+	"errors"
+
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+	"github.com/iguidao/redis-manager/src/middleware/txcloud"
+)
+
+var (
+	ErrPromoteTargetNotFound = errors.New("promote target not found")
+	ErrPromoteNotSupported   = errors.New("promote is not supported for this instance type")
+	ErrPromoteNoCredential   = errors.New("cloud-managed promote needs tx_secretid and tx_secretkey")
+)
+
+// 提升从库，自建cluster节点按节点ID，腾讯云按实例ID调用云上的切换接口
+func PromoteReplica(ctx context.Context, replicaID string) error {
+	for _, v := range opredis.FleetTargets() {
+		if v.Id != replicaID {
+			continue
+		}
+		switch v.Type {
+		case "cluster":
+			return opredis.PromoteNode(ctx, v)
+		case "txredis":
+			// 云上的接口没有链接可以限制，只读用户直接拒绝
+			if opredis.IsReadOnlyContext(ctx) {
+				return opredis.NewOpError(replicaID, "promote", opredis.ErrReadOnlyConnection)
+			}
+			return txPromote(replicaID)
+		}
+		return opredis.NewOpError(replicaID, "promote", ErrPromoteNotSupported)
+	}
+	return opredis.NewOpError(replicaID, "promote", ErrPromoteTargetNotFound)
+}
+
+func txPromote(instanceid string) error {
+	info, ok := mysql.DB.GetCloudInfo("txredis", instanceid)
+	if !ok {
+		return opredis.NewOpError(instanceid, "promote", ErrPromoteTargetNotFound)
+	}
+	if !txcloud.TxRedisContent(info.Region) {
+		return opredis.NewOpError(instanceid, "promote", ErrPromoteNoCredential)
+	}
+	taskid, err := txcloud.TxChangeReplicaToMaster(instanceid)
+	if err != nil {
+		return opredis.NewOpError(instanceid, "promote", err)
+	}
+	logger.Warn("腾讯云redis ", instanceid, " 提升副本任务: ", taskid)
+	return nil
+}
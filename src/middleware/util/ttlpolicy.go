@@ -0,0 +1,111 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/iguidao/redis-manager/src/middleware/alert"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/model"
+	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+)
+
+// 没有配置采样个数时的默认值
+const defaultTTLPolicySample = 10000
+
+var (
+	ErrTTLPolicyInvalid = errors.New("ttl policy default_ttl is invalid")
+	ErrTTLPolicyScan    = errors.New("ttl policy scan failed")
+)
+
+// 读取实例的TTL检查策略，按实例的配置优先，默认只告警不修改
+func InstanceTTLPolicy(id string) (model.TTLPolicy, error) {
+	policy := model.TTLPolicy{Pattern: "*", Sample: defaultTTLPolicySample}
+	value := mysql.DB.GetOneCfgValue(model.TTLPOLICY + ":" + id)
+	if value == "" {
+		value = mysql.DB.GetOneCfgValue(model.TTLPOLICY)
+	}
+	if value == "" {
+		return policy, nil
+	}
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		return policy, err
+	}
+	if policy.Pattern == "" {
+		policy.Pattern = "*"
+	}
+	if policy.Sample <= 0 {
+		policy.Sample = defaultTTLPolicySample
+	}
+	return policy, nil
+}
+
+// 检查所有打了 policy=ttl-required 标签的 master，有没有过期时间的key时告警
+// 开启 auto_fix 后在维护窗口内给匹配的key补上 default_ttl
+func EnforceTTLPolicies() []model.TTLPolicyResult {
+	var results []model.TTLPolicyResult
+	for _, v := range opredis.FleetTargets() {
+		if opredis.InstanceLabels(v.Id)["policy"] != model.TTLREQUIRED {
+			continue
+		}
+		// 从库上的key由主库同步，只检查和修改 master
+		role, err := opredis.TargetRole(context.Background(), v)
+		if err != nil {
+			results = append(results, model.TTLPolicyResult{Instance: v.Id, Addr: v.Addr, DryRun: true, Error: err.Error()})
+			continue
+		}
+		if !opredis.ROLEMASTER.Match(role) {
+			continue
+		}
+		results = append(results, enforceTTLPolicy(v))
+	}
+	return results
+}
+
+func enforceTTLPolicy(target opredis.FleetTarget) model.TTLPolicyResult {
+	result := model.TTLPolicyResult{Instance: target.Id, Addr: target.Addr, DryRun: true}
+	policy, err := InstanceTTLPolicy(target.Id)
+	if err != nil {
+		logger.Error("ttl policy unmarshal error: ", err)
+		result.Error = err.Error()
+		return result
+	}
+	var ttl time.Duration
+	if policy.AutoFix {
+		if ttl, err = time.ParseDuration(policy.DefaultTTL); err != nil || ttl <= 0 {
+			logger.Error("ttl policy ", target.Id, " error: ", ErrTTLPolicyInvalid)
+			result.Error = ErrTTLPolicyInvalid.Error()
+			return result
+		}
+	}
+	// 不在维护窗口内时只告警，下次执行时再修改
+	result.DryRun = !policy.AutoFix || !InMaintenanceWindow("ttl policy "+target.Id)
+	// 每个实例单独的链接，不影响全局的 RD，只告警时用只读链接
+	rd, err := opredis.TargetClient(target, result.DryRun)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer opredis.ReleaseClient(rd)
+	nottl, ok := opredis.KeysWithoutTtl(rd, policy.Sample)
+	if !ok {
+		result.Error = ErrTTLPolicyScan.Error()
+		return result
+	}
+	result.Sampled = nottl.Sampled
+	result.NoTtl = nottl.NoTtl
+	result.Estimated = nottl.Estimated
+	alert.Check("ttl_missing", target.Id, nottl.NoTtl > 0, float64(nottl.Estimated), 0, target.Addr+" 有没有过期时间的key")
+	if nottl.NoTtl == 0 || result.DryRun {
+		return result
+	}
+	fixed, err := opredis.SetTTLByPattern(context.Background(), rd, target.Addr, policy.Pattern, ttl, false)
+	result.Fixed = fixed
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -10,8 +10,8 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
-	"github.com/iguidao/redis-manager/src/cfg"
 	"github.com/iguidao/redis-manager/src/middleware/jwt"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	v1 "github.com/iguidao/redis-manager/src/rhttp/v1"
 )
@@ -19,9 +19,12 @@ import (
 // NewServer return a configured http server of gin
 func NewServer() *gin.Engine {
 	// 存储日志文件代码
-	logpath := "./logs/" + cfg.Get_Info_String("logapipath")
 	gin.DisableConsoleColor()
-	f, _ := os.Create(logpath)
+	f, err := logger.ApiLogWriter()
+	if err != nil {
+		logger.Error("create api log err: ", err)
+		f = os.Stdout
+	}
 	gin.DefaultWriter = io.MultiWriter(f)
 	r := gin.Default()
 
@@ -61,6 +64,7 @@ func NewServer() *gin.Engine {
 	base := r.Group("/redis-manager/base/v1")
 	{
 		base.GET("/health", v1.HealthCheck) //自检接口
+		base.GET("/metrics", v1.Metrics)    //prometheus 指标
 	}
 	login := r.Group("/redis-manager/auth/v1")
 	{
@@ -80,12 +84,18 @@ func NewServer() *gin.Engine {
 	board := r.Group(model.PATHBOARD)
 	board.Use(jwt.JWT())
 	{
-		board.GET("/desc", v1.BoardDesc) //board页面
+		board.GET("/desc", v1.BoardDesc)           //board页面
+		board.GET("/fleet", v1.BoardFleet)         //所有实例的概要信息
+		board.GET("/eviction", v1.BoardEviction)   //有驱逐的实例
+		board.GET("/preflight", v1.BoardPreflight) //连通性检查
+		board.GET("/trend", v1.BoardTrend)         //实例指标的趋势
+		board.GET("/alerts", v1.BoardAlerts)       //正在告警的列表
 	}
 	history := r.Group(model.PATHHISTORY)
 	history.Use(jwt.JWT())
 	{
-		history.GET("/list", v1.OpHistory) //查看历史操作记录
+		history.GET("/list", v1.OpHistory)    //查看历史操作记录
+		history.GET("/journal", v1.OpJournal) //查看操作流水
 	}
 	cfg := r.Group(model.PATHCFG)
 	cfg.Use(jwt.JWT())
@@ -114,19 +124,24 @@ func NewServer() *gin.Engine {
 		cloud.POST("/size", v1.ChangeSize)              //修改集群大小
 		cloud.POST("/add", v1.CloudAdd)                 // 添加集群
 		cloud.DELETE("/del", v1.CloudDel)               //删除集群
+		cloud.GET("/config", v1.CloudConfigExport)      //导出实例参数
+		cloud.GET("/metrics", v1.CloudMetrics)          //云监控指标
 	}
 	cluster := r.Group(model.PATHCLUSTER)
 	cluster.Use(jwt.JWT())
 	{
-		cluster.GET("/list", v1.ClusterList)   //列出所有集群
-		cluster.GET("/nodes", v1.NodeList)     // 列出集群的node
-		cluster.GET("/masters", v1.MasterList) //列出master地址
-		cluster.POST("/add", v1.ClusterAdd)    //添加集群
+		cluster.GET("/list", v1.ClusterList)               //列出所有集群
+		cluster.GET("/nodes", v1.NodeList)                 // 列出集群的node
+		cluster.GET("/masters", v1.MasterList)             //列出master地址
+		cluster.GET("/health", v1.ClusterHealth)           //检查集群slot覆盖和节点状态
+		cluster.GET("/rebalance", v1.ClusterRebalancePlan) //slot迁移计划
+		cluster.POST("/add", v1.ClusterAdd)                //添加集群
 	}
 	cli := r.Group(model.PATHCLI)
 	cli.Use(jwt.JWT())
 	{
 		cli.POST("/opkey", v1.OpKey) //对key进行操作
+		cli.POST("/batch", v1.Batch) //批量执行只读查询
 	}
 	user := r.Group(model.PATHUSER)
 	user.Use(jwt.JWT())
@@ -146,6 +161,12 @@ func NewServer() *gin.Engine {
 		rule.GET("/cfg", v1.GetRuleCfg) //查看默认配置
 	}
 
+	logs := r.Group(model.PATHLOGS)
+	logs.Use(jwt.JWT())
+	{
+		logs.GET("/:level", v1.TailLog) //查看不低于该级别的日志，follow=1时持续输出
+	}
+
 	r.NoMethod(v1.MethodFails)
 	r.NoRoute(v1.RouterNotFound)
 	return r
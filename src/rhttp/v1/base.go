@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/iguidao/redis-manager/src/hsc"
+	"github.com/iguidao/redis-manager/src/middleware/metrics"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,6 +23,16 @@ func HealthCheck(c *gin.Context) {
 	// c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
+// prometheus 抓取的指标
+func Metrics(c *gin.Context) {
+	text, err := metrics.MetricsText(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(text))
+}
+
 func HandleNotFound(c *gin.Context) {
 	code := hsc.NOT_FOUND
 	c.JSON(http.StatusOK, gin.H{
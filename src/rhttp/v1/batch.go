@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iguidao/redis-manager/src/hsc"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+)
+
+const (
+	batchMaxItems   = 100
+	batchMaxTimeout = 30
+)
+
+// 一次请求执行多个只读查询，timeout 为整个批次的超时秒数，默认10秒
+func Batch(c *gin.Context) {
+	var items []opredis.BatchItem
+	var result interface{}
+	code := hsc.SUCCESS
+	timeout, err := strconv.Atoi(c.DefaultQuery("timeout", "10"))
+	if err != nil || timeout <= 0 || timeout > batchMaxTimeout {
+		timeout = 10
+	}
+	if err := c.BindJSON(&items); err != nil {
+		logger.Error("Batch Bind Json error: ", err)
+		code = hsc.INVALID_PARAMS
+	} else if len(items) > batchMaxItems {
+		code = hsc.INVALID_PARAMS
+		result = "一次最多 " + strconv.Itoa(batchMaxItems) + " 个请求"
+	} else {
+		result = opredis.Batch(c.Request.Context(), items, time.Duration(timeout)*time.Second)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
@@ -2,10 +2,17 @@ package v1
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iguidao/redis-manager/src/hsc"
+	"github.com/iguidao/redis-manager/src/middleware/alert"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/opredis"
+	"github.com/iguidao/redis-manager/src/middleware/snapshot"
+	"github.com/iguidao/redis-manager/src/middleware/util"
 )
 
 func BoardDesc(c *gin.Context) {
@@ -21,3 +28,79 @@ func BoardDesc(c *gin.Context) {
 		"data":      result,
 	})
 }
+
+// 所有实例的概要信息，用于首页展示
+func BoardFleet(c *gin.Context) {
+	code := hsc.SUCCESS
+	targets := opredis.FleetTargets()
+	result := opredis.FleetSummary(c.Request.Context(), targets)
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
+
+// 窗口内有驱逐的实例
+func BoardEviction(c *gin.Context) {
+	code := hsc.SUCCESS
+	result := opredis.EvictingInstances(opredis.FleetTargets())
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
+
+// 实例指标的趋势，instance 为实例ID或地址，window 为秒数，默认一天
+func BoardTrend(c *gin.Context) {
+	code := hsc.SUCCESS
+	var result interface{}
+	window := 24 * time.Hour
+	if v := c.Query("window"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			code = hsc.INVALID_PARAMS
+		}
+		window = time.Duration(sec) * time.Second
+	}
+	if c.Query("instance") == "" {
+		code = hsc.INVALID_PARAMS
+	}
+	if code == hsc.SUCCESS {
+		points, err := snapshot.Trend(c.Query("instance"), c.Query("metric"), window)
+		if err == snapshot.ErrUnknownMetric {
+			code = hsc.INVALID_PARAMS
+		} else if err != nil {
+			logger.Error("Read snapshot trend error: ", err)
+			code = hsc.ERROR
+		}
+		result = points
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
+
+// 正在告警的列表
+func BoardAlerts(c *gin.Context) {
+	code := hsc.SUCCESS
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      alert.Firing(),
+	})
+}
+
+// 连通性检查
+func BoardPreflight(c *gin.Context) {
+	code := hsc.SUCCESS
+	result := util.Preflight(c.Request.Context())
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
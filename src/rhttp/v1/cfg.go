@@ -9,6 +9,7 @@ import (
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
 )
 
 func CfgListDefault(c *gin.Context) {
@@ -48,7 +49,7 @@ func CfgAddDefault(c *gin.Context) {
 			urlinfo := c.Request.URL
 			jsonBody, _ := json.Marshal(cfg)
 			method := c.Request.Method
-			go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+			tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 			_, ok := mysql.DB.AddCfg(model.CC, cfg.Value, model.CN)
 			if !ok {
 				logger.Error("add cfg error")
@@ -91,7 +92,7 @@ func CfgUpdate(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(cfg)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		name := model.DefaultName[cfg.Key]
 		if mysql.DB.ExistCfg(cfg.Key) {
 			if !mysql.DB.UpdateCfg(cfg.Key, cfg.Value) {
@@ -121,7 +122,7 @@ func CfgDelete(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(key)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		if !mysql.DB.DelCfg(key) {
 			result = false
 			code = hsc.SERVER_ERROR
@@ -1,8 +1,13 @@
 package v1
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -10,14 +15,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/iguidao/redis-manager/src/cfg"
 	"github.com/iguidao/redis-manager/src/hsc"
+	"github.com/iguidao/redis-manager/src/middleware/cluster"
 	"github.com/iguidao/redis-manager/src/middleware/codisapi"
 	"github.com/iguidao/redis-manager/src/middleware/cosop"
+	"github.com/iguidao/redis-manager/src/middleware/journal"
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
 	"github.com/iguidao/redis-manager/src/middleware/opredis"
 	"github.com/iguidao/redis-manager/src/middleware/tools"
 	"github.com/iguidao/redis-manager/src/middleware/txcloud"
+	"github.com/iguidao/redis-manager/src/middleware/util"
 )
 
 func OpKey(c *gin.Context) {
@@ -30,6 +38,17 @@ func OpKey(c *gin.Context) {
 	if err != nil {
 		logger.Error("Op Key Bind Json error: ", err)
 		code = hsc.INVALID_PARAMS
+	} else if cliquery.CacheType == "cluster" && cliquery.DB != 0 {
+		code = hsc.WARN_CLUSTER_ONLY_DB0
+		result = opredis.ErrClusterDB.Error()
+	} else if exporterr := checkOutput(cliquery); exporterr != nil {
+		code = hsc.INVALID_PARAMS
+		result = exporterr.Error()
+	} else if tools.CheckStringInArray(cliquery.CacheOp, confirmOpList) && !Confirmed(cliquery) {
+		code = hsc.WARN_NEED_CONFIRM
+		result = "请在 confirm 填写目标ID：" + ConfirmTarget(cliquery)
+	} else if tools.CheckStringInArray(cliquery.CacheOp, heavyOpList) && !util.InMaintenanceWindow(cliquery.CacheOp) {
+		code = hsc.WARN_NOT_IN_WINDOW
 	} else if !opredis.LockCheck(cliquery.CacheOp+"-"+cliquery.CacheType+"-"+cliquery.ClusterName+"-"+cliquery.KeyName, locaktime) {
 		logger.Error(cliquery.CacheOp + "-" + cliquery.CacheType + "-" + cliquery.ClusterName + "-" + cliquery.KeyName + " Key click repeatedly")
 		code = hsc.WARN_CLICK_REPEATEDLY
@@ -39,7 +58,10 @@ func OpKey(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(cliquery)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
+		usertype, _ := c.Get("UserType")
+		cliquery.ReadOnly = usertype == model.USERTYPEVISITOR
+		start := time.Now()
 		if cliquery.CacheType == "codis" {
 			code = hsc.ERROR_NO_CONNEC
 			result, ok = CodisOp(cliquery)
@@ -59,7 +81,25 @@ func OpKey(c *gin.Context) {
 				code = hsc.SUCCESS
 			}
 		}
-		go opredis.LockRm(cliquery.CacheOp + "-" + cliquery.CacheType + "-" + cliquery.ClusterName + "-" + cliquery.KeyName)
+		if tools.CheckStringInArray(cliquery.CacheOp, journalOpList) {
+			actor, _ := c.Get("UserName")
+			entry := journalEntry(cliquery, code, result, time.Since(start))
+			entry.Actor = fmt.Sprint(actor)
+			logger.With("op", entry.Op, "instance", entry.Instance, "actor", entry.Actor, logger.Args("args", journalArgs(cliquery), journalArgMax)).Info("mutating op: ", entry.Result)
+			tools.SafeGo("journal", func() { journal.Record(entry) })
+		}
+		if code == hsc.SUCCESS && cliquery.Output != "" {
+			var buf bytes.Buffer
+			if err := opredis.ExportReport(&buf, cliquery.CacheOp, result, cliquery.Output); err != nil {
+				logger.Error("Export report error: ", err)
+				code = hsc.INVALID_PARAMS
+			} else {
+				result = buf.String()
+			}
+		}
+		tools.SafeGo("lockrm", func() {
+			opredis.LockRm(cliquery.CacheOp + "-" + cliquery.CacheType + "-" + cliquery.ClusterName + "-" + cliquery.KeyName)
+		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"errorCode": code,
@@ -67,12 +107,33 @@ func OpKey(c *gin.Context) {
 		"data":      result,
 	})
 }
+
+// 只有报表类操作可以导出，在执行前检查，避免修改类操作执行完才报错
+func checkOutput(cliquery CliQuery) error {
+	if cliquery.Output == "" {
+		return nil
+	}
+	return opredis.CheckExport(cliquery.CacheOp, cliquery.Output)
+}
+
+// 访客使用只读链接，并选择要操作的逻辑库
+func connectRedis(cliquery CliQuery, addr, pw string) bool {
+	return opredis.DialRedis(addr, pw, cliquery.DB, cliquery.ReadOnly) == nil
+}
+
+func connectRedisCluster(cliquery CliQuery, addr []string, pw string) bool {
+	if cliquery.ReadOnly {
+		return opredis.ConnectRedisClusterReadOnly(addr, pw)
+	}
+	return opredis.ConnectRedisCluster(addr, pw)
+}
+
 func ClusterOp(cliquery CliQuery) (interface{}, bool) {
 	switch cliquery.CacheOp {
 	case "query":
 		address, pw := mysql.DB.GetClusterAddress(cliquery.ClusterId)
 		addlist := strings.Split(address, ",")
-		if opredis.ConnectRedisCluster(addlist, pw) {
+		if connectRedisCluster(cliquery, addlist, pw) {
 			result := opredis.CQueryKey(cliquery.KeyName)
 			return result, true
 		}
@@ -80,12 +141,22 @@ func ClusterOp(cliquery CliQuery) (interface{}, bool) {
 	case "hot":
 		serverip := mysql.DB.GetClusterNodeMasterAddress(cliquery.NodeId)
 		pw := mysql.DB.GetClusterPassword(cliquery.ClusterId)
+		release, err := acquireNodeOp(serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
 		result := opredis.HotKey(serverip, pw)
 		return result, true
 	case "all":
 		serverip := mysql.DB.GetClusterNodeSlaverAddress(cliquery.NodeId)
 		pw := mysql.DB.GetClusterPassword(cliquery.ClusterId)
-		if opredis.ConnectRedis(serverip, pw) {
+		release, err := acquireNodeOp(serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
+		if connectRedis(cliquery, serverip, pw) {
 			result := opredis.AllKey()
 			return result, true
 		}
@@ -93,7 +164,7 @@ func ClusterOp(cliquery CliQuery) (interface{}, bool) {
 	case "slow":
 		serverip := mysql.DB.GetClusterNodeMasterAddress(cliquery.NodeId)
 		pw := mysql.DB.GetClusterPassword(cliquery.ClusterId)
-		if opredis.ConnectRedis(serverip, pw) {
+		if connectRedis(cliquery, serverip, pw) {
 			result := opredis.SlowKey()
 			return result, true
 		}
@@ -101,7 +172,7 @@ func ClusterOp(cliquery CliQuery) (interface{}, bool) {
 	case "del":
 		address, pw := mysql.DB.GetClusterAddress(cliquery.ClusterId)
 		addlist := strings.Split(address, ",")
-		if opredis.ConnectRedisCluster(addlist, pw) {
+		if connectRedisCluster(cliquery, addlist, pw) {
 			result := opredis.CDeleteKey(cliquery.KeyName)
 			return result, true
 		}
@@ -110,6 +181,11 @@ func ClusterOp(cliquery CliQuery) (interface{}, bool) {
 		result := make(map[string]interface{})
 		serverip := mysql.DB.GetClusterNodeSlaverAddress(cliquery.NodeId)
 		pw := mysql.DB.GetClusterPassword(cliquery.ClusterId)
+		release, err := acquireNodeOp(serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
 		if opredis.ConnectRedis(cfg.Get_Info_String("REDIS"), cfg.Get_Info_String("redispw")) {
 			clickkeyname := "Click-Bigkey-" + cliquery.CacheType + "-" + cliquery.ClusterId + "-" + cliquery.NodeId
 			tips, ok := opredis.BigKeyClick(cliquery.ClusterId, cliquery.NodeId, clickkeyname)
@@ -120,8 +196,10 @@ func ClusterOp(cliquery CliQuery) (interface{}, bool) {
 			case 1:
 				result["友情提示"] = tips
 				opredis.ExpireKey(clickkeyname, cfg.Get_Info_Int("biglocktime"))
-				if opredis.ConnectRedis(serverip, pw) {
-					opredis.RedisSave(serverip)
+				if connectRedis(cliquery, serverip, pw) {
+					if err := opredis.SafeBgsave(serverip); err != nil {
+						logger.Error("ip: ", serverip, " bgsave error: ", err)
+					}
 				}
 			case 2:
 				result["友情提示"] = tips
@@ -138,7 +216,7 @@ func ClusterOp(cliquery CliQuery) (interface{}, bool) {
 		}
 		return nil, false
 	default:
-		return "没有找到这个查询key的方式: " + cliquery.CacheOp, false
+		return NodeOp(cliquery)
 	}
 }
 
@@ -147,7 +225,7 @@ func CodisOp(cliquery CliQuery) (interface{}, bool) {
 	case "query":
 		proxylist := codisapi.GetProxy(cliquery.CodisUrl, cliquery.ClusterName)
 		for _, v := range proxylist {
-			if opredis.ConnectRedis(v, "") {
+			if connectRedis(cliquery, v, "") {
 				result := opredis.QueryKey(cliquery.KeyName)
 				return result, true
 			}
@@ -155,18 +233,28 @@ func CodisOp(cliquery CliQuery) (interface{}, bool) {
 		return nil, false
 	case "hot":
 		serverip := codisapi.GetMaster(cliquery.CodisUrl, cliquery.ClusterName, cliquery.GroupName)
+		release, err := acquireNodeOp(serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
 		result := opredis.HotKey(serverip, "")
 		return result, true
 	case "all":
 		serverip := codisapi.GetSlave(cliquery.CodisUrl, cliquery.ClusterName, cliquery.GroupName)
-		if opredis.ConnectRedis(serverip, "") {
+		release, err := acquireNodeOp(serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
+		if connectRedis(cliquery, serverip, "") {
 			result := opredis.AllKey()
 			return result, true
 		}
 		return nil, false
 	case "slow":
 		serverip := codisapi.GetMaster(cliquery.CodisUrl, cliquery.ClusterName, cliquery.GroupName)
-		if opredis.ConnectRedis(serverip, "") {
+		if connectRedis(cliquery, serverip, "") {
 			result := opredis.SlowKey()
 			return result, true
 		}
@@ -174,7 +262,7 @@ func CodisOp(cliquery CliQuery) (interface{}, bool) {
 	case "del":
 		proxylist := codisapi.GetProxy(cliquery.CodisUrl, cliquery.ClusterName)
 		for _, v := range proxylist {
-			if opredis.ConnectRedis(v, "") {
+			if connectRedis(cliquery, v, "") {
 				result := opredis.DeleteKey(cliquery.KeyName)
 				return result, true
 			}
@@ -183,6 +271,11 @@ func CodisOp(cliquery CliQuery) (interface{}, bool) {
 	case "big":
 		result := make(map[string]interface{})
 		serverip := codisapi.GetSlave(cliquery.CodisUrl, cliquery.ClusterName, cliquery.GroupName)
+		release, err := acquireNodeOp(serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
 		if opredis.ConnectRedis(cfg.Get_Info_String("REDIS"), cfg.Get_Info_String("redispw")) {
 			clickkeyname := "Click-Bigkey-" + cliquery.CacheType + "-" + cliquery.ClusterName + "-" + cliquery.GroupName
 			tips, ok := opredis.BigKeyClick(cliquery.ClusterName, cliquery.GroupName, clickkeyname)
@@ -193,8 +286,10 @@ func CodisOp(cliquery CliQuery) (interface{}, bool) {
 			case 1:
 				result["友情提示"] = tips
 				opredis.ExpireKey(clickkeyname, cfg.Get_Info_Int("biglocktime"))
-				if opredis.ConnectRedis(serverip, "") {
-					opredis.RedisSave(serverip)
+				if connectRedis(cliquery, serverip, "") {
+					if err := opredis.SafeBgsave(serverip); err != nil {
+						logger.Error("ip: ", serverip, " bgsave error: ", err)
+					}
 				}
 			case 2:
 				result["友情提示"] = tips
@@ -211,7 +306,7 @@ func CodisOp(cliquery CliQuery) (interface{}, bool) {
 		}
 		return nil, false
 	default:
-		return "没有找到这个查询key的方式: " + cliquery.CacheOp, false
+		return NodeOp(cliquery)
 	}
 
 }
@@ -220,12 +315,18 @@ func TxRedisOp(cliquery CliQuery) (interface{}, bool) {
 	case "query":
 		pw, ip, port := mysql.DB.GetCloudAddress(cliquery.CacheType, cliquery.InstanceId)
 		sport := strconv.Itoa(port)
-		if opredis.ConnectRedis(ip+":"+sport, pw) {
+		if connectRedis(cliquery, ip+":"+sport, pw) {
 			result := opredis.QueryKey(cliquery.KeyName)
 			return result, true
 		}
 		return nil, false
 	case "hot":
+		_, ip, port := mysql.DB.GetCloudAddress(cliquery.CacheType, cliquery.InstanceId)
+		release, err := acquireNodeOp(net.JoinHostPort(ip, strconv.Itoa(port)))
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
 		if !txcloud.TxRedisContent(cliquery.Region) {
 			return nil, false
 		} else {
@@ -244,7 +345,12 @@ func TxRedisOp(cliquery CliQuery) (interface{}, bool) {
 	case "all":
 		pw, ip, port := mysql.DB.GetCloudAddress(cliquery.CacheType, cliquery.InstanceId)
 		sport := strconv.Itoa(port)
-		if opredis.ConnectRedis(ip+":"+sport, pw) {
+		release, err := acquireNodeOp(ip + ":" + sport)
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
+		if connectRedis(cliquery, ip+":"+sport, pw) {
 			result := opredis.AllKey()
 			return result, true
 		}
@@ -284,12 +390,18 @@ func TxRedisOp(cliquery CliQuery) (interface{}, bool) {
 	case "del":
 		pw, ip, port := mysql.DB.GetCloudAddress(cliquery.CacheType, cliquery.InstanceId)
 		sport := strconv.Itoa(port)
-		if opredis.ConnectRedis(ip+":"+sport, pw) {
+		if connectRedis(cliquery, ip+":"+sport, pw) {
 			result := opredis.DeleteKey(cliquery.KeyName)
 			return result, true
 		}
 		return nil, false
 	case "big":
+		_, ip, port := mysql.DB.GetCloudAddress(cliquery.CacheType, cliquery.InstanceId)
+		release, err := acquireNodeOp(net.JoinHostPort(ip, strconv.Itoa(port)))
+		if err != nil {
+			return err.Error(), false
+		}
+		defer release()
 		if !txcloud.TxDbrainContent(cliquery.Region) {
 			return nil, false
 		} else {
@@ -306,7 +418,7 @@ func TxRedisOp(cliquery CliQuery) (interface{}, bool) {
 		}
 		return nil, false
 	default:
-		return "没有找到这个查询key的方式: " + cliquery.CacheOp, false
+		return NodeOp(cliquery)
 	}
 }
 func AnalysisRdb(c *gin.Context) {
@@ -320,9 +432,9 @@ func AnalysisRdb(c *gin.Context) {
 	} else {
 		if cosop.CosGet(clirdb.RdbName, "/tmp/"+clirdb.RdbName) {
 			if opredis.ConnectRedis(cfg.Get_Info_String("REDIS"), cfg.Get_Info_String("redispw")) {
-				go func() {
+				tools.SafeGo("analysisrdb", func() {
 					opredis.Analysis("/tmp/"+clirdb.RdbName, "bigkey-"+clirdb.ServerIp)
-				}()
+				})
 			}
 		}
 	}
@@ -332,6 +444,421 @@ func AnalysisRdb(c *gin.Context) {
 		"data":      result,
 	})
 }
+
+// 单节点操作的列表
+var nodeOpList = []string{"cold", "aofrewrite", "types", "scripts", "kill", "cmdstats", "copy", "configsnapshot", "configapply", "configrestore", "cardinality", "infocapture", "infodiff", "keydetail", "delete", "memory", "scan", "monitor", "nottl", "reload", "slowlogreset", "expire", "promote", "ttlhistogram", "functions", "functionload", "functiondelete", "clusterfailover", "duplicates", "hotkeys", "idlehistogram", "sample", "permissions"}
+
+// 需要确认的危险操作
+var confirmOpList = []string{"kill", "configapply", "configrestore", "delete", "monitor", "reload", "slowlogreset", "promote", "functionload", "functiondelete", "clusterfailover", "hotkeys"}
+
+// 只能在维护窗口内执行的重操作
+var heavyOpList = []string{"big", "all", "aofrewrite", "cold", "types", "nottl", "ttlhistogram", "duplicates", "hotkeys", "idlehistogram"}
+
+// 修改类操作，记录到操作流水
+var journalOpList = []string{"del", "kill", "copy", "aofrewrite", "configapply", "configrestore", "delete", "reload", "slowlogreset", "promote", "functionload", "functiondelete", "clusterfailover"}
+
+// 流水里单个参数最多保留的字节数
+const journalArgMax = 256
+
+// 参数按 name=value 记录，密钥类参数的值隐藏，写入时再截断
+func journalArgs(cliquery CliQuery) []string {
+	var names []string
+	for k := range cliquery.Params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	args := []string{"key=" + cliquery.KeyName}
+	for _, k := range names {
+		value := cliquery.Params[k]
+		if model.IsSecretKey(k) {
+			value = model.Redacted
+		}
+		args = append(args, k+"="+value)
+	}
+	return args
+}
+
+func journalEntry(cliquery CliQuery, code int, result interface{}, duration time.Duration) journal.JournalEntry {
+	instance := ConfirmTarget(cliquery)
+	if instance == "" {
+		instance = cliquery.ClusterName
+	}
+	msg := hsc.GetMsg(code)
+	if errmsg, ok := result.(string); ok && code != hsc.SUCCESS {
+		msg += ": " + errmsg
+	}
+	return journal.JournalEntry{
+		Time:       time.Now(),
+		Instance:   cliquery.CacheType + "/" + instance,
+		Op:         cliquery.CacheOp,
+		Args:       strings.Join(logger.TruncateArgs(journalArgs(cliquery), journalArgMax), " "),
+		Result:     msg,
+		DurationMs: duration.Milliseconds(),
+	}
+}
+
+// 确认时需要填写的目标ID
+func ConfirmTarget(cliquery CliQuery) string {
+	switch cliquery.CacheType {
+	case "cluster":
+		return cliquery.NodeId
+	case "codis":
+		return cliquery.GroupName
+	}
+	return cliquery.InstanceId
+}
+
+func Confirmed(cliquery CliQuery) bool {
+	target := ConfirmTarget(cliquery)
+	return target != "" && cliquery.Confirm == target
+}
+
+// 单节点操作，连接到目标redis节点后执行
+func NodeOp(cliquery CliQuery) (interface{}, bool) {
+	if !tools.CheckStringInArray(cliquery.CacheOp, nodeOpList) {
+		return "没有找到这个查询key的方式: " + cliquery.CacheOp, false
+	}
+	// 提升从库单独建立链接，腾讯云实例调用云上的接口，params.timeout 为等待从库追上的秒数
+	if cliquery.CacheOp == "promote" {
+		promotectx, cancel := context.WithTimeout(opredis.WithReadOnly(context.Background(), cliquery.ReadOnly), time.Duration(ParamInt(cliquery, "timeout", 30))*time.Second)
+		defer cancel()
+		if err := util.PromoteReplica(promotectx, ConfirmTarget(cliquery)); err != nil {
+			return err.Error(), false
+		}
+		return "ok", true
+	}
+	serverip, pw := NodeAddress(cliquery)
+	if serverip == "" {
+		return nil, false
+	}
+	release, err := acquireNodeOp(serverip)
+	if err != nil {
+		return err.Error(), false
+	}
+	defer release()
+	if err = opredis.DialRedis(serverip, pw, cliquery.DB, cliquery.ReadOnly); err != nil {
+		if opredis.IsAuthError(err) {
+			return err.Error(), false
+		}
+		return nil, false
+	}
+	result, ok := nodeOpRun(cliquery, serverip, pw)
+	// params.mask 为 true 时按实例配置的正则对返回的key脱敏
+	if ok && cliquery.Params["mask"] == "true" {
+		result = util.LoadKeyMask(ConfirmTarget(cliquery)).MaskResult(result)
+	}
+	return result, ok
+}
+
+func nodeOpRun(cliquery CliQuery, serverip, pw string) (interface{}, bool) {
+	switch cliquery.CacheOp {
+	case "cold":
+		idle := time.Duration(ParamInt(cliquery, "idle", 86400)) * time.Second
+		return opredis.ColdKeys(serverip, idle, int64(ParamInt(cliquery, "freq", 0)), ParamInt(cliquery, "limit", 100))
+	case "types":
+		return opredis.TypeHistogram(ParamInt(cliquery, "sample", 1000))
+	case "nottl":
+		// cluster 的 params.replica 为 true 时按权重从从库采样，没有可用的从库时读master
+		if cliquery.CacheType == "cluster" && cliquery.Params["replica"] == "true" {
+			reader, err := opredis.GetReader(cliquery.NodeId)
+			if err != nil {
+				return err.Error(), false
+			}
+			defer opredis.ReleaseClient(reader)
+			return opredis.KeysWithoutTtl(reader, ParamInt(cliquery, "sample", 1000))
+		}
+		return opredis.KeysWithoutTtl(opredis.RD.Client, ParamInt(cliquery, "sample", 1000))
+	case "scripts":
+		return opredis.RunningScripts()
+	case "cmdstats":
+		return opredis.CommandStats()
+	case "cardinality":
+		// params.pattern 不为空时按pattern批量统计
+		if pattern := cliquery.Params["pattern"]; pattern != "" {
+			return opredis.CardinalityByPattern(pattern, ParamInt(cliquery, "limit", 100))
+		}
+		result, err := opredis.Cardinality(cliquery.KeyName)
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "scan":
+		// params: match、type、count、cursor，返回下一次的cursor
+		cursor, _ := strconv.ParseUint(cliquery.Params["cursor"], 10, 64)
+		keys, next, ok := opredis.ScanKeys(cursor, cliquery.Params["match"], int64(ParamInt(cliquery, "count", 1000)), cliquery.Params["type"])
+		if !ok {
+			return nil, false
+		}
+		return opredis.ScanResult{Keys: keys, Cursor: next}, true
+	case "monitor":
+		// params.duration 秒，最多60秒；params.limit 最多返回的条数
+		duration := time.Duration(ParamInt(cliquery, "duration", 10)) * time.Second
+		limit := ParamInt(cliquery, "limit", 1000)
+		monitorctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := opredis.Monitor(monitorctx, ConfirmTarget(cliquery), duration)
+		if err != nil {
+			return err.Error(), false
+		}
+		var lines []opredis.MonitorLine
+		for line := range ch {
+			lines = append(lines, line)
+			if len(lines) >= limit {
+				cancel()
+				break
+			}
+		}
+		return lines, true
+	case "reload":
+		result, err := opredis.ReloadFromDisk(serverip, ConfirmTarget(cliquery))
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "slowlogreset":
+		count, err := opredis.ResetSlowlog(opredis.WithReadOnly(context.Background(), cliquery.ReadOnly), ConfirmTarget(cliquery))
+		if err != nil {
+			return err.Error(), false
+		}
+		return map[string]int{"cleared": count}, true
+	case "keydetail":
+		result, err := opredis.GetKeyDetail(cliquery.KeyName)
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "permissions":
+		// params.commands 为逗号分隔的命令，可以带子命令，例如 config|set,bgsave，为空时检查常用的管理命令
+		var commands []string
+		if cliquery.Params["commands"] != "" {
+			for _, v := range strings.Split(cliquery.Params["commands"], ",") {
+				commands = append(commands, strings.TrimSpace(v))
+			}
+		}
+		result, err := opredis.CheckPermissions(context.Background(), serverip, commands)
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "sample":
+		// 查看集合类型key的前 params.count 个元素，最多1000个
+		samplectx, cancel := context.WithTimeout(context.Background(), opredis.DefaultTimeout()*10)
+		defer cancel()
+		result, err := opredis.CollectionSample(samplectx, serverip, cliquery.KeyName, ParamInt(cliquery, "count", 100))
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "duplicates":
+		// 采样 params.sample 个 string key，只比较不超过 params.maxbytes 字节的值
+		timeout := time.Duration(ParamInt(cliquery, "timeout", 60)) * time.Second
+		dupctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		result, err := opredis.FindDuplicateValues(dupctx, serverip, ParamInt(cliquery, "maxbytes", 1024), ParamInt(cliquery, "sample", 10000))
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "hotkeys":
+		// LFU策略时读取 OBJECT FREQ，否则 MONITOR 采样 params.duration 秒，最多60秒
+		duration := time.Duration(ParamInt(cliquery, "duration", 10)) * time.Second
+		timeout := time.Duration(ParamInt(cliquery, "timeout", 60)) * time.Second
+		hotctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		result, err := opredis.HotKeys(hotctx, ConfirmTarget(cliquery), serverip, duration)
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "idlehistogram":
+		// 采样 params.sample 个key，params.buckets 为逗号分隔的分界点，例如 1m,1h,24h
+		buckets, err := ParamDurations(cliquery, "buckets")
+		if err != nil {
+			return err.Error(), false
+		}
+		timeout := time.Duration(ParamInt(cliquery, "timeout", 60)) * time.Second
+		histctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		result, err := opredis.IdleTimeHistogram(histctx, serverip, ParamInt(cliquery, "sample", 10000), buckets)
+		if err != nil {
+			return map[string]interface{}{"histogram": result, "error": err.Error()}, false
+		}
+		return result, true
+	case "ttlhistogram":
+		// params.match 为key的pattern，params.buckets 为逗号分隔的分界点，例如 1m,1h,24h
+		buckets, err := ParamDurations(cliquery, "buckets")
+		if err != nil {
+			return err.Error(), false
+		}
+		timeout := time.Duration(ParamInt(cliquery, "timeout", 60)) * time.Second
+		histctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		result, err := opredis.TTLHistogram(histctx, serverip, cliquery.Params["match"], buckets)
+		if err != nil {
+			return map[string]interface{}{"histogram": result, "error": err.Error()}, false
+		}
+		return result, true
+	case "clusterfailover":
+		// params.mode 为空、force 或 takeover，params.settle 为切换后等待拓扑稳定的秒数
+		if cliquery.CacheType != "cluster" {
+			return "clusterfailover 只支持 cluster", false
+		}
+		settle := time.Duration(ParamInt(cliquery, "settle", 3)) * time.Second
+		failoverctx, cancel := context.WithTimeout(opredis.WithReadOnly(context.Background(), cliquery.ReadOnly), settle+30*time.Second)
+		defer cancel()
+		result, err := opredis.ClusterFailover(failoverctx, cliquery.NodeId, cliquery.Params["mode"], settle)
+		if err != nil {
+			return err.Error(), false
+		}
+		return map[string]interface{}{"failover": result, "health": cluster.CheckHealth(result.Nodes, result.ClusterInfo)}, true
+	case "functions":
+		libs, err := opredis.ListFunctions(context.Background(), serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		stats, err := opredis.GetFunctionStats(context.Background(), serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		return map[string]interface{}{"libraries": libs, "stats": stats}, true
+	case "functionload":
+		// params.code 为函数库的源码，params.replace 为 true 时覆盖同名的库
+		if cliquery.Params["code"] == "" {
+			return "params.code 不能为空", false
+		}
+		name, err := opredis.LoadFunction(cliquery.Params["code"], cliquery.Params["replace"] == "true")
+		if err != nil {
+			return err.Error(), false
+		}
+		return name, true
+	case "functiondelete":
+		if cliquery.Params["name"] == "" {
+			return "params.name 不能为空", false
+		}
+		if err := opredis.DeleteFunction(cliquery.Params["name"]); err != nil {
+			return err.Error(), false
+		}
+		return "ok", true
+	case "expire":
+		result, err := opredis.GetExpireSettings(context.Background(), serverip)
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "memory":
+		result, err := opredis.MemoryOverhead()
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "infocapture":
+		return opredis.CaptureInfo(serverip)
+	case "infodiff":
+		// params 为修改前 infocapture 返回的 fields
+		after, ok := opredis.CaptureInfo(serverip)
+		if !ok {
+			return nil, false
+		}
+		return opredis.DiffInfo(opredis.InfoStats{Addr: serverip, Fields: cliquery.Params}, after), true
+	case "configsnapshot":
+		return opredis.SnapshotConfig(serverip)
+	case "configapply":
+		// params 为要修改的配置，返回修改前的快照
+		result, err := opredis.ApplyConfig(serverip, cliquery.Params)
+		if err != nil {
+			return err.Error(), false
+		}
+		return result, true
+	case "configrestore":
+		// params 为快照里的配置
+		if err := opredis.RestoreConfig(opredis.ConfigSnapshot{Addr: serverip, Params: cliquery.Params}); err != nil {
+			return err.Error(), false
+		}
+		return "ok", true
+	case "copy":
+		if cliquery.KeyName == "" || cliquery.Params["dst"] == "" {
+			return "key_name 和 params.dst 不能为空", false
+		}
+		ttl := time.Duration(ParamInt(cliquery, "ttl", 0)) * time.Second
+		if err := opredis.CopyKey(cliquery.KeyName, cliquery.Params["dst"], cliquery.Params["replace"] == "true", ttl); err != nil {
+			return err.Error(), false
+		}
+		return "ok", true
+	case "delete":
+		// params.keys 为逗号分隔的key列表，一般来自冷key、大key的结果
+		if cliquery.Params["keys"] == "" {
+			return "params.keys 不能为空", false
+		}
+		keys := strings.Split(cliquery.Params["keys"], ",")
+		deleted, err := opredis.DeleteKeys(context.Background(), serverip, keys, cliquery.Params["unlink"] == "true")
+		if err != nil {
+			return map[string]interface{}{"deleted": deleted, "error": err.Error()}, false
+		}
+		return map[string]interface{}{"deleted": deleted}, true
+	case "kill":
+		if err := opredis.KillCommand(cliquery.Params["type"]); err != nil {
+			return err.Error(), false
+		}
+		return "ok", true
+	case "aofrewrite":
+		timeout := time.Duration(ParamInt(cliquery, "timeout", 600)) * time.Second
+		aofctx, cancel := context.WithTimeout(opredis.WithReadOnly(context.Background(), cliquery.ReadOnly), timeout)
+		defer cancel()
+		if err := opredis.RewriteAOF(aofctx, serverip, pw); err != nil {
+			logger.Error("ip: ", serverip, " bgrewriteaof error: ", err)
+			return err.Error(), false
+		}
+		return "ok", true
+	}
+	return nil, false
+}
+
+// 同一个实例同时执行的操作个数有限制，等待时间不超过操作锁的时间，用完调用返回的函数释放
+func acquireNodeOp(serverip string) (func(), error) {
+	semctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Get_Info_Int("locktime"))*time.Second)
+	defer cancel()
+	return opredis.AcquireOp(semctx, serverip)
+}
+
+// 获取要操作的redis节点地址和密码
+func NodeAddress(cliquery CliQuery) (string, string) {
+	switch cliquery.CacheType {
+	case "cluster":
+		return mysql.DB.GetClusterNodeMasterAddress(cliquery.NodeId), mysql.DB.GetClusterPassword(cliquery.ClusterId)
+	case "codis":
+		return codisapi.GetMaster(cliquery.CodisUrl, cliquery.ClusterName, cliquery.GroupName), ""
+	case "txredis":
+		pw, ip, port := mysql.DB.GetCloudAddress(cliquery.CacheType, cliquery.InstanceId)
+		return net.JoinHostPort(ip, strconv.Itoa(port)), pw
+	}
+	return "", ""
+}
+
+// 获取int类型的参数，没有或者格式不对返回默认值
+func ParamInt(cliquery CliQuery, name string, defaultvalue int) int {
+	val, err := strconv.Atoi(cliquery.Params[name])
+	if err != nil {
+		return defaultvalue
+	}
+	return val
+}
+
+// 逗号分隔的时长，例如 1m,1h,24h，为空时返回nil
+func ParamDurations(cliquery CliQuery, name string) ([]time.Duration, error) {
+	if cliquery.Params[name] == "" {
+		return nil, nil
+	}
+	var result []time.Duration
+	for _, v := range strings.Split(cliquery.Params[name], ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("params.%s 格式错误: %s", name, v)
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
 func DefaultOp(cliquery CliQuery) (interface{}, bool) {
 	switch cliquery.CacheOp {
 	case "query":
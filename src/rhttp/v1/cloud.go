@@ -3,6 +3,8 @@ package v1
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iguidao/redis-manager/src/hsc"
@@ -10,6 +12,7 @@ import (
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
 	"github.com/iguidao/redis-manager/src/middleware/txcloud"
 	"github.com/iguidao/redis-manager/src/middleware/util"
 )
@@ -37,7 +40,7 @@ func CloudDel(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(instanceid)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		if !mysql.DB.DelCloud(instanceid) {
 			result = false
 			code = hsc.SERVER_ERROR
@@ -69,7 +72,7 @@ func CloudList(c *gin.Context) {
 				if ok {
 					err := json.Unmarshal([]byte(list), &rlist)
 					if err == nil {
-						go util.TxWriteRedis(cloud, rlist)
+						tools.SafeGo("txwriteredis", func() { util.TxWriteRedis(cloud, rlist) })
 						code = hsc.WARN_BACKGROUND
 					} else {
 						logger.Error("json tx cloud result error: ", err)
@@ -88,7 +91,7 @@ func CloudList(c *gin.Context) {
 				if ok {
 					err := json.Unmarshal([]byte(list), &rlist)
 					if err == nil {
-						go util.AliWriteRedis(cloud, rlist)
+						tools.SafeGo("aliwriteredis", func() { util.AliWriteRedis(cloud, rlist) })
 						code = hsc.WARN_BACKGROUND
 					} else {
 						logger.Error("json tx cloud result error: ", err)
@@ -175,7 +178,7 @@ func ChangeCloudPassword(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(cp)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		ok := mysql.DB.UpdateCloudPassword(cp.Cloud, cp.Instanceid, cp.Password)
 		if ok {
 			code = hsc.SUCCESS
@@ -201,7 +204,7 @@ func ChangeSize(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(shardcfg)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		if shardcfg.Cloud == "txredis" {
 
 		}
@@ -216,3 +219,57 @@ func ChangeSize(c *gin.Context) {
 		"data":      result,
 	})
 }
+
+// 导出实例当前参数，download=true 时作为文件下载
+func CloudConfigExport(c *gin.Context) {
+	code := hsc.SUCCESS
+	var result interface{}
+	region := c.Query("region")
+	instanceid := c.Query("instanceid")
+	if region == "" || instanceid == "" {
+		code = hsc.INVALID_PARAMS
+	} else {
+		desired, ok := util.ExportDesiredConfig(region, instanceid)
+		if !ok {
+			code = hsc.ERROR_CLOUD_GET
+		} else if c.Query("download") == "true" {
+			c.Header("Content-Disposition", "attachment; filename="+instanceid+".json")
+			c.IndentedJSON(http.StatusOK, desired)
+			return
+		} else {
+			result = desired
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
+
+// 从云监控获取实例指标，period 为查询的秒数，默认1小时
+func CloudMetrics(c *gin.Context) {
+	code := hsc.SUCCESS
+	var result interface{}
+	region := c.Query("region")
+	instanceid := c.Query("instanceid")
+	metric := c.Query("metric")
+	period, err := strconv.Atoi(c.DefaultQuery("period", "3600"))
+	if region == "" || instanceid == "" || err != nil || period <= 0 {
+		code = hsc.INVALID_PARAMS
+	} else {
+		points, err := txcloud.TxCloudMetrics(region, instanceid, metric, time.Duration(period)*time.Second)
+		if err == txcloud.ErrUnknownMetric {
+			code = hsc.INVALID_PARAMS
+		} else if err != nil {
+			code = hsc.ERROR_CLOUD_GET
+		} else {
+			result = points
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
@@ -3,15 +3,18 @@ package v1
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iguidao/redis-manager/src/hsc"
 	"github.com/iguidao/redis-manager/src/middleware/cluster"
+	"github.com/iguidao/redis-manager/src/middleware/events"
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
 	"github.com/iguidao/redis-manager/src/middleware/opredis"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
 )
 
 func ClusterList(c *gin.Context) {
@@ -55,6 +58,42 @@ func MasterList(c *gin.Context) {
 		"data":      nodes,
 	})
 }
+func ClusterHealth(c *gin.Context) {
+	code := hsc.ERROR_NO_CONNEC
+	var result interface{}
+	clusterid := c.Query("cluster_id")
+	address, pw := mysql.DB.GetClusterAddress(clusterid)
+	if opredis.ConnectRedisCluster(strings.Split(address, ","), pw) {
+		clusterinfo, ok := opredis.CGetClusterInfo()
+		if ok {
+			result = cluster.CheckHealth(opredis.CGetClusterNode(), clusterinfo)
+			code = hsc.SUCCESS
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
+
+// 生成slot迁移计划，只返回计划不执行
+func ClusterRebalancePlan(c *gin.Context) {
+	code := hsc.ERROR_NO_CONNEC
+	var result interface{}
+	clusterid := c.Query("cluster_id")
+	address, pw := mysql.DB.GetClusterAddress(clusterid)
+	if opredis.ConnectRedisCluster(strings.Split(address, ","), pw) {
+		keycount, _ := opredis.CMasterKeyCount()
+		result = cluster.PlanRebalance(opredis.CGetClusterNode(), keycount)
+		code = hsc.SUCCESS
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
 func ClusterAdd(c *gin.Context) {
 	var clusterinfo AddCluster
 	result := make(map[string]interface{})
@@ -69,7 +108,7 @@ func ClusterAdd(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(clusterinfo)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 
 		address := strings.Split(clusterinfo.Nodes, ",")
 		connectok := false
@@ -85,6 +124,7 @@ func ClusterAdd(c *gin.Context) {
 						cluster.WriteCluster(id, v)
 					}
 				}
+				events.Publish(events.INSTANCEREGISTERED, strconv.Itoa(id), map[string]string{"type": "cluster", "name": clusterinfo.Name})
 				code = hsc.SUCCESS
 			} else {
 				logger.Error("添加集群到 cluster info 失败")
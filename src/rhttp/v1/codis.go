@@ -8,6 +8,7 @@ import (
 
 	"github.com/iguidao/redis-manager/src/hsc"
 	"github.com/iguidao/redis-manager/src/middleware/codisapi"
+	"github.com/iguidao/redis-manager/src/middleware/events"
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
@@ -31,10 +32,12 @@ func CodisAdd(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(codisinfo)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		result, ok = mysql.DB.AddCodis(codisinfo.Curl, codisinfo.Cname)
 		if !ok {
 			code = hsc.ERROR
+		} else {
+			events.Publish(events.INSTANCEREGISTERED, codisinfo.Curl, map[string]string{"type": "codis", "name": codisinfo.Cname})
 		}
 	}
 
@@ -106,7 +109,7 @@ func CodisOpNode(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(codisnode)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		log.Println(codisnode.Curl, codisnode.ClusterName)
 		topom, ok = codisapi.CodisTopom(codisnode.Curl, codisnode.ClusterName)
 		if !ok {
@@ -137,7 +140,7 @@ func CodisOpNode(c *gin.Context) {
 				}
 				if len(noconnect) == 0 {
 					// result = opredis.Cdilatation(codisnode, clusterauth, topom)
-					go opredis.Cdilatation(codisnode, clusterauth, topom)
+					tools.SafeGo("codisdilatation", func() { opredis.Cdilatation(codisnode, clusterauth, topom) })
 					result = "Codis 扩容在执行中，请关注codis平台界面情况."
 				} else {
 					var address string
@@ -160,7 +163,7 @@ func CodisOpNode(c *gin.Context) {
 					code = hsc.WARN_CODIS_GROUP_MIN_CAPACITY
 					result = "Codis 缩容后的 group 的容量不足，不能缩容!"
 				} else {
-					go opredis.Cshrinkage(codisnode, clusterauth, topom)
+					tools.SafeGo("codisshrinkage", func() { opredis.Cshrinkage(codisnode, clusterauth, topom) })
 					// result = opredis.Cshrinkage(codisnode, clusterauth, topom)
 					result = "Codis 缩容在执行中，请关注codis平台界面情况."
 				}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iguidao/redis-manager/src/hsc"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
+)
+
+// 一次最多返回的日志行数，避免整个文件读进内存
+const tailMaxLines = 5000
+
+// 查看 app 日志里不低于 level 的最后几行，follow=1 时持续输出新日志
+func TailLog(c *gin.Context) {
+	var result interface{}
+	code := hsc.SUCCESS
+	lines, err := strconv.Atoi(c.DefaultQuery("lines", "100"))
+	if err != nil || lines < 0 {
+		code = hsc.INVALID_PARAMS
+	} else if lines > tailMaxLines {
+		code = hsc.INVALID_PARAMS
+		result = "一次最多 " + strconv.Itoa(tailMaxLines) + " 行"
+	}
+	follow := c.Query("follow") == "1"
+	var tail <-chan string
+	if code == hsc.SUCCESS {
+		tail, err = logger.TailFile(c.Request.Context(), c.Param("level"), follow, lines)
+		if err != nil {
+			logger.Error("Tail log error: ", err)
+			code = hsc.INVALID_PARAMS
+		}
+	}
+	if code != hsc.SUCCESS {
+		c.JSON(http.StatusOK, gin.H{
+			"errorCode": code,
+			"msg":       hsc.GetMsg(code),
+			"data":      result,
+		})
+		return
+	}
+	if !follow {
+		var content []string
+		for line := range tail {
+			content = append(content, line)
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"errorCode": code,
+			"msg":       hsc.GetMsg(code),
+			"data":      content,
+		})
+		return
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Stream(func(w io.Writer) bool {
+		line, ok := <-tail
+		if !ok {
+			return false
+		}
+		_, err := io.WriteString(w, line+"\n")
+		return err == nil
+	})
+}
@@ -2,8 +2,12 @@ package v1
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/iguidao/redis-manager/src/hsc"
+	"github.com/iguidao/redis-manager/src/middleware/journal"
+	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
 
 	"github.com/gin-gonic/gin"
@@ -19,3 +23,30 @@ func OpHistory(c *gin.Context) {
 	})
 	// c.JSON(http.StatusOK, gin.H{"ok": true})
 }
+
+// 查询操作流水，since 为unix秒，默认最近24小时
+func OpJournal(c *gin.Context) {
+	code := hsc.SUCCESS
+	var result interface{}
+	since := time.Now().Add(-24 * time.Hour)
+	if v := c.Query("since"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			code = hsc.INVALID_PARAMS
+		}
+		since = time.Unix(sec, 0)
+	}
+	if code == hsc.SUCCESS {
+		entries, err := journal.Journal(since)
+		if err != nil {
+			logger.Error("Read journal error: ", err)
+			code = hsc.ERROR
+		}
+		result = entries
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"errorCode": code,
+		"msg":       hsc.GetMsg(code),
+		"data":      result,
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/iguidao/redis-manager/src/middleware/casbin"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
 	"github.com/iguidao/redis-manager/src/middleware/util"
 )
 
@@ -69,7 +70,7 @@ func AddRule(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(Policy)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		if !casbin.RuleAdd(Policy.Identity, Policy.Path, Policy.Method) {
 			code = hsc.ERROR
 		}
@@ -94,7 +95,7 @@ func DelRule(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal("Path:" + ppath + " method:" + pmethod + " identity:" + pidentity)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		if !casbin.RuleDel(pidentity, ppath, pmethod) {
 			code = hsc.ERROR
 		}
@@ -10,6 +10,7 @@ import (
 	"github.com/iguidao/redis-manager/src/middleware/logger"
 	"github.com/iguidao/redis-manager/src/middleware/model"
 	"github.com/iguidao/redis-manager/src/middleware/mysql"
+	"github.com/iguidao/redis-manager/src/middleware/tools"
 	"github.com/iguidao/redis-manager/src/middleware/useride"
 	"github.com/iguidao/redis-manager/src/middleware/util"
 
@@ -62,7 +63,7 @@ func AddUser(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(rduser)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		scrypt_password := useride.Get_scrypt(rduser.Password)
 		result := mysql.DB.CreatUser(rduser.UserName, rduser.Mail, scrypt_password)
 		if !result {
@@ -93,7 +94,7 @@ func DelUser(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(userid)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		result := mysql.DB.DelUser(id)
 		if !result {
 			code = hsc.ERROR
@@ -125,7 +126,7 @@ func ChangUserPassword(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(upasssword)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(userid.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(userid.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 
 		if useride.Gd_login(username.(string), upasssword.Old) {
 			scrypt_password := useride.Get_scrypt(upasssword.New)
@@ -162,7 +163,7 @@ func ChangUserType(c *gin.Context) {
 		urlinfo := c.Request.URL
 		jsonBody, _ := json.Marshal(rduser)
 		method := c.Request.Method
-		go mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody))
+		tools.SafeGo("history", func() { mysql.DB.AddHistory(username.(int), method+":"+urlinfo.Path, string(jsonBody)) })
 		result := false
 		if mysql.DB.ExistUserName(rduser.UserName) {
 			result = mysql.DB.UpdateUserType(rduser.UserName, rduser.UserType)
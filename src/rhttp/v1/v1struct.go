@@ -16,16 +16,21 @@ type ConfigInfo struct {
 
 // 操作缓存的指令
 type CliQuery struct {
-	CacheType   string `json:"cache_type"`
-	CacheOp     string `json:"cache_op"`
-	ClusterName string `json:"cluster_name"`
-	KeyName     string `json:"key_name"`
-	CodisUrl    string `json:"codis_url"`
-	GroupName   string `json:"group_name"`
-	Region      string `json:"region"`
-	InstanceId  string `json:"instance_id"`
-	ClusterId   string `json:"cluster_id"`
-	NodeId      string `json:"node_id"`
+	CacheType   string            `json:"cache_type"`
+	CacheOp     string            `json:"cache_op"`
+	ClusterName string            `json:"cluster_name"`
+	KeyName     string            `json:"key_name"`
+	CodisUrl    string            `json:"codis_url"`
+	GroupName   string            `json:"group_name"`
+	Region      string            `json:"region"`
+	InstanceId  string            `json:"instance_id"`
+	ClusterId   string            `json:"cluster_id"`
+	NodeId      string            `json:"node_id"`
+	Output      string            `json:"output"`  // 导出格式 csv/json，为空时返回原始结果
+	ReadOnly    bool              `json:"-"`       // 只读链接，访客身份时设置
+	Params      map[string]string `json:"params"`  // 操作的额外参数
+	DB          int               `json:"db"`      // 操作的逻辑库，cluster只支持0
+	Confirm     string            `json:"confirm"` // 危险操作的确认，需要跟目标ID一致
 }
 
 // 分析大key